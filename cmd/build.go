@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/static"
 
 	"github.com/spf13/cobra"
 )
@@ -11,7 +12,14 @@ var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build CSS and search index",
 	Long: `Execute the build process which compiles Tailwind CSS 
-and generates the search index with Pagefind.`,
+and generates the search index with Pagefind.
+
+With --static (or build.static in garp.toml) markdown pages are rendered
+through _template.html into plain HTML in the static directory (dist by
+default), so the site can be hosted on any static file host or CDN.
+Pages with "draft: true" or a future "date:" are left out of static
+builds, the search index and the sitemap unless --drafts or --future is
+given.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Log build start
 		internal.LogInfo("Starting build process",
@@ -23,6 +31,10 @@ and generates the search index with Pagefind.`,
 		options := internal.BuildOptions{
 			CSSOnly:    cssOnly,
 			SearchOnly: searchOnly,
+			SkipSearch: !projectConfig.Search.Enabled,
+			Drafts:     buildDrafts,
+			Future:     buildFuture,
+			BaseURL:    projectConfig.Site.BaseURL,
 			Watch:      watch,
 			Verbose:    verbose,
 		}
@@ -35,7 +47,11 @@ and generates the search index with Pagefind.`,
 		}
 
 		// Execute build
-		result, err := internal.BuildAll(options)
+		build := internal.BuildAll
+		if projectConfig.Build.Static {
+			build = static.Build
+		}
+		result, err := build(options)
 		if err != nil {
 			internal.LogErrorWithError("Build failed", err,
 				"css_built", fmt.Sprintf("%t", result != nil && result.CSSBuilt),
@@ -61,6 +77,12 @@ and generates the search index with Pagefind.`,
 			if result.CSSBuilt {
 				fmt.Println("  📄 CSS compiled")
 			}
+			if result.PagesRendered > 0 {
+				fmt.Printf("  📝 %d pages rendered to %s\n", result.PagesRendered, internal.GetProjectLayout().OutputDir)
+			}
+			if result.PagesUnpublished > 0 {
+				fmt.Printf("  🚧 %d drafts and scheduled pages skipped\n", result.PagesUnpublished)
+			}
 			if result.SearchBuilt {
 				fmt.Println("  🔍 Search index generated")
 			}
@@ -71,14 +93,20 @@ and generates the search index with Pagefind.`,
 }
 
 var (
-	cssOnly    bool
-	searchOnly bool
-	watch      bool
+	cssOnly     bool
+	searchOnly  bool
+	watch       bool
+	buildDrafts bool
+	buildFuture bool
 )
 
 func init() {
 	buildCmd.Flags().BoolVar(&cssOnly, "css-only", false, "Build only CSS files")
 	buildCmd.Flags().BoolVar(&searchOnly, "search-only", false, "Build only search index")
 	buildCmd.Flags().BoolVar(&watch, "watch", false, "Watch for changes and rebuild automatically")
+	buildCmd.Flags().Bool("static", false, "Render markdown to plain HTML in the static directory")
+	buildCmd.Flags().BoolVar(&buildDrafts, "drafts", false, "Include draft pages in a static build")
+	buildCmd.Flags().BoolVar(&buildFuture, "future", false, "Include pages dated in the future in a static build")
+	bindConfigFlag(buildCmd, "static", "build.static")
 	rootCmd.AddCommand(buildCmd)
 }
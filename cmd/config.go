@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mattsafaii/garp/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var projectConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect project configuration",
+	Long: `Inspect the project configuration in garp.toml.
+
+Values are resolved with the precedence flags > environment > garp.toml >
+defaults. Environment variables are read from the process environment and
+the project's .env file.`,
+}
+
+var projectConfigShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration",
+	Long:  `Print every configuration value after merging defaults, garp.toml and the environment, along with where each value came from.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showProjectConfig(projectConfig)
+	},
+}
+
+// showProjectConfig prints the merged configuration with value sources
+func showProjectConfig(cfg *config.Config) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tENV")
+
+	for _, field := range cfg.Fields() {
+		value := field.Value
+		if field.Secret && value != "" {
+			value = maskSecret(value)
+		}
+		if value == "" {
+			value = "-"
+		}
+		env := field.Env
+		if env == "" {
+			env = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", field.Key, value, field.Source, env)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if names := cfg.EnvironmentNames(); len(names) > 0 {
+		fmt.Printf("\nDeploy environments (%s):\n", config.FileName)
+		for _, name := range names {
+			env := cfg.Deploy.Environments[name]
+			fmt.Printf("  %s: %s\n", name, describeEnvironment(env))
+		}
+	}
+
+	if names := cfg.FormNames(); len(names) > 0 {
+		fmt.Printf("\nForms (%s):\n", config.FileName)
+		for _, name := range names {
+			fmt.Printf("  %s: %s\n", name, describeForm(cfg.Forms.Schemas[name]))
+		}
+	}
+
+	return nil
+}
+
+// describeForm summarises a garp.toml form on one line
+func describeForm(form config.FormSchema) string {
+	fields := make([]string, 0, len(form.Fields))
+	for _, field := range form.Fields {
+		name := field.Name
+		if field.Required {
+			name += "*"
+		}
+		fields = append(fields, name)
+	}
+
+	parts := []string{"fields=" + strings.Join(fields, ",")}
+	if form.Recipient != "" {
+		parts = append(parts, "recipient="+form.Recipient)
+	}
+	if form.Redirect != "" {
+		parts = append(parts, "redirect="+form.Redirect)
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeEnvironment summarises a garp.toml deploy environment on one line
+func describeEnvironment(env config.DeployEnvironment) string {
+	var parts []string
+	if env.Strategy != "" {
+		parts = append(parts, "strategy="+env.Strategy)
+	}
+	if env.Remote != "" {
+		parts = append(parts, "remote="+env.Remote)
+	}
+	if env.Branch != "" {
+		parts = append(parts, "branch="+env.Branch)
+	}
+	if env.Host != "" {
+		target := env.Host
+		if env.User != "" {
+			target = env.User + "@" + target
+		}
+		if env.Path != "" {
+			target += ":" + env.Path
+		}
+		parts = append(parts, "target="+target)
+	} else if env.Path != "" {
+		parts = append(parts, "path="+env.Path)
+	}
+	if len(env.Excludes) > 0 {
+		parts = append(parts, "excludes="+strings.Join(env.Excludes, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// maskSecret hides all but the last four characters of a secret
+func maskSecret(value string) string {
+	if len(value) <= 4 {
+		return "****"
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
+
+func init() {
+	projectConfigCmd.AddCommand(projectConfigShowCmd)
+	rootCmd.AddCommand(projectConfigCmd)
+}
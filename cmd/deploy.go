@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/mattsafaii/garp/internal"
 	"github.com/mattsafaii/garp/internal/deploy"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -11,37 +13,45 @@ var deployCmd = &cobra.Command{
 	Use:   "deploy",
 	Short: "Deploy the site",
 	Long: `Deploy the site using configured deployment strategy 
-(Git, rsync, or static hosting platform).`,
+(Git, rsync, a Netlify-compatible static hosting API, or an S3 bucket).
+
+The target and its settings come from the [deploy] section of garp.toml
+and can be overridden with DEPLOY_* environment variables or flags.
+
+With --env, the named environment is used instead. Environments are
+declared in garp.toml under [deploy.environments.<name>] or stored with
+'garp deploy-config set'; stored settings take precedence, and flags given
+on the command line override both.`,
+	Example: `  garp deploy
+  garp deploy --env production
+  garp deploy --env staging --git-branch hotfix`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runDeploy()
+		return runDeploy(cmd)
 	},
 }
 
 var (
-	deployTarget     string
 	dryRun           bool
 	buildFirst       bool
 	deployVerbose    bool
 	skipValidation   bool
 	skipContentCheck bool
-	gitRemote        string
-	gitBranch        string
-	rsyncHost        string
-	rsyncUser        string
-	rsyncPath        string
-	apiKey           string
+	fullSync         bool
+	forceUnlock      bool
+	allowUnpublished bool
 	projectID        string
-	siteID           string
+	deployEnv        string
 )
 
-func runDeploy() error {
+func runDeploy(cmd *cobra.Command) error {
 	manager := deploy.NewManager()
+	settings := projectConfig.Deploy
 
 	// Determine deployment strategy
 	strategy := deploy.GitStrategy // Default to git
-	if deployTarget != "" {
+	if settings.Target != "" {
 		var err error
-		strategy, err = deploy.ParseStrategy(deployTarget)
+		strategy, err = deploy.ParseStrategy(settings.Target)
 		if err != nil {
 			return fmt.Errorf("invalid deployment target: %v", err)
 		}
@@ -49,23 +59,66 @@ func runDeploy() error {
 
 	// Create deployment configuration
 	config := deploy.DeploymentConfig{
-		Strategy:         strategy,
-		Target:           deployTarget,
-		DryRun:           dryRun,
-		Verbose:          deployVerbose,
-		BuildFirst:       buildFirst,
-		SkipValidation:   skipValidation,
-		SkipContentCheck: skipContentCheck,
-		GitRemote:        gitRemote,
-		GitBranch:        gitBranch,
-		RsyncHost:        rsyncHost,
-		RsyncUser:        rsyncUser,
-		RsyncPath:        rsyncPath,
-		APIKey:           apiKey,
-		ProjectID:        projectID,
-		SiteID:           siteID,
+		Strategy:          strategy,
+		Target:            settings.Target,
+		DryRun:            dryRun,
+		Verbose:           deployVerbose,
+		BuildFirst:        buildFirst,
+		SkipSearch:        !projectConfig.Search.Enabled,
+		BaseURL:           projectConfig.Site.BaseURL,
+		SkipValidation:    skipValidation,
+		SkipContentCheck:  skipContentCheck,
+		AllowUnpublished:  allowUnpublished,
+		FullSync:          fullSync,
+		GarpVersion:       version,
+		GitRemote:         settings.Remote,
+		GitBranch:         settings.Branch,
+		RsyncHost:         settings.Host,
+		RsyncUser:         settings.User,
+		RsyncPath:         settings.Path,
+		Releases:          settings.Releases,
+		KeepReleases:      settings.KeepReleases,
+		HistoryLimit:      settings.HistoryLimit,
+		APIURL:            settings.APIURL,
+		APIKey:            settings.APIKey,
+		ProjectID:         projectID,
+		SiteID:            settings.SiteID,
+		S3Bucket:          settings.Bucket,
+		S3Prefix:          settings.Prefix,
+		S3Region:          settings.Region,
+		S3Endpoint:        settings.Endpoint,
+		S3AccessKeyID:     settings.AccessKeyID,
+		S3SecretAccessKey: settings.SecretKey,
+		S3SessionToken:    settings.SessionToken,
+		Hooks: deploy.Hooks{
+			deploy.PreBuildHook:   settings.Hooks.PreBuild,
+			deploy.PreDeployHook:  settings.Hooks.PreDeploy,
+			deploy.PostDeployHook: settings.Hooks.PostDeploy,
+			deploy.FailureHook:    settings.Hooks.OnFailure,
+		},
+	}
+
+	if deployEnv != "" {
+		env, err := deployEnvironment(deployEnv, strategy.String())
+		if err != nil {
+			return err
+		}
+		if err := env.Apply(&config); err != nil {
+			return err
+		}
+		if err := applyDeployFlags(cmd, &config); err != nil {
+			return err
+		}
+		fmt.Printf("🌍 Deploying to environment '%s' (%s)\n", deployEnv, config.Strategy.String())
 	}
 
+	// Keep concurrent deployments from overwriting each other and .garp
+	lock, err := deploy.AcquireLock("garp deploy", forceUnlock)
+	if err != nil {
+		return err
+	}
+	defer releaseLock(lock)
+
 	// Validate configuration
 	if err := manager.Validate(config); err != nil {
 		return fmt.Errorf("deployment validation failed: %v", err)
@@ -108,27 +161,164 @@ func runDeploy() error {
 	return nil
 }
 
+// deployEnvironment returns the named environment, combining its declaration
+// in garp.toml with the settings stored by 'garp deploy-config set'
+func deployEnvironment(name, defaultStrategy string) (deploy.EnvironmentConfig, error) {
+	env := deploy.EnvironmentConfig{Name: name, Config: make(map[string]string)}
+	found := false
+
+	if declared, ok := projectConfig.Deploy.Environments[name]; ok {
+		found = true
+		env.Strategy = declared.Strategy
+		for key, value := range declared.Settings() {
+			env.Config[key] = value
+		}
+	}
+
+	manager, err := deploy.NewConfigManager()
+	if err != nil {
+		return env, fmt.Errorf("failed to initialize config manager: %v", err)
+	}
+	if stored, err := manager.GetEnvironment(name); err == nil {
+		found = true
+		if stored.Strategy != "" {
+			env.Strategy = stored.Strategy
+		}
+		for key, value := range stored.Config {
+			env.Config[key] = value
+		}
+	}
+
+	if !found {
+		names := append(projectConfig.EnvironmentNames(), manager.ListEnvironments()...)
+		suggestions := []string{fmt.Sprintf("Create it with 'garp deploy-config set %s --strategy rsync --config path=...'", name)}
+		if len(names) > 0 {
+			suggestions = append([]string{fmt.Sprintf("Known environments: %s", strings.Join(names, ", "))}, suggestions...)
+		}
+		return env, internal.NewValidationErrorWithSuggestions(
+			fmt.Sprintf("unknown deployment environment: %s", name),
+			suggestions,
+		)
+	}
+
+	if env.Strategy == "" {
+		env.Strategy = defaultStrategy
+	}
+	return env, nil
+}
+
+// releaseLock gives up the deploy lock, warning when that fails
+func releaseLock(lock *deploy.DeployLock) {
+	if err := lock.Release(); err != nil {
+		fmt.Printf("Warning: Failed to release deploy lock: %v\n", err)
+	}
+}
+
+// applyDeployFlags re-applies deployment flags given on the command line so
+// they take precedence over the settings of an environment
+func applyDeployFlags(cmd *cobra.Command, config *deploy.DeploymentConfig) error {
+	settings := projectConfig.Deploy
+	flags := cmd.Flags()
+
+	if flags.Changed("target") {
+		strategy, err := deploy.ParseStrategy(settings.Target)
+		if err != nil {
+			return fmt.Errorf("invalid deployment target: %v", err)
+		}
+		config.Strategy = strategy
+	}
+	if flags.Changed("git-remote") {
+		config.GitRemote = settings.Remote
+	}
+	if flags.Changed("git-branch") {
+		config.GitBranch = settings.Branch
+	}
+	if flags.Changed("rsync-host") {
+		config.RsyncHost = settings.Host
+	}
+	if flags.Changed("rsync-user") {
+		config.RsyncUser = settings.User
+	}
+	if flags.Changed("rsync-path") {
+		config.RsyncPath = settings.Path
+	}
+	if flags.Changed("releases") {
+		config.Releases = settings.Releases
+	}
+	if flags.Changed("keep-releases") {
+		config.KeepReleases = settings.KeepReleases
+	}
+	if flags.Changed("site-id") {
+		config.SiteID = settings.SiteID
+	}
+	if flags.Changed("api-url") {
+		config.APIURL = settings.APIURL
+	}
+	if flags.Changed("bucket") {
+		config.S3Bucket = settings.Bucket
+	}
+	if flags.Changed("prefix") {
+		config.S3Prefix = settings.Prefix
+	}
+	if flags.Changed("region") {
+		config.S3Region = settings.Region
+	}
+	if flags.Changed("endpoint") {
+		config.S3Endpoint = settings.Endpoint
+	}
+	return nil
+}
+
 func init() {
-	deployCmd.Flags().StringVar(&deployTarget, "target", "", "Deployment target (git, rsync)")
+	deployCmd.Flags().String("target", "git", "Deployment target (git, rsync, netlify, s3)")
+	deployCmd.Flags().StringVar(&deployEnv, "env", "", "Deploy to a named environment from garp.toml or 'garp deploy-config'")
 	deployCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be deployed without actually deploying")
 	deployCmd.Flags().BoolVar(&buildFirst, "build", true, "Run build before deployment")
 	deployCmd.Flags().BoolVarP(&deployVerbose, "verbose", "v", false, "Show detailed deployment output")
 	deployCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip connection validation (for testing)")
 	deployCmd.Flags().BoolVar(&skipContentCheck, "skip-content-check", false, "Skip content validation")
+	deployCmd.Flags().BoolVar(&forceUnlock, "force-unlock", false, "Remove the deploy lock of another deployment before deploying")
+	deployCmd.Flags().BoolVar(&allowUnpublished, "allow-unpublished", false, "Let git deployments push committed drafts and scheduled pages")
+	deployCmd.Flags().BoolVar(&fullSync, "full", false, "Transfer every file instead of only the files changed since the last deployment")
 
 	// Git-specific flags
-	deployCmd.Flags().StringVar(&gitRemote, "git-remote", "origin", "Git remote for deployment")
-	deployCmd.Flags().StringVar(&gitBranch, "git-branch", "", "Git branch for deployment (defaults to current branch)")
+	deployCmd.Flags().String("git-remote", "origin", "Git remote for deployment")
+	deployCmd.Flags().String("git-branch", "", "Git branch for deployment (defaults to current branch)")
 
 	// Rsync-specific flags
-	deployCmd.Flags().StringVar(&rsyncHost, "rsync-host", "", "Rsync target host")
-	deployCmd.Flags().StringVar(&rsyncUser, "rsync-user", "", "Rsync user")
-	deployCmd.Flags().StringVar(&rsyncPath, "rsync-path", "", "Rsync target path")
+	deployCmd.Flags().String("rsync-host", "", "Rsync target host")
+	deployCmd.Flags().String("rsync-user", "", "Rsync user")
+	deployCmd.Flags().String("rsync-path", "", "Rsync target path")
+	deployCmd.Flags().Bool("releases", false, "Deploy into a new release directory and switch the current symlink")
+	deployCmd.Flags().Int("keep-releases", 5, "Number of releases to keep on the target")
 
 	// Static hosting flags
-	deployCmd.Flags().StringVar(&apiKey, "api-key", "", "API key for static hosting platform")
+	deployCmd.Flags().String("api-key", "", "API key for static hosting platform")
 	deployCmd.Flags().StringVar(&projectID, "project-id", "", "Project ID for static hosting platform")
-	deployCmd.Flags().StringVar(&siteID, "site-id", "", "Site ID for static hosting platform")
+	deployCmd.Flags().String("site-id", "", "Site ID for static hosting platform")
+	deployCmd.Flags().String("api-url", "", "API URL of a Netlify-compatible static host")
+
+	// S3-specific flags
+	deployCmd.Flags().String("bucket", "", "S3 bucket to deploy to")
+	deployCmd.Flags().String("prefix", "", "Key prefix inside the S3 bucket")
+	deployCmd.Flags().String("region", "", "S3 region (defaults to us-east-1)")
+	deployCmd.Flags().String("endpoint", "", "Endpoint of an S3-compatible store, e.g. http://localhost:9000")
+
+	bindConfigFlag(deployCmd, "target", "deploy.target")
+	bindConfigFlag(deployCmd, "git-remote", "deploy.remote")
+	bindConfigFlag(deployCmd, "git-branch", "deploy.branch")
+	bindConfigFlag(deployCmd, "rsync-host", "deploy.host")
+	bindConfigFlag(deployCmd, "rsync-user", "deploy.user")
+	bindConfigFlag(deployCmd, "rsync-path", "deploy.path")
+	bindConfigFlag(deployCmd, "releases", "deploy.releases")
+	bindConfigFlag(deployCmd, "keep-releases", "deploy.keep_releases")
+	bindConfigFlag(deployCmd, "api-key", "deploy.api_key")
+	bindConfigFlag(deployCmd, "site-id", "deploy.site_id")
+	bindConfigFlag(deployCmd, "api-url", "deploy.api_url")
+	bindConfigFlag(deployCmd, "bucket", "deploy.bucket")
+	bindConfigFlag(deployCmd, "prefix", "deploy.prefix")
+	bindConfigFlag(deployCmd, "region", "deploy.region")
+	bindConfigFlag(deployCmd, "endpoint", "deploy.endpoint")
 
 	rootCmd.AddCommand(deployCmd)
 }
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/mattsafaii/garp/internal/deploy"
 
 	"github.com/spf13/cobra"
@@ -16,9 +18,23 @@ var deployConfigCmd = &cobra.Command{
 var setConfigCmd = &cobra.Command{
 	Use:   "set [environment-name]",
 	Short: "Set deployment configuration for an environment",
-	Long:  `Set deployment configuration for a specific environment (e.g., staging, production).`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runSetConfig,
+	Long: `Set deployment configuration for a specific environment (e.g., staging, production).
+
+Settings are given as --config key=value and validated for the strategy:
+  git:     remote, branch
+  rsync:   host, user, path (required), excludes, releases, keep_releases
+  netlify: site_id (required), api_url, excludes
+  s3:      bucket (required), prefix, region, endpoint, excludes
+
+Credentials are not stored; set DEPLOY_API_KEY for netlify and
+AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY for s3.
+
+Repeat --config excludes=... or give a comma-separated list to exclude
+several patterns. Deploy to the environment with 'garp deploy --env <name>'.`,
+	Example: `  garp deploy-config set production --strategy rsync --config host=example.com --config user=deploy --config path=/var/www/site
+  garp deploy-config set staging --strategy git --config branch=staging`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSetConfig,
 }
 
 var getConfigCmd = &cobra.Command{
@@ -57,21 +73,11 @@ func runSetConfig(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize config manager: %v", err)
 	}
 
-	// Parse config values into map
-	configMap := make(map[string]string)
-	for _, val := range configValues {
-		// Parse key=value format
-		if len(val) > 0 {
-			// Simple parsing - in production you might want more robust parsing
-			fmt.Printf("Adding config: %s\n", val)
-			configMap["raw"] = val // Simplified for demo
-		}
-	}
-
-	config := deploy.EnvironmentConfig{
-		Strategy: configStrategy,
-		Config:   configMap,
+	config, err := deploy.ParseEnvironmentConfig(configStrategy, configValues)
+	if err != nil {
+		return err
 	}
+	config.Name = envName
 
 	err = configManager.SetEnvironment(envName, config)
 	if err != nil {
@@ -98,8 +104,13 @@ func runGetConfig(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Environment: %s\n", config.Name)
 	fmt.Printf("Strategy: %s\n", config.Strategy)
 	fmt.Println("Configuration:")
-	for key, value := range config.Config {
-		fmt.Printf("  %s: %s\n", key, value)
+	keys := make([]string, 0, len(config.Config))
+	for key := range config.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %s: %s\n", key, config.Config[key])
 	}
 
 	return nil
@@ -150,8 +161,8 @@ func runRemoveConfig(cmd *cobra.Command, args []string) error {
 
 func init() {
 	// Add flags for set command
-	setConfigCmd.Flags().StringVar(&configStrategy, "strategy", "", "Deployment strategy (git, rsync)")
-	setConfigCmd.Flags().StringArrayVar(&configValues, "config", []string{}, "Configuration values (can be specified multiple times)")
+	setConfigCmd.Flags().StringVar(&configStrategy, "strategy", "", "Deployment strategy (git, rsync, netlify, s3)")
+	setConfigCmd.Flags().StringArrayVar(&configValues, "config", []string{}, "Configuration value as key=value (can be specified multiple times)")
 	setConfigCmd.MarkFlagRequired("strategy")
 
 	// Add subcommands
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
 	"github.com/mattsafaii/garp/internal/deploy"
 
 	"github.com/spf13/cobra"
@@ -10,23 +15,63 @@ import (
 var deployHistoryCmd = &cobra.Command{
 	Use:   "deploy-history",
 	Short: "Show deployment history",
-	Long:  `Display recent deployment history with details about each deployment.`,
+	Long: `Display recent deployment history with details about each deployment.
+
+Deployments can be filtered by environment, strategy, outcome and time.
+--since takes a duration such as 24h, 7d or 2w, a date (2006-01-02) or an
+RFC 3339 timestamp. With --json the matching records are printed as a JSON
+array, newest first; --limit 0 shows every recorded deployment.`,
+	Example: `  garp deploy-history
+  garp deploy-history --env production --since 7d
+  garp deploy-history --failed --strategy rsync
+  garp deploy-history --limit 0 --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runDeployHistory()
 	},
 }
 
 var (
-	historyLimit int
+	historyLimit    int
+	historyEnv      string
+	historyStrategy string
+	historyFailed   bool
+	historySince    string
+	historyJSON     bool
 )
 
 func runDeployHistory() error {
+	filter := deploy.HistoryFilter{
+		Environment: historyEnv,
+		FailedOnly:  historyFailed,
+		Limit:       historyLimit,
+	}
+	if historyStrategy != "" {
+		strategy, err := deploy.ParseStrategy(historyStrategy)
+		if err != nil {
+			return internal.NewValidationError(err.Error())
+		}
+		filter.Strategy = strategy.String()
+	}
+	if historySince != "" {
+		since, err := deploy.ParseSince(historySince, time.Now())
+		if err != nil {
+			return internal.NewValidationError(err.Error())
+		}
+		filter.Since = since
+	}
+
 	history, err := deploy.NewDeploymentHistory()
 	if err != nil {
 		return fmt.Errorf("failed to load deployment history: %v", err)
 	}
 
-	recent := history.GetRecentDeployments(historyLimit)
+	recent := history.Query(filter)
+
+	if historyJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(recent)
+	}
 
 	if len(recent) == 0 {
 		fmt.Println("No deployments found.")
@@ -44,8 +89,11 @@ func runDeployHistory() error {
 		fmt.Printf("ID: %s\n", record.ID)
 		fmt.Printf("Time: %s\n", record.Timestamp.Format("2006-01-02 15:04:05"))
 		fmt.Printf("Strategy: %s\n", record.Strategy)
-		if record.Target != "" {
-			fmt.Printf("Target: %s\n", record.Target)
+		if env := record.EnvironmentName(); env != "" {
+			fmt.Printf("Environment: %s\n", env)
+		}
+		if record.RollbackOf != "" {
+			fmt.Printf("Rollback of: %s\n", record.RollbackOf)
 		}
 		fmt.Printf("Status: %s\n", status)
 		fmt.Printf("Duration: %v\n", record.Duration)
@@ -54,6 +102,10 @@ func runDeployHistory() error {
 			fmt.Printf("URL: %s\n", record.URL)
 		}
 
+		if record.GitRemote != "" {
+			fmt.Printf("Git Remote: %s\n", record.GitRemote)
+		}
+
 		if record.GitBranch != "" {
 			fmt.Printf("Git Branch: %s\n", record.GitBranch)
 		}
@@ -62,6 +114,31 @@ func runDeployHistory() error {
 			fmt.Printf("Git Commit: %s\n", record.GitCommit)
 		}
 
+		if info := record.BuildInfo; info != nil && info.FileCount > 0 {
+			fmt.Printf("Build: %d files, %s", info.FileCount, formatSize(info.TotalSize))
+			if info.GarpVersion != "" {
+				fmt.Printf(", garp %s", info.GarpVersion)
+			}
+			fmt.Println()
+			if info.TailwindVersion != "" {
+				fmt.Printf("Tailwind: %s\n", info.TailwindVersion)
+			}
+			if info.PagefindVersion != "" {
+				fmt.Printf("Pagefind: %s\n", info.PagefindVersion)
+			}
+		}
+
+		if len(record.Hooks) > 0 {
+			fmt.Println("Hooks:")
+			for _, hook := range record.Hooks {
+				mark := "✅"
+				if !hook.Success() {
+					mark = "❌"
+				}
+				fmt.Printf("  %s %s: %s (exit %d, %v)\n", mark, hook.Stage, hook.Command, hook.ExitCode, hook.Duration.Round(time.Millisecond))
+			}
+		}
+
 		if len(record.Messages) > 0 {
 			fmt.Println("Messages:")
 			for _, msg := range record.Messages {
@@ -83,6 +160,25 @@ func runDeployHistory() error {
 }
 
 func init() {
-	deployHistoryCmd.Flags().IntVar(&historyLimit, "limit", 10, "Number of recent deployments to show")
+	deployHistoryCmd.Flags().IntVar(&historyLimit, "limit", 10, "Number of recent deployments to show (0 for all)")
+	deployHistoryCmd.Flags().StringVar(&historyEnv, "env", "", "Only show deployments to this environment")
+	deployHistoryCmd.Flags().StringVar(&historyStrategy, "strategy", "", "Only show deployments using this strategy (git, rsync, netlify, s3)")
+	deployHistoryCmd.Flags().BoolVar(&historyFailed, "failed", false, "Only show failed deployments")
+	deployHistoryCmd.Flags().StringVar(&historySince, "since", "", "Only show deployments since a duration ago (24h, 7d) or a date")
+	deployHistoryCmd.Flags().BoolVar(&historyJSON, "json", false, "Print the deployments as JSON")
 	rootCmd.AddCommand(deployHistoryCmd)
 }
+
+// formatSize formats a byte count for display, e.g. 1.5 MB
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -62,10 +62,11 @@ This command checks:
 		fmt.Println("🔧 Checking project health:")
 
 		// Check writable directories
-		if err := internal.ValidateWritableDirectory("public"); err != nil {
-			fmt.Printf("  ❌ public/ directory: %s\n", err.Error())
+		sourceDir := internal.GetProjectLayout().SourceDir
+		if err := internal.ValidateWritableDirectory(sourceDir); err != nil {
+			fmt.Printf("  ❌ %s/ directory: %s\n", sourceDir, err.Error())
 		} else {
-			fmt.Println("  ✅ public/ directory: Writable")
+			fmt.Printf("  ✅ %s/ directory: Writable\n", sourceDir)
 		}
 
 		if err := internal.ValidateWritableDirectory("bin"); err != nil {
@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/config"
+	"github.com/mattsafaii/garp/internal/forms"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -15,43 +22,208 @@ import (
 var formServerCmd = &cobra.Command{
 	Use:   "form-server",
 	Short: "Start the contact form server",
-	Long: `Start the Sinatra server for handling contact form submissions with Resend email integration.
+	Long: `Start the server for handling contact form submissions with email notifications.
 
-The form server provides:
+The form server is built into garp and provides:
   • Contact form submission handling
-  • Email delivery via Resend API  
+  • Email delivery via Resend API, SMTP or a local mail directory
   • Input validation and spam protection
   • Rate limiting and security measures
   • Structured JSON logging
   • CORS support for cross-origin requests
 
-Environment variables:
-  RESEND_API_KEY       - Required: Resend API key for email delivery
-  RESEND_FROM_EMAIL    - Required: From email address (must be verified)
-  RESEND_TO_EMAIL      - Required: Recipient email address
-  GARP_FORM_HOST       - Optional: Host binding (default: 0.0.0.0)
+Settings are read from the [forms] section of garp.toml and can be
+overridden by environment variables (or .env) and flags:
+  FORM_EMAIL_BACKEND   - Optional: resend (default), smtp or file
+  RESEND_API_KEY       - Required for resend: Resend API key
+  SMTP_HOST            - Required for smtp: SMTP server
+  SMTP_PORT            - Optional: SMTP port (default: 587, 465 for tls)
+  SMTP_USERNAME        - Optional: SMTP user, authenticates when set
+  SMTP_PASSWORD        - Optional: SMTP password
+  SMTP_SECURITY        - Optional: starttls (default), tls or none
+  FORM_MAIL_DIR        - Optional: Maildir of the file backend (default: .garp/mail)
+  FORM_STORE_DIR       - Optional: Where submissions are saved (default: .garp/submissions)
+  FORM_WEBHOOK_URLS    - Optional: Comma-separated URLs that receive every submission
+  FORM_WEBHOOK_SECRET  - Required for webhooks: Secret signing the webhook requests
+  FORM_FROM_EMAIL      - Required: From email address (must be verified)
+  FORM_TO_EMAIL        - Required: Recipient email address
+  FORM_SERVER_HOST     - Optional: Host binding (default: 0.0.0.0)
+  FORM_SERVER_PORT     - Optional: Port (default: 4567)
+  EMAIL_SUBJECT_PREFIX - Optional: Subject prefix of notification emails
+  EMAIL_REPLY_TO       - Optional: Reply-To address of notification emails
   GARP_ENV             - Optional: Environment (development/production)
 
+Submissions are accepted as JSON or as regular HTML form posts, logged to
+form-submissions.log and saved to the submission store before they are
+emailed, so none are lost when email delivery fails. Use 'garp forms list'
+to read them.
+
+Submissions are also posted as JSON to every webhook URL, signed with an
+HMAC-SHA256 X-Garp-Signature header. Failed webhooks are retried with
+exponential backoff from an on-disk queue; see 'garp forms webhooks status'.
+
+The contact form is served at /submit. Forms declared in garp.toml as
+[forms.schemas.<name>] (see 'garp new form') are served at /forms/<name>
+and validated against their declared fields.
+
+With --ruby, the project's form-server.rb (Ruby + Sinatra) is run instead.
+
 Examples:
   garp form-server                    Start server on default port 4567
   garp form-server --port 8080       Start server on custom port
-  garp form-server --host localhost  Bind to localhost only`,
+  garp form-server --host localhost  Bind to localhost only
+  garp form-server --email file      Write emails to .garp/mail instead of sending them
+  garp form-server --ruby            Run form-server.rb with Ruby`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if formServerRuby {
+			return startRubyFormServer()
+		}
 		return startFormServer()
 	},
 }
 
-var (
-	formPort int
-	formHost string
-)
+var formServerRuby bool
 
 func startFormServer() error {
-	// Set environment variables for the Ruby server
-	os.Setenv("GARP_FORM_PORT", strconv.Itoa(formPort))
-	if formHost != "" {
-		os.Setenv("GARP_FORM_HOST", formHost)
+	settings := projectConfig.Forms
+
+	schemas, err := formSchemas(projectConfig)
+	if err != nil {
+		return err
+	}
+	webhooks, err := formWebhooks(projectConfig)
+	if err != nil {
+		return err
+	}
+
+	projectName := "Garp"
+	if dir, err := os.Getwd(); err == nil {
+		projectName = filepath.Base(dir)
+	}
+
+	server, err := forms.NewServer(forms.Config{
+		Host:          settings.Host,
+		Port:          settings.Port,
+		ProjectName:   projectName,
+		Environment:   os.Getenv("GARP_ENV"),
+		Version:       version,
+		ToEmail:       settings.ToEmail,
+		FromEmail:     settings.FromEmail,
+		ReplyTo:       settings.ReplyTo,
+		SubjectPrefix: settings.SubjectPrefix,
+		EmailBackend:  settings.EmailBackend,
+		ResendAPIKey:  settings.ResendAPIKey,
+		SMTP: forms.SMTPConfig{
+			Host:     settings.SMTPHost,
+			Port:     settings.SMTPPort,
+			Username: settings.SMTPUsername,
+			Password: settings.SMTPPassword,
+			Security: settings.SMTPSecurity,
+		},
+		MailDir:  settings.MailDir,
+		StoreDir: settings.StoreDir,
+		Webhooks: webhooks,
+		Forms:    schemas,
+	})
+	if err != nil {
+		return internal.NewFileSystemError("Failed to open the submission log or store", err)
+	}
+	defer server.Close()
+
+	fmt.Printf("🚀 Starting Garp Form Server...\n")
+	if server.EmailEnabled() {
+		fmt.Printf("📧 Email delivery enabled via %s\n", server.EmailBackend())
+		if settings.EmailBackend == forms.FileBackend {
+			fmt.Printf("📬 Emails are written to %s/new\n", settings.MailDir)
+		}
+	} else {
+		fmt.Printf("⚠️  Email delivery disabled: %s\n", server.EmailDisabledReason)
+		fmt.Printf("   Submissions are still saved, see 'garp forms list'\n")
+	}
+	for _, target := range webhooks.URLs {
+		fmt.Printf("🪝 Webhook: %s\n", target)
 	}
+	fmt.Printf("📧 Form endpoint: http://%s/submit\n", server.Addr())
+	for _, name := range server.FormNames() {
+		if name != forms.ContactFormName {
+			fmt.Printf("📋 Form %s: http://%s/forms/%s\n", name, server.Addr(), name)
+		}
+	}
+	fmt.Printf("📝 Logs: %s\n", forms.LogFile)
+	fmt.Printf("🗄️  Submissions: %s\n", server.Store().Path())
+	fmt.Printf("💡 Use Ctrl+C to stop the server\n\n")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := server.ListenAndServe(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return internal.NewExternalError("Form server encountered an error", err)
+	}
+
+	fmt.Printf("\n🛑 Form server stopped\n")
+	return nil
+}
+
+// formSchemas converts the forms declared in garp.toml, sorted by name
+func formSchemas(cfg *config.Config) ([]forms.Schema, error) {
+	var schemas []forms.Schema
+	for _, name := range cfg.FormNames() {
+		declared := cfg.Forms.Schemas[name]
+		schema := forms.Schema{
+			Name:      name,
+			Title:     declared.Title,
+			Recipient: declared.Recipient,
+			Subject:   declared.Subject,
+			Redirect:  declared.Redirect,
+		}
+		for _, field := range declared.Fields {
+			schema.Fields = append(schema.Fields, forms.Field{
+				Name:      field.Name,
+				Label:     field.Label,
+				Type:      field.Type,
+				Required:  field.Required,
+				MaxLength: field.MaxLength,
+				Options:   field.Options,
+			})
+		}
+
+		if err := schema.Validate(); err != nil {
+			return nil, internal.NewConfigurationErrorWithSuggestions(
+				fmt.Sprintf("invalid form in %s: %v", config.FileName, err),
+				[]string{
+					fmt.Sprintf("Check [forms.schemas.%s] in %s", name, config.FileName),
+					fmt.Sprintf("Field types: %s", strings.Join(forms.FieldTypes, ", ")),
+				},
+			)
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+// formWebhooks returns the webhook settings of the project
+func formWebhooks(cfg *config.Config) (forms.WebhookConfig, error) {
+	webhooks := forms.WebhookConfig{
+		URLs:        cfg.Forms.WebhookURLs,
+		Secret:      cfg.Forms.WebhookSecret,
+		Dir:         cfg.Forms.WebhookDir,
+		MaxAttempts: cfg.Forms.WebhookMaxAttempts,
+	}
+	if err := webhooks.Validate(); err != nil {
+		return webhooks, internal.NewConfigurationErrorWithSuggestions(
+			fmt.Sprintf("invalid webhook settings: %v", err),
+			[]string{
+				fmt.Sprintf("Check forms.webhook_urls in %s or FORM_WEBHOOK_URLS", config.FileName),
+				"Set FORM_WEBHOOK_SECRET in .env to sign the webhooks",
+			},
+		)
+	}
+	return webhooks, nil
+}
+
+// startRubyFormServer runs the project's form-server.rb with Ruby
+func startRubyFormServer() error {
+	forms := projectConfig.Forms
 
 	// Check if form-server.rb exists in current directory
 	if _, err := os.Stat("form-server.rb"); os.IsNotExist(err) {
@@ -77,10 +249,8 @@ func startFormServer() error {
 	}
 
 	fmt.Printf("🚀 Starting Garp Form Server...\n")
-	fmt.Printf("📧 Port: %d\n", formPort)
-	if formHost != "" {
-		fmt.Printf("🌐 Host: %s\n", formHost)
-	}
+	fmt.Printf("📧 Port: %d\n", forms.Port)
+	fmt.Printf("🌐 Host: %s\n", forms.Host)
 	fmt.Printf("📝 Logs: form-submissions.log\n")
 	fmt.Printf("💡 Use Ctrl+C to stop the server\n\n")
 
@@ -88,7 +258,7 @@ func startFormServer() error {
 	rubyCmd := exec.Command("ruby", "form-server.rb")
 	rubyCmd.Stdout = os.Stdout
 	rubyCmd.Stderr = os.Stderr
-	rubyCmd.Env = os.Environ()
+	rubyCmd.Env = append(os.Environ(), formServerEnv(forms)...)
 
 	// Start the server
 	if err := rubyCmd.Start(); err != nil {
@@ -123,8 +293,37 @@ func startFormServer() error {
 	return nil
 }
 
+// formServerEnv passes the resolved form settings to the Ruby server
+func formServerEnv(forms config.FormsConfig) []string {
+	env := []string{
+		"GARP_FORM_PORT=" + strconv.Itoa(forms.Port),
+		"GARP_FORM_HOST=" + forms.Host,
+	}
+	if forms.ResendAPIKey != "" {
+		env = append(env, "RESEND_API_KEY="+forms.ResendAPIKey)
+	}
+	if forms.FromEmail != "" {
+		env = append(env, "RESEND_FROM_EMAIL="+forms.FromEmail)
+	}
+	if forms.ToEmail != "" {
+		env = append(env, "RESEND_TO_EMAIL="+forms.ToEmail)
+	}
+	if forms.SubjectPrefix != "" {
+		env = append(env, "EMAIL_SUBJECT_PREFIX="+forms.SubjectPrefix)
+	}
+	if forms.ReplyTo != "" {
+		env = append(env, "EMAIL_REPLY_TO="+forms.ReplyTo)
+	}
+	return env
+}
+
 func init() {
-	formServerCmd.Flags().IntVarP(&formPort, "port", "p", 4567, "Port for form server")
-	formServerCmd.Flags().StringVarP(&formHost, "host", "H", "", "Host to bind to (default: 0.0.0.0)")
+	formServerCmd.Flags().IntP("port", "p", 4567, "Port for form server")
+	formServerCmd.Flags().StringP("host", "H", "0.0.0.0", "Host to bind to")
+	formServerCmd.Flags().String("email", "resend", "Email backend (resend, smtp, file)")
+	formServerCmd.Flags().BoolVar(&formServerRuby, "ruby", false, "Run the project's form-server.rb with Ruby instead of the built-in server")
+	bindConfigFlag(formServerCmd, "port", "forms.port")
+	bindConfigFlag(formServerCmd, "host", "forms.host")
+	bindConfigFlag(formServerCmd, "email", "forms.email_backend")
 	rootCmd.AddCommand(formServerCmd)
 }
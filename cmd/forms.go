@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/deploy"
+	"github.com/mattsafaii/garp/internal/forms"
+
+	"github.com/spf13/cobra"
+)
+
+var formsCmd = &cobra.Command{
+	Use:   "forms",
+	Short: "List, show and export form submissions and webhooks",
+	Long: `Read the submissions saved by 'garp form-server'.
+
+Every valid submission is saved to the submission store (forms.store_dir,
+default .garp/submissions) before it is emailed, along with whether the
+email was sent. Submissions are kept when email delivery fails or is
+disabled, so they can be answered or exported later.
+
+--since takes a duration such as 24h, 7d or 2w, a date (2006-01-02) or an
+RFC 3339 timestamp.
+
+'garp forms webhooks' inspects the deliveries to forms.webhook_urls.`,
+}
+
+var formsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent submissions",
+	Long: `List recent submissions, newest first. --status filters by email
+delivery: emailed, email_failed, not_emailed or pending (the server stopped
+before emailing it).`,
+	Example: `  garp forms list
+  garp forms list --form quote --since 7d
+  garp forms list --status email_failed --limit 0`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFormsList()
+	},
+}
+
+var formsShowCmd = &cobra.Command{
+	Use:     "show <id>",
+	Short:   "Show a submission",
+	Example: `  garp forms show sub_1722350000_9f86d081`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFormsShow(args[0])
+	},
+}
+
+var formsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export submissions as CSV or JSON",
+	Long: `Export submissions, newest first, to stdout or --output.
+
+CSV exports have the columns id, form, received_at, status, email_error and
+ip followed by a column for every submitted field. Values starting with =,
++, - or @ are prefixed with a quote so spreadsheets do not run them.`,
+	Example: `  garp forms export > submissions.csv
+  garp forms export --form quote --since 30d --output quotes.csv
+  garp forms export --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFormsExport()
+	},
+}
+
+var formsWebhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Inspect webhook deliveries",
+	Long: `Inspect the webhook queue of 'garp form-server'.
+
+Each submission is queued for every URL in forms.webhook_urls. Failed
+deliveries are retried with exponential backoff while the form server runs;
+after forms.webhook_max_attempts failures they become dead letters, which
+are kept until they are retried.`,
+}
+
+var formsWebhooksStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show queued deliveries and dead letters",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFormsWebhooksStatus()
+	},
+}
+
+var formsWebhooksRetryCmd = &cobra.Command{
+	Use:   "retry [id...]",
+	Short: "Queue dead letters again",
+	Long: `Move the given dead letters, or all of them, back into the webhook queue.
+They are delivered by the running form server, or the next time it starts.`,
+	Example: `  garp forms webhooks retry
+  garp forms webhooks retry whd_1722350000_9f86d081`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFormsWebhooksRetry(args)
+	},
+}
+
+var (
+	formsForm   string
+	formsStatus string
+	formsSince  string
+	formsLimit  int
+	formsJSON   bool
+	formsFormat string
+	formsOutput string
+)
+
+// submissionStatuses are the values accepted by --status
+var submissionStatuses = []string{forms.StatusEmailed, forms.StatusEmailFailed, forms.StatusNotEmailed, forms.StatusPending}
+
+// submissionFilter builds the store query from the command line flags
+func submissionFilter(limit int) (forms.SubmissionFilter, error) {
+	filter := forms.SubmissionFilter{Form: formsForm, Status: formsStatus, Limit: limit}
+	if formsStatus != "" && !slices.Contains(submissionStatuses, formsStatus) {
+		return filter, internal.NewValidationError(fmt.Sprintf("unknown status %q, use one of: %s", formsStatus, strings.Join(submissionStatuses, ", ")))
+	}
+	if formsSince != "" {
+		since, err := deploy.ParseSince(formsSince, time.Now())
+		if err != nil {
+			return filter, internal.NewValidationError(err.Error())
+		}
+		filter.Since = since
+	}
+	return filter, nil
+}
+
+// submissionStore returns the store of the current project
+func submissionStore() *forms.Store {
+	return forms.NewStore(projectConfig.Forms.StoreDir)
+}
+
+func runFormsList() error {
+	filter, err := submissionFilter(formsLimit)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := submissionStore().Query(filter)
+	if err != nil {
+		return internal.NewFileSystemError("Failed to read form submissions", err)
+	}
+
+	if formsJSON {
+		return forms.Export(os.Stdout, forms.JSONFormat, submissions)
+	}
+
+	if len(submissions) == 0 {
+		fmt.Println("No submissions found.")
+		return nil
+	}
+
+	fmt.Printf("Recent submissions (showing %d):\n\n", len(submissions))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tRECEIVED\tFORM\tSTATUS\tFROM")
+	for _, submission := range submissions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			submission.ID,
+			submission.ReceivedAt.Local().Format("2006-01-02 15:04"),
+			submission.Form,
+			submissionStatus(submission.Status),
+			submission.Summary())
+	}
+	return w.Flush()
+}
+
+func runFormsShow(id string) error {
+	submission, err := submissionStore().Get(id)
+	if err != nil {
+		return internal.NewValidationErrorWithSuggestions(err.Error(), []string{
+			"Run 'garp forms list' to see the IDs of recent submissions",
+		})
+	}
+
+	if formsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(submission)
+	}
+
+	fmt.Printf("ID: %s\n", submission.ID)
+	fmt.Printf("Form: %s\n", submission.Form)
+	fmt.Printf("Received: %s\n", submission.ReceivedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("Status: %s\n", submissionStatus(submission.Status))
+	if submission.EmailID != "" {
+		fmt.Printf("Email ID: %s\n", submission.EmailID)
+	}
+	if submission.EmailError != "" {
+		fmt.Printf("Email error: %s\n", submission.EmailError)
+	}
+	if submission.IP != "" {
+		fmt.Printf("IP: %s\n", submission.IP)
+	}
+	if submission.UserAgent != "" {
+		fmt.Printf("User agent: %s\n", submission.UserAgent)
+	}
+
+	names := make([]string, 0, len(submission.Fields))
+	for name := range submission.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Fields:")
+	for _, name := range names {
+		value := strings.ReplaceAll(submission.Fields[name], "\n", "\n    ")
+		fmt.Printf("  %s: %s\n", name, value)
+	}
+
+	if len(submission.Warnings) > 0 {
+		fmt.Println("Warnings:")
+		for _, warning := range submission.Warnings {
+			fmt.Printf("  ⚠️  %s\n", warning)
+		}
+	}
+	return nil
+}
+
+func runFormsExport() error {
+	if formsFormat != forms.CSVFormat && formsFormat != forms.JSONFormat {
+		return internal.NewValidationError(fmt.Sprintf("unknown format %q, use csv or json", formsFormat))
+	}
+
+	filter, err := submissionFilter(0)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := submissionStore().Query(filter)
+	if err != nil {
+		return internal.NewFileSystemError("Failed to read form submissions", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if formsOutput != "" {
+		// Exports hold personal data, so only the owner may read them
+		file, err := os.OpenFile(formsOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return internal.NewFileSystemError(fmt.Sprintf("failed to create file: %s", formsOutput), err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := forms.Export(out, formsFormat, submissions); err != nil {
+		return internal.NewFileSystemError("Failed to export form submissions", err)
+	}
+
+	if formsOutput != "" {
+		fmt.Printf("✅ Exported %d submissions to %s\n", len(submissions), formsOutput)
+	}
+	return nil
+}
+
+// webhookQueue returns the webhook queue of the current project
+func webhookQueue() *forms.WebhookQueue {
+	return forms.NewWebhookQueue(forms.WebhookConfig{Dir: projectConfig.Forms.WebhookDir})
+}
+
+func runFormsWebhooksStatus() error {
+	queue := webhookQueue()
+	pending, err := queue.Pending()
+	if err != nil {
+		return internal.NewFileSystemError("Failed to read the webhook queue", err)
+	}
+	dead, err := queue.Dead()
+	if err != nil {
+		return internal.NewFileSystemError("Failed to read the webhook queue", err)
+	}
+
+	if formsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"urls":    projectConfig.Forms.WebhookURLs,
+			"pending": nonNilDeliveries(pending),
+			"dead":    nonNilDeliveries(dead),
+		})
+	}
+
+	if urls := projectConfig.Forms.WebhookURLs; len(urls) > 0 {
+		fmt.Println("Webhooks:")
+		for _, target := range urls {
+			fmt.Printf("  • %s\n", target)
+		}
+	} else {
+		fmt.Println("No webhooks configured (forms.webhook_urls).")
+	}
+	fmt.Printf("Queue: %d pending, %d dead letters (%s)\n", len(pending), len(dead), queue.Dir())
+
+	if len(pending) > 0 {
+		fmt.Println("\nPending deliveries:")
+		printDeliveries(pending, "NEXT ATTEMPT", func(d forms.Delivery) time.Time { return d.NextAttempt })
+	}
+	if len(dead) > 0 {
+		fmt.Println("\nDead letters:")
+		printDeliveries(dead, "LAST ATTEMPT", func(d forms.Delivery) time.Time { return d.LastAttempt })
+		fmt.Println("\n💡 Run 'garp forms webhooks retry' to queue the dead letters again")
+	}
+	return nil
+}
+
+func runFormsWebhooksRetry(ids []string) error {
+	retried, err := webhookQueue().Retry(ids...)
+	if err != nil {
+		return internal.NewValidationErrorWithSuggestions(err.Error(), []string{
+			"Run 'garp forms webhooks status' to see the dead letters",
+		})
+	}
+
+	if retried == 0 {
+		fmt.Println("No dead letters to retry.")
+		return nil
+	}
+	fmt.Printf("✅ Queued %d dead letters again\n", retried)
+	if len(projectConfig.Forms.WebhookURLs) == 0 {
+		fmt.Println("⚠️  No webhooks are configured, so 'garp form-server' will not deliver them")
+	}
+	return nil
+}
+
+// printDeliveries prints a table of webhook deliveries
+func printDeliveries(deliveries []forms.Delivery, timeColumn string, at func(forms.Delivery) time.Time) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\tSUBMISSION\tURL\tATTEMPTS\t%s\tLAST ERROR\n", timeColumn)
+	for _, delivery := range deliveries {
+		when := "-"
+		if t := at(delivery); !t.IsZero() {
+			when = t.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			delivery.ID, delivery.SubmissionID, delivery.URL, delivery.Attempts, when, delivery.LastError)
+	}
+	w.Flush()
+}
+
+// nonNilDeliveries keeps empty lists as [] in JSON output
+func nonNilDeliveries(deliveries []forms.Delivery) []forms.Delivery {
+	if deliveries == nil {
+		return []forms.Delivery{}
+	}
+	return deliveries
+}
+
+// submissionStatus describes the email delivery status of a submission
+func submissionStatus(status string) string {
+	switch status {
+	case forms.StatusEmailed:
+		return "✅ emailed"
+	case forms.StatusEmailFailed:
+		return "❌ email failed"
+	case forms.StatusNotEmailed:
+		return "📭 not emailed"
+	case forms.StatusPending:
+		return "⏳ pending"
+	}
+	return status
+}
+
+func init() {
+	formsListCmd.Flags().IntVar(&formsLimit, "limit", 20, "Number of recent submissions to show (0 for all)")
+	formsListCmd.Flags().BoolVar(&formsJSON, "json", false, "Print the submissions as JSON")
+	formsShowCmd.Flags().BoolVar(&formsJSON, "json", false, "Print the submission as JSON")
+	formsWebhooksStatusCmd.Flags().BoolVar(&formsJSON, "json", false, "Print the queue as JSON")
+	formsExportCmd.Flags().StringVar(&formsFormat, "format", forms.CSVFormat, "Export format (csv, json)")
+	formsExportCmd.Flags().StringVarP(&formsOutput, "output", "o", "", "Write the export to a file instead of stdout")
+
+	for _, cmd := range []*cobra.Command{formsListCmd, formsExportCmd} {
+		cmd.Flags().StringVar(&formsForm, "form", "", "Only include submissions of this form")
+		cmd.Flags().StringVar(&formsStatus, "status", "", "Only include submissions with this status (emailed, email_failed, not_emailed, pending)")
+		cmd.Flags().StringVar(&formsSince, "since", "", "Only include submissions since a duration ago (24h, 7d) or a date")
+	}
+
+	formsCmd.AddCommand(formsListCmd)
+	formsCmd.AddCommand(formsShowCmd)
+	formsCmd.AddCommand(formsExportCmd)
+	formsWebhooksCmd.AddCommand(formsWebhooksStatusCmd)
+	formsWebhooksCmd.AddCommand(formsWebhooksRetryCmd)
+	formsCmd.AddCommand(formsWebhooksCmd)
+	rootCmd.AddCommand(formsCmd)
+}
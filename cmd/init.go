@@ -2,7 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/config"
 	"github.com/mattsafaii/garp/internal/scaffold"
 
 	"github.com/spf13/cobra"
@@ -12,6 +17,10 @@ var (
 	enableForms  bool
 	enableSearch bool
 	disableSearch bool
+	initTemplate string
+	initAuthor   string
+	initFrom     string
+	initVars     []string
 )
 
 var initCmd = &cobra.Command{
@@ -26,14 +35,32 @@ This command generates:
 - Tailwind CSS v4 configuration and input.css
 - Build scripts for CSS and search indexing
 - Example content and starter template
+- archetypes/ used by 'garp new' for pages, posts and sections
+
+Starter templates (--template):
+- default    A single welcome page that explains how Garp works
+- blog       A blog with a post listing, a first post and an about page
+- docs       Documentation with a sidebar
+- portfolio  A personal portfolio with project pages
+- business   A business site with services, about and contact pages
+
+Custom starters (--from):
+A directory, .tar.gz archive or archive URL is copied into the project.
+Files ending in .tmpl are rendered with Go templates (project data plus
+{{.Vars.<name>}}), and an optional garp-starter.toml declares variables,
+feature defaults and feature-specific files. Files the starter does not
+provide are generated as usual, and the result is validated.
 
 Optional features:
-- Form server (Ruby + Sinatra) for contact forms with --forms
+- Contact form server with Resend email delivery with --forms
 - Search functionality (Pagefind) enabled by default`,
-	Example: `  garp init my-blog
+	Example: `  garp init my-blog --template blog --author "Jane Doe"
+  garp init handbook --template docs
   garp init business-site --forms
   garp init portfolio --no-search
-  garp init landing-page --forms --no-search`,
+  garp init landing-page --forms --no-search
+  garp init my-site --from ./house-starter --var company="Acme Inc"
+  garp init my-site --from https://example.com/starter.tar.gz`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		projectName := "my-site"
@@ -46,9 +73,19 @@ Optional features:
 			return err
 		}
 
+		if initFrom != "" {
+			return initFromStarter(cmd, projectName)
+		}
+
+		starter, err := scaffold.FindStarter(initTemplate)
+		if err != nil {
+			return err
+		}
+
 		fmt.Printf("Initializing new Garp project: %s\n", projectName)
+		fmt.Printf("✓ Starter: %s (%s)\n", starter.Name, starter.Description)
 		if enableForms {
-			fmt.Printf("✓ Forms enabled (Ruby form server)\n")
+			fmt.Printf("✓ Forms enabled (garp form-server)\n")
 		}
 		if enableSearch {
 			fmt.Printf("✓ Search enabled (Pagefind)\n")
@@ -58,6 +95,8 @@ Optional features:
 		ps := scaffold.NewProjectStructure(projectName)
 		ps.EnableForms = enableForms
 		ps.EnableSearch = enableSearch
+		ps.Starter = starter.Name
+		ps.Author = initAuthor
 
 		// Validate project path
 		if err := ps.ValidateProjectPath(); err != nil {
@@ -79,6 +118,11 @@ Optional features:
 			return err
 		}
 
+		// Create archetypes for 'garp new'
+		if err := ps.CreateArchetypeFiles(); err != nil {
+			return err
+		}
+
 		// Create form server files if enabled
 		if err := ps.CreateFormServerFiles(); err != nil {
 			return err
@@ -99,7 +143,6 @@ Optional features:
 		
 		if enableForms {
 			fmt.Printf("  # For forms: Set RESEND_API_KEY and email settings in .env\n")
-			fmt.Printf("  bundle install        # Install Ruby dependencies\n")
 		}
 		
 		fmt.Printf("  garp serve            # Start development server\n")
@@ -113,9 +156,14 @@ Optional features:
 }
 
 func init() {
-	initCmd.Flags().BoolVar(&enableForms, "forms", false, "Enable form server (Ruby + Sinatra)")
+	initCmd.Flags().BoolVar(&enableForms, "forms", false, "Enable contact form server")
 	initCmd.Flags().BoolVar(&enableSearch, "search", true, "Enable search functionality (Pagefind)")
 	initCmd.Flags().BoolVar(&disableSearch, "no-search", false, "Disable search functionality")
+	initCmd.Flags().StringVarP(&initTemplate, "template", "t", scaffold.DefaultStarter, "Starter template ("+strings.Join(scaffold.StarterNames(), ", ")+")")
+	initCmd.Flags().StringVar(&initAuthor, "author", "", "Site author, used in the footer and for new content")
+	initCmd.Flags().StringVar(&initFrom, "from", "", "Create the project from a starter directory, .tar.gz archive or archive URL")
+	initCmd.Flags().StringArrayVar(&initVars, "var", nil, "Set a starter variable (name=value, repeatable)")
+	initCmd.MarkFlagsMutuallyExclusive("template", "from")
 	
 	// Handle the --no-search flag properly
 	initCmd.PreRun = func(cmd *cobra.Command, args []string) {
@@ -126,3 +174,133 @@ func init() {
 	
 	rootCmd.AddCommand(initCmd)
 }
+
+// initFromStarter creates a project from an external starter
+func initFromStarter(cmd *cobra.Command, projectName string) error {
+	values, err := parseStarterVars(initVars)
+	if err != nil {
+		return err
+	}
+
+	source, err := scaffold.OpenStarter(initFrom)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	name := source.Manifest.Name
+	if name == "" {
+		name = initFrom
+	}
+	fmt.Printf("Initializing new Garp project: %s\n", projectName)
+	fmt.Printf("✓ Starter: %s\n", name)
+
+	// Flags win over the starter's feature defaults
+	forms := enableForms
+	if !cmd.Flags().Changed("forms") {
+		forms = source.FeatureDefault("forms", forms)
+	}
+	search := enableSearch
+	if !cmd.Flags().Changed("search") && !cmd.Flags().Changed("no-search") {
+		search = source.FeatureDefault("search", search)
+	}
+
+	// Ask for missing variables only when someone is at the terminal
+	var prompt io.Reader
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		prompt = os.Stdin
+	}
+	vars, err := source.ResolveVariables(values, prompt, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	ps := scaffold.NewProjectStructure(projectName)
+	ps.EnableForms = forms
+	ps.EnableSearch = search
+	ps.Author = initAuthor
+
+	if err := ps.ValidateProjectPath(); err != nil {
+		return err
+	}
+	if err := ps.CreateFromStarter(source, vars); err != nil {
+		return err
+	}
+
+	// Generate whatever the starter leaves out, following its garp.toml
+	cfg, err := config.LoadFile(projectName)
+	if err != nil {
+		return err
+	}
+	ps.Layout = cfg.Layout()
+	ps.KeepExisting = true
+	steps := []func() error{
+		ps.CreateConfigurationFiles,
+		ps.CreateStylesheet,
+		ps.CreateArchetypeFiles,
+		ps.CreateFormServerFiles,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	if err := validateNewProject(projectName, ps.Layout); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✓ Project created from %s!\n", name)
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  cd %s\n", projectName)
+	fmt.Printf("  garp serve            # Start development server\n")
+	return nil
+}
+
+// parseStarterVars parses repeated --var name=value flags
+func parseStarterVars(pairs []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, internal.NewValidationErrorWithSuggestions(
+				fmt.Sprintf("invalid --var %q", pair),
+				[]string{"Use --var name=value"},
+			)
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+	return values, nil
+}
+
+// validateNewProject runs the project configuration checks inside the new
+// project directory
+func validateNewProject(dir string, layout internal.ProjectLayout) error {
+	previousDir, err := os.Getwd()
+	if err != nil {
+		return internal.NewFileSystemError("cannot determine current directory", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("cannot enter project directory: %s", dir), err)
+	}
+	defer os.Chdir(previousDir)
+
+	previousLayout := internal.GetProjectLayout()
+	internal.SetProjectLayout(layout)
+	defer internal.SetProjectLayout(previousLayout)
+
+	problems := internal.ValidateProjectConfiguration()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	suggestions := make([]string, len(problems))
+	for i, problem := range problems {
+		suggestions[i] = problem.Error()
+	}
+	suggestions = append(suggestions, fmt.Sprintf("Fix the starter or the files in %s, then run 'garp doctor'", dir))
+	return internal.NewValidationErrorWithSuggestions(
+		fmt.Sprintf("project created from starter is not valid (%d problems)", len(problems)),
+		suggestions,
+	)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/config"
+	"github.com/mattsafaii/garp/internal/content"
+	"github.com/mattsafaii/garp/internal/scaffold"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	newDir    string
+	newAuthor string
+	newDraft  bool
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create pages, posts, sections and forms",
+	Long: `Create new markdown content with prefilled frontmatter.
+
+Each kind of content is generated from an archetype. Garp ships built-in
+archetypes for pages, posts and sections; add archetypes/page.md,
+archetypes/post.md or archetypes/section.md to your project to customize
+them. Archetypes are Go text/templates and can use {{.Title}}, {{.Slug}},
+{{.Date}}, {{.Author}}, {{.Draft}} and {{.Year}}; pipe values through
+quote, e.g. {{.Title | quote}}, to write them as quoted frontmatter strings.
+
+New content is created as a draft. Drafts are shown with a banner in
+'garp serve' and left out of builds until you set draft: false.
+
+'garp new form' declares a form for 'garp form-server' instead.`,
+}
+
+var newPageCmd = &cobra.Command{
+	Use:   "page <name>",
+	Short: "Create a page",
+	Long:  `Create a markdown page in the source directory, e.g. public/about.md.`,
+	Example: `  garp new page about
+  garp new page "Contact Us"
+  garp new page docs/installation`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNewContent,
+}
+
+var newPostCmd = &cobra.Command{
+	Use:   "post <title>",
+	Short: "Create a dated blog post",
+	Long:  `Create a dated markdown post in public/blog/ (change the directory with --dir).`,
+	Example: `  garp new post "My First Post"
+  garp new post "Release Notes" --dir news`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNewContent,
+}
+
+var newSectionCmd = &cobra.Command{
+	Use:   "section <name>",
+	Short: "Create a section with an index page",
+	Long:  `Create a directory with an index.md page, e.g. public/docs/index.md.`,
+	Example: `  garp new section docs
+  garp new section "Case Studies"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNewContent,
+}
+
+var newFormCmd = &cobra.Command{
+	Use:   "form <name>",
+	Short: "Declare a form and create its HTML snippet",
+	Long: `Declare a form served by 'garp form-server' at /forms/<name>.
+
+A starter declaration with name, email and message fields is appended to
+garp.toml as [forms.schemas.<name>]; edit its fields, recipient, subject
+and redirect there. A matching HTML form is written to
+public/_forms/<name>.html to paste into a page, or to include from a
+page served by 'garp serve' with [[include "/_forms/<name>.html"]].`,
+	Example: `  garp new form newsletter
+  garp new form "Quote Request"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNewForm,
+}
+
+// runNewContent creates content of the kind named by the subcommand
+func runNewContent(cmd *cobra.Command, args []string) error {
+	if err := internal.ValidateGarpProject(); err != nil {
+		return err
+	}
+
+	author := newAuthor
+	if !cmd.Flags().Changed("author") {
+		author = projectConfig.Site.Author
+	}
+
+	request := scaffold.NewContent{
+		Kind:   cmd.Name(),
+		Name:   args[0],
+		Dir:    newDir,
+		Author: author,
+		Draft:  newDraft,
+	}
+
+	layout := internal.GetProjectLayout()
+	created, err := request.Create(layout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Created %s: %s (archetype: %s)\n", request.Kind, created.Path, created.Archetype)
+	if page, err := content.ParsePage(created.RelPath, nil); err == nil {
+		fmt.Printf("  Preview it with 'garp serve' at %s\n", page.URL)
+	}
+	if request.Draft {
+		fmt.Printf("  Set draft: false in %s when it is ready to publish\n", created.Path)
+	}
+	return nil
+}
+
+// runNewForm declares a form in garp.toml and writes its snippet
+func runNewForm(cmd *cobra.Command, args []string) error {
+	if err := internal.ValidateGarpProject(); err != nil {
+		return err
+	}
+
+	name := scaffold.Slugify(args[0])
+	if _, exists := projectConfig.Forms.Schemas[name]; exists {
+		return internal.NewValidationErrorWithSuggestions(
+			fmt.Sprintf("form %s is already declared in %s", name, config.FileName),
+			[]string{fmt.Sprintf("Edit [forms.schemas.%s] in %s", name, config.FileName)},
+		)
+	}
+
+	request := scaffold.NewForm{
+		Name:   args[0],
+		Server: fmt.Sprintf("http://localhost:%d", projectConfig.Forms.Port),
+	}
+	created, err := request.Create(internal.GetProjectLayout(), config.FileName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Declared form %s in %s as [forms.schemas.%s]\n", created.Name, config.FileName, created.Name)
+	fmt.Printf("✓ Created HTML snippet: %s\n", created.SnippetPath)
+	fmt.Printf("  Submissions go to /forms/%s on 'garp form-server'\n", created.Name)
+	fmt.Printf("  Edit the fields in %s, then update the snippet to match\n", config.FileName)
+	return nil
+}
+
+func init() {
+	newCmd.PersistentFlags().StringVar(&newDir, "dir", "", "Directory inside the source directory (posts default to blog)")
+	newCmd.PersistentFlags().StringVar(&newAuthor, "author", "", "Author for the frontmatter (defaults to site.author)")
+	newCmd.PersistentFlags().BoolVar(&newDraft, "draft", true, "Create the content as a draft")
+
+	newCmd.AddCommand(newPageCmd)
+	newCmd.AddCommand(newPostCmd)
+	newCmd.AddCommand(newSectionCmd)
+	newCmd.AddCommand(newFormCmd)
+	rootCmd.AddCommand(newCmd)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/mattsafaii/garp/internal"
 	"github.com/mattsafaii/garp/internal/deploy"
 
 	"github.com/spf13/cobra"
@@ -11,16 +12,33 @@ var rollbackCmd = &cobra.Command{
 	Use:   "rollback [deployment-id]",
 	Short: "Rollback to a previous deployment",
 	Long: `Rollback to a previous successful deployment. 
-If no deployment ID is specified, rolls back to the last successful deployment.`,
+If no deployment ID is specified, rolls back to the last successful deployment.
+
+Git deployments are rolled back by committing the content of the deployed
+commit on top of the deploy branch and pushing it; the working tree must be
+clean. Use --force to force push the recorded commit to the deploy branch
+instead. Without an ID, the last deployment of a different commit is used.
+
+Rsync deployments made with deploy.releases enabled are rolled back by
+pointing the current symlink at the release of that deployment. Without an
+ID, the newest earlier release that is still kept on the target is used.`,
 	RunE: runRollback,
 }
 
 var (
 	rollbackDryRun  bool
 	rollbackVerbose bool
+	rollbackForce   bool
+	rollbackUnlock  bool
 )
 
 func runRollback(cmd *cobra.Command, args []string) error {
+	lock, err := deploy.AcquireLock("garp rollback", rollbackUnlock)
+	if err != nil {
+		return err
+	}
+	defer releaseLock(lock)
+
 	history, err := deploy.NewDeploymentHistory()
 	if err != nil {
 		return fmt.Errorf("failed to load deployment history: %v", err)
@@ -40,6 +58,25 @@ func runRollback(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("no successful deployment found: %v", err)
 		}
+
+		// The latest release is usually live, so go back to the one before it
+		if targetDeployment.Release != nil {
+			current := *targetDeployment.Release
+			live, err := deploy.CurrentRelease(current)
+			if err != nil {
+				return fmt.Errorf("failed to read the live release: %v", err)
+			}
+			current.Name = live
+			targetDeployment, err = history.GetRollbackRelease(current)
+			if err != nil {
+				return err
+			}
+		} else if targetDeployment.Strategy == deploy.GitStrategy.String() {
+			targetDeployment, err = history.GetRollbackCommit(targetDeployment)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	if !targetDeployment.Success {
@@ -49,46 +86,119 @@ func runRollback(cmd *cobra.Command, args []string) error {
 	fmt.Printf("🔄 Rolling back to deployment %s\n", targetDeployment.ID)
 	fmt.Printf("Target: %s (%s)\n", targetDeployment.Strategy, targetDeployment.Timestamp.Format("2006-01-02 15:04:05"))
 
+	// For Git deployments, we need to handle rollback differently
+	if targetDeployment.Strategy == deploy.GitStrategy.String() {
+		return rollbackGitDeployment(targetDeployment)
+	}
+
 	if rollbackDryRun {
+		if release := targetDeployment.Release; release != nil {
+			fmt.Printf("Would point %s/%s at %s/%s\n", release.Target(), deploy.CurrentLink, deploy.ReleasesDir, release.Name)
+		}
 		fmt.Println("🧪 Dry run - no actual rollback will be performed")
 		return nil
 	}
 
-	// For Git deployments, we need to handle rollback differently
-	if targetDeployment.Strategy == "git" {
-		return rollbackGitDeployment(targetDeployment)
+	if targetDeployment.Release != nil {
+		return rollbackRelease(targetDeployment)
+	}
+
+	// Rsync deployments without releases overwrote the previous files
+	fmt.Println("⚠️  This deployment was not made into a release directory and cannot be restored")
+	fmt.Println("Suggested action: Set releases = true in the [deploy] section of garp.toml so future deployments can be rolled back,")
+	fmt.Println("or manually revert your changes and run 'garp deploy' again")
+
+	return nil
+}
+
+func rollbackRelease(record *deploy.DeploymentRecord) error {
+	release := *record.Release
+
+	if rollbackVerbose {
+		fmt.Printf("Switching %s/%s to %s/%s\n", release.Target(), deploy.CurrentLink, deploy.ReleasesDir, release.Name)
 	}
 
-	// For other deployment types, we can re-deploy with the same configuration
-	fmt.Println("⚠️  Rollback not yet implemented for this deployment strategy")
-	fmt.Println("Suggested action: Manually revert your changes and run 'garp deploy' again")
+	if err := deploy.RollbackRelease(release); err != nil {
+		return fmt.Errorf("rollback failed: %v", err)
+	}
 
+	fmt.Printf("✅ Release %s is live again at %s\n", release.Name, release.Target())
 	return nil
 }
 
 func rollbackGitDeployment(record *deploy.DeploymentRecord) error {
-	if record.GitCommit == "" {
-		return fmt.Errorf("no Git commit information available for rollback")
+	remote, branch, err := gitRollbackTarget(record)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("🔄 Rolling back Git deployment to commit %s\n", record.GitCommit)
+	result, err := deploy.RollbackGit(record, deploy.GitRollback{
+		Remote:  remote,
+		Branch:  branch,
+		Force:   rollbackForce,
+		DryRun:  rollbackDryRun,
+		Verbose: rollbackVerbose,
 
-	// This is a simplified rollback - in production you might want to:
-	// 1. Create a new commit that reverts to the target state
-	// 2. Or checkout the specific commit and push
-	// 3. Handle merge conflicts and dependencies
+		HistoryLimit: projectConfig.Deploy.HistoryLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("rollback failed: %v", err)
+	}
 
-	fmt.Println("⚠️  Git rollback requires manual intervention:")
-	fmt.Printf("1. git checkout %s\n", record.GitCommit)
-	fmt.Println("2. Review the changes")
-	fmt.Println("3. Create a new commit or force push if appropriate")
-	fmt.Println("4. Run 'garp deploy' to deploy the rolled-back version")
+	for _, msg := range result.Messages {
+		fmt.Printf("  %s\n", msg)
+	}
+	if rollbackDryRun {
+		fmt.Println("🧪 Dry run - no actual rollback will be performed")
+		return nil
+	}
 
+	fmt.Printf("✅ Rolled back to deployment %s in %v\n", record.ID, result.Duration)
 	return nil
 }
 
+// gitRollbackTarget returns the remote and branch a git deployment was pushed
+// to. Deployments recorded without their remote are resolved through their
+// environment, or the default deploy settings when they had none.
+func gitRollbackTarget(record *deploy.DeploymentRecord) (remote, branch string, err error) {
+	if record.GitRemote != "" {
+		return record.GitRemote, record.GitBranch, nil
+	}
+
+	config := deploy.DeploymentConfig{
+		Strategy:  deploy.GitStrategy,
+		GitRemote: projectConfig.Deploy.Remote,
+		GitBranch: projectConfig.Deploy.Branch,
+	}
+	if record.Environment != "" {
+		env, err := deployEnvironment(record.Environment, deploy.GitStrategy.String())
+		if err == nil {
+			err = env.Apply(&config)
+		}
+		if err == nil && config.Strategy != deploy.GitStrategy {
+			err = fmt.Errorf("it is now a %s environment", config.Strategy)
+		}
+		if err != nil {
+			return "", "", internal.NewValidationErrorWithSuggestions(
+				fmt.Sprintf("cannot find where deployment %s was pushed: environment '%s' cannot be resolved: %v", record.ID, record.Environment, err),
+				[]string{
+					fmt.Sprintf("Restore the git environment '%s' and try again", record.Environment),
+					fmt.Sprintf("Or push commit %s to the deploy branch yourself", record.GitCommit),
+				},
+			)
+		}
+	}
+
+	if config.GitBranch == "" {
+		config.GitBranch = record.GitBranch
+	}
+	return config.GitRemote, config.GitBranch, nil
+}
+
 func init() {
 	rollbackCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", false, "Show what would be rolled back without performing rollback")
+	rollbackCmd.Flags().BoolVar(&rollbackForce, "force", false, "Force push the recorded commit instead of creating a revert commit (git only)")
+	rollbackCmd.Flags().BoolVar(&rollbackUnlock, "force-unlock", false, "Remove the deploy lock of another deployment before rolling back")
 	rollbackCmd.Flags().BoolVarP(&rollbackVerbose, "verbose", "v", false, "Show detailed rollback output")
 	rootCmd.AddCommand(rollbackCmd)
 }
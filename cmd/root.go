@@ -3,17 +3,25 @@ package cmd
 import (
 	"fmt"
 	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/config"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
 	version = "0.1.0"
 	verbose bool
 	debug   bool
+
+	// projectConfig is the effective configuration of the current project
+	projectConfig = config.Default()
 )
 
+// configKeyAnnotation links a command flag to a garp.toml key
+const configKeyAnnotation = "garp_config_key"
+
 var rootCmd = &cobra.Command{
 	Use:   "garp",
 	Short: "A legendary, no-nonsense static site engine",
@@ -32,7 +40,10 @@ Examples:
 	Version:                    version,
 	SuggestionsMinimumDistance: 2,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		return initializeLogging()
+		if err := initializeLogging(); err != nil {
+			return err
+		}
+		return loadProjectConfig(cmd)
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 		return internal.CloseGlobalLogger()
@@ -70,6 +81,39 @@ func initializeLogging() error {
 	return nil
 }
 
+// loadProjectConfig resolves garp.toml, the environment and the command's
+// explicitly set flags into projectConfig
+func loadProjectConfig(cmd *cobra.Command) error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	var flagErr error
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		keys := f.Annotations[configKeyAnnotation]
+		if flagErr != nil || len(keys) == 0 {
+			return
+		}
+		flagErr = cfg.Set(keys[0], f.Value.String(), config.SourceFlag+":--"+f.Name)
+	})
+	if flagErr != nil {
+		return flagErr
+	}
+
+	projectConfig = cfg
+	internal.SetProjectLayout(cfg.Layout())
+	internal.LogDebug("Project configuration loaded")
+	return nil
+}
+
+// bindConfigFlag marks a flag as an override for a garp.toml key
+func bindConfigFlag(cmd *cobra.Command, flag, key string) {
+	if err := cmd.Flags().SetAnnotation(flag, configKeyAnnotation, []string{key}); err != nil {
+		panic(err)
+	}
+}
+
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
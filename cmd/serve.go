@@ -14,16 +14,27 @@ var serveCmd = &cobra.Command{
 	Long: `Start the Caddy server for local development with 
 live reloading and markdown rendering.
 
+The server runs your project's Caddyfile as-is, overriding only the
+site address with the configured host and port ([serve] in garp.toml,
+DEV_SERVER_HOST/DEV_SERVER_PORT, or --host and --port).
+
 The development server provides:
 - Automatic markdown rendering with Goldmark
 - YAML frontmatter parsing
 - Template variables for metadata
 - Static file serving
-- Live reloading during development`,
+- Live reloading during development (CSS changes are applied
+  without a full page reload; disable with --no-reload)
+- A DRAFT or SCHEDULED banner on pages that are drafts or dated in
+  the future (shown through the live reload proxy)`,
 	Example: `  garp serve
   garp serve --port 3000
-  garp serve --host 0.0.0.0 --port 8080`,
+  garp serve --host 0.0.0.0 --port 8080
+  garp serve --no-reload`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		host := projectConfig.Serve.Host
+		port := projectConfig.Serve.Port
+
 		// Log server start
 		internal.LogInfo("Starting development server",
 			"host", host,
@@ -56,6 +67,7 @@ The development server provides:
 
 		// Create and configure Caddy server
 		caddyServer := server.NewCaddyServer(host, port)
+		caddyServer.LiveReload = !noReload
 		internal.LogDebug("Caddy server instance created")
 
 		// Start the server (this will block until stopped)
@@ -65,13 +77,13 @@ The development server provides:
 	},
 }
 
-var (
-	port int
-	host string
-)
+var noReload bool
 
 func init() {
-	serveCmd.Flags().IntVarP(&port, "port", "p", 8080, "Port to serve on")
-	serveCmd.Flags().StringVar(&host, "host", "localhost", "Host to bind to")
+	serveCmd.Flags().IntP("port", "p", 8080, "Port to serve on")
+	serveCmd.Flags().String("host", "localhost", "Host to bind to")
+	serveCmd.Flags().BoolVar(&noReload, "no-reload", false, "Disable live reload")
+	bindConfigFlag(serveCmd, "port", "serve.port")
+	bindConfigFlag(serveCmd, "host", "serve.host")
 	rootCmd.AddCommand(serveCmd)
 }
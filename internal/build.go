@@ -13,6 +13,10 @@ import (
 type BuildOptions struct {
 	CSSOnly    bool
 	SearchOnly bool
+	SkipSearch bool // search disabled in project configuration
+	Drafts     bool // include draft pages in static builds
+	Future     bool // include pages dated in the future in static builds
+	BaseURL    string
 	Watch      bool
 	Verbose    bool
 }
@@ -22,11 +26,13 @@ var buildMutex sync.Mutex
 
 // BuildResult contains information about a completed build
 type BuildResult struct {
-	Success     bool
-	Duration    time.Duration
-	CSSBuilt    bool
-	SearchBuilt bool
-	Errors      []string
+	Success          bool
+	Duration         time.Duration
+	CSSBuilt         bool
+	SearchBuilt      bool
+	PagesRendered    int // pages written by a static build
+	PagesUnpublished int // drafts and scheduled pages left out of a static build
+	Errors           []string
 }
 
 // BuildCSS executes the CSS build process using the bin/build-css script
@@ -64,9 +70,11 @@ func BuildCSS(options BuildOptions) (*BuildResult, error) {
 		args = append(args, "--watch")
 	}
 
-	// Execute build script
+	// Execute build script with the project layout in its environment
+	layout := GetProjectLayout()
 	cmd := exec.Command(buildScript, args...)
 	cmd.Dir = "."
+	cmd.Env = append(os.Environ(), layout.Env()...)
 
 	// Capture output for verbose mode
 	if options.Verbose {
@@ -98,7 +106,7 @@ func BuildCSS(options BuildOptions) (*BuildResult, error) {
 		}
 
 		// Check if output file was created
-		outputFile := "site/style.css"
+		outputFile := layout.CSSOutput
 		if _, err := os.Stat(outputFile); os.IsNotExist(err) {
 			errMsg := "CSS build completed but output file not found: " + outputFile
 			result.Errors = append(result.Errors, errMsg)
@@ -152,9 +160,11 @@ func BuildSearch(options BuildOptions) (*BuildResult, error) {
 		args = append(args, "--verbose")
 	}
 
-	// Execute build script
+	// Execute build script with the project layout in its environment
+	layout := GetProjectLayout()
 	cmd := exec.Command(buildScript, args...)
 	cmd.Dir = "."
+	cmd.Env = append(os.Environ(), layout.Env()...)
 
 	// Execute the command
 	var err error
@@ -181,7 +191,7 @@ func BuildSearch(options BuildOptions) (*BuildResult, error) {
 	}
 
 	// Check if output directory was created
-	outputDir := "site/_pagefind"
+	outputDir := layout.SearchOutput
 	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
 		errMsg := "Search index build completed but output directory not found: " + outputDir
 		result.Errors = append(result.Errors, errMsg)
@@ -236,8 +246,8 @@ func BuildAll(options BuildOptions) (*BuildResult, error) {
 		}
 	}
 
-	// Build search index unless css-only is specified
-	if !options.CSSOnly {
+	// Build search index unless css-only is specified or search is disabled
+	if !options.CSSOnly && !options.SkipSearch {
 		searchResult, err := BuildSearch(options)
 		result.SearchBuilt = searchResult.SearchBuilt
 		if err != nil {
@@ -267,7 +277,8 @@ func BuildAll(options BuildOptions) (*BuildResult, error) {
 func WatchFiles(options BuildOptions) error {
 	if options.Verbose {
 		fmt.Println("👀 Starting file watcher...")
-		fmt.Println("Watching: input.css, site/")
+		layout := GetProjectLayout()
+		fmt.Printf("Watching: %s, %s/\n", layout.CSSInput, layout.SourceDir)
 		fmt.Println("Press Ctrl+C to stop watching")
 	}
 
@@ -284,11 +295,13 @@ func WatchFiles(options BuildOptions) error {
 func GetBuildInfo() map[string]interface{} {
 	info := make(map[string]interface{})
 
+	layout := GetProjectLayout()
+
 	// Check for required files
 	files := map[string]bool{
-		"input.css":     false,
-		"bin/build-css": false,
-		"public/":       false,
+		layout.CSSInput:        false,
+		"bin/build-css":        false,
+		layout.SourceDir + "/": false,
 	}
 
 	for file := range files {
@@ -309,7 +322,7 @@ func GetBuildInfo() map[string]interface{} {
 
 	// Check for output files
 	outputs := map[string]bool{
-		"site/style.css": false,
+		layout.CSSOutput: false,
 	}
 
 	for file := range outputs {
@@ -325,9 +338,13 @@ func GetBuildInfo() map[string]interface{} {
 
 // CleanBuildArtifacts removes generated build files
 func CleanBuildArtifacts() error {
+	layout := GetProjectLayout()
 	filesToClean := []string{
-		"site/style.css",
-		"site/_pagefind/",
+		layout.CSSOutput,
+		layout.SearchOutput,
+	}
+	if layout.IsStatic() {
+		filesToClean = append(filesToClean, layout.OutputDir)
 	}
 
 	var errors []string
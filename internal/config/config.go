@@ -0,0 +1,494 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mattsafaii/garp/internal"
+)
+
+// FileName is the project configuration file created by 'garp init'
+const FileName = "garp.toml"
+
+// Sources a configuration value can come from, lowest precedence first
+const (
+	SourceDefault = "default"
+	SourceFile    = FileName
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Config is the typed project configuration. Values are resolved with the
+// precedence flags > environment > garp.toml > defaults.
+type Config struct {
+	Site   SiteConfig   `toml:"site"`
+	Serve  ServeConfig  `toml:"serve"`
+	Build  BuildConfig  `toml:"build"`
+	Search SearchConfig `toml:"search"`
+	Forms  FormsConfig  `toml:"forms"`
+	Deploy DeployConfig `toml:"deploy"`
+
+	sources map[string]string
+}
+
+// SiteConfig describes the published site
+type SiteConfig struct {
+	BaseURL string `toml:"base_url" env:"SITE_BASE_URL"`
+	Author  string `toml:"author" env:"SITE_AUTHOR"`
+}
+
+// ServeConfig configures the development server
+type ServeConfig struct {
+	Host string `toml:"host" env:"DEV_SERVER_HOST"`
+	Port int    `toml:"port" env:"DEV_SERVER_PORT"`
+}
+
+// BuildConfig configures where sources and build outputs live
+type BuildConfig struct {
+	SourceDir string `toml:"source_dir" env:"BUILD_SOURCE_DIR"`
+	OutputDir string `toml:"output_dir" env:"BUILD_OUTPUT_DIR"`
+	CSSInput  string `toml:"css_input" env:"CSS_INPUT_FILE"`
+	CSSOutput string `toml:"css_output" env:"CSS_OUTPUT_FILE"`
+	Static    bool   `toml:"static" env:"BUILD_STATIC"`
+	StaticDir string `toml:"static_dir" env:"BUILD_STATIC_DIR"`
+}
+
+// SearchConfig configures the Pagefind search index
+type SearchConfig struct {
+	Enabled   bool   `toml:"enabled" env:"SEARCH_ENABLED"`
+	OutputDir string `toml:"output_dir" env:"SEARCH_OUTPUT_DIR"`
+}
+
+// FormsConfig configures the contact form server
+type FormsConfig struct {
+	Enabled            bool                  `toml:"enabled" env:"FORMS_ENABLED"`
+	Host               string                `toml:"host" env:"FORM_SERVER_HOST"`
+	Port               int                   `toml:"port" env:"FORM_SERVER_PORT"`
+	ToEmail            string                `toml:"to_email" env:"FORM_TO_EMAIL"`
+	FromEmail          string                `toml:"from_email" env:"FORM_FROM_EMAIL"`
+	ResendAPIKey       string                `toml:"resend_api_key" env:"RESEND_API_KEY" secret:"true"`
+	SubjectPrefix      string                `toml:"subject_prefix" env:"EMAIL_SUBJECT_PREFIX"`
+	ReplyTo            string                `toml:"reply_to" env:"EMAIL_REPLY_TO"`
+	EmailBackend       string                `toml:"email_backend" env:"FORM_EMAIL_BACKEND"`
+	SMTPHost           string                `toml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort           int                   `toml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername       string                `toml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword       string                `toml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	SMTPSecurity       string                `toml:"smtp_security" env:"SMTP_SECURITY"`
+	MailDir            string                `toml:"mail_dir" env:"FORM_MAIL_DIR"`
+	StoreDir           string                `toml:"store_dir" env:"FORM_STORE_DIR"`
+	WebhookURLs        []string              `toml:"webhook_urls" env:"FORM_WEBHOOK_URLS"`
+	WebhookSecret      string                `toml:"webhook_secret" env:"FORM_WEBHOOK_SECRET" secret:"true"`
+	WebhookDir         string                `toml:"webhook_dir" env:"FORM_WEBHOOK_DIR"`
+	WebhookMaxAttempts int                   `toml:"webhook_max_attempts" env:"FORM_WEBHOOK_MAX_ATTEMPTS"`
+	Schemas            map[string]FormSchema `toml:"schemas"`
+}
+
+// FormSchema declares a named form served at /forms/<name>
+type FormSchema struct {
+	Title     string      `toml:"title"`
+	Recipient string      `toml:"recipient"`
+	Subject   string      `toml:"subject"`
+	Redirect  string      `toml:"redirect"`
+	Fields    []FormField `toml:"fields"`
+}
+
+// FormField declares a field of a form
+type FormField struct {
+	Name      string   `toml:"name"`
+	Label     string   `toml:"label"`
+	Type      string   `toml:"type"`
+	Required  bool     `toml:"required"`
+	MaxLength int      `toml:"max_length"`
+	Options   []string `toml:"options"`
+}
+
+// DeployConfig configures the default deployment target and named
+// deployment environments
+type DeployConfig struct {
+	Target       string                       `toml:"target" env:"DEPLOY_TARGET"`
+	Host         string                       `toml:"host" env:"DEPLOY_HOST"`
+	User         string                       `toml:"user" env:"DEPLOY_USER"`
+	Path         string                       `toml:"path" env:"DEPLOY_PATH"`
+	Remote       string                       `toml:"remote" env:"DEPLOY_REMOTE"`
+	Branch       string                       `toml:"branch" env:"DEPLOY_BRANCH"`
+	Releases     bool                         `toml:"releases" env:"DEPLOY_RELEASES"`
+	KeepReleases int                          `toml:"keep_releases" env:"DEPLOY_KEEP_RELEASES"`
+	SiteID       string                       `toml:"site_id" env:"DEPLOY_SITE_ID"`
+	APIURL       string                       `toml:"api_url" env:"DEPLOY_API_URL"`
+	APIKey       string                       `toml:"api_key" env:"DEPLOY_API_KEY" secret:"true"`
+	Bucket       string                       `toml:"bucket" env:"DEPLOY_BUCKET"`
+	Prefix       string                       `toml:"prefix" env:"DEPLOY_PREFIX"`
+	Region       string                       `toml:"region" env:"AWS_REGION"`
+	Endpoint     string                       `toml:"endpoint" env:"DEPLOY_ENDPOINT"`
+	AccessKeyID  string                       `toml:"access_key_id" env:"AWS_ACCESS_KEY_ID" secret:"true"`
+	SecretKey    string                       `toml:"secret_access_key" env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	SessionToken string                       `toml:"session_token" env:"AWS_SESSION_TOKEN" secret:"true"`
+	HistoryLimit int                          `toml:"history_limit" env:"DEPLOY_HISTORY_LIMIT"`
+	Hooks        DeployHooks                  `toml:"hooks"`
+	Environments map[string]DeployEnvironment `toml:"environments"`
+}
+
+// DeployHooks lists the shell commands run at each stage of a deployment
+type DeployHooks struct {
+	PreBuild   []string `toml:"pre_build"`
+	PreDeploy  []string `toml:"pre_deploy"`
+	PostDeploy []string `toml:"post_deploy"`
+	OnFailure  []string `toml:"on_failure"`
+}
+
+// DeployEnvironment is a named deployment target declared in garp.toml. Its
+// keys are the settings 'garp deploy-config set' accepts.
+type DeployEnvironment struct {
+	Strategy     string   `toml:"strategy"`
+	Host         string   `toml:"host"`
+	User         string   `toml:"user"`
+	Path         string   `toml:"path"`
+	Remote       string   `toml:"remote"`
+	Branch       string   `toml:"branch"`
+	Releases     *bool    `toml:"releases"`
+	KeepReleases int      `toml:"keep_releases"`
+	SiteID       string   `toml:"site_id"`
+	APIURL       string   `toml:"api_url"`
+	Bucket       string   `toml:"bucket"`
+	Prefix       string   `toml:"prefix"`
+	Region       string   `toml:"region"`
+	Endpoint     string   `toml:"endpoint"`
+	Excludes     []string `toml:"excludes"`
+}
+
+// Settings returns the settings given for the environment, keyed by their
+// garp.toml names and formatted like 'garp deploy-config set' stores them
+func (e DeployEnvironment) Settings() map[string]string {
+	settings := make(map[string]string)
+	v := reflect.ValueOf(e)
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("toml")
+		value := v.Field(i)
+		if key == "strategy" || value.IsZero() {
+			continue
+		}
+		if value.Kind() == reflect.Pointer {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Slice {
+			items := make([]string, value.Len())
+			for j := range items {
+				items[j] = fmt.Sprint(value.Index(j).Interface())
+			}
+			settings[key] = strings.Join(items, ",")
+			continue
+		}
+		settings[key] = fmt.Sprint(value.Interface())
+	}
+	return settings
+}
+
+// Field is a single resolved configuration value
+type Field struct {
+	Key    string
+	Value  string
+	Source string
+	Env    string
+	Secret bool
+}
+
+// Default returns the configuration used when nothing else is set
+func Default() *Config {
+	layout := internal.DefaultProjectLayout()
+
+	cfg := &Config{
+		Serve: ServeConfig{
+			Host: "localhost",
+			Port: 8080,
+		},
+		Build: BuildConfig{
+			SourceDir: layout.SourceDir,
+			OutputDir: layout.OutputDir,
+			CSSInput:  layout.CSSInput,
+			CSSOutput: layout.CSSOutput,
+			StaticDir: layout.StaticDir,
+		},
+		Search: SearchConfig{
+			Enabled:   true,
+			OutputDir: layout.SearchOutput,
+		},
+		Forms: FormsConfig{
+			Host:               "0.0.0.0",
+			Port:               4567,
+			EmailBackend:       "resend",
+			MailDir:            ".garp/mail",
+			StoreDir:           ".garp/submissions",
+			WebhookDir:         ".garp/webhooks",
+			WebhookMaxAttempts: 8,
+		},
+		Deploy: DeployConfig{
+			Target:       "git",
+			Remote:       "origin",
+			KeepReleases: 5,
+			HistoryLimit: 50,
+		},
+		sources: make(map[string]string),
+	}
+
+	for _, f := range cfg.fields() {
+		cfg.sources[f.key] = SourceDefault
+	}
+
+	return cfg
+}
+
+// Load resolves the configuration for the project in dir from defaults,
+// garp.toml, the .env file and the process environment
+func Load(dir string) (*Config, error) {
+	return load(dir, os.LookupEnv)
+}
+
+// LoadFile resolves the configuration from defaults and garp.toml only,
+// ignoring the environment
+func LoadFile(dir string) (*Config, error) {
+	cfg := Default()
+	if err := cfg.loadFile(filepath.Join(dir, FileName)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func load(dir string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	if err := cfg.loadFile(filepath.Join(dir, FileName)); err != nil {
+		return nil, err
+	}
+
+	dotenv, err := ReadDotEnv(filepath.Join(dir, ".env"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty variables (e.g. "DEPLOY_HOST=" copied from .env.example) are
+	// treated as unset
+	for _, f := range cfg.fields() {
+		if f.env == "" {
+			continue
+		}
+		if value, ok := lookupEnv(f.env); ok && value != "" {
+			if err := cfg.Set(f.key, value, SourceEnv+":"+f.env); err != nil {
+				return nil, err
+			}
+		} else if value, ok := dotenv[f.env]; ok && value != "" {
+			if err := cfg.Set(f.key, value, SourceDotEnv+":"+f.env); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// loadFile decodes garp.toml on top of the current values
+func (c *Config) loadFile(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	metadata, err := toml.DecodeFile(path, c)
+	if err != nil {
+		return internal.NewConfigurationErrorWithSuggestions(
+			fmt.Sprintf("invalid %s: %v", FileName, err),
+			[]string{
+				fmt.Sprintf("Check the TOML syntax of %s", FileName),
+				"Run 'garp config show' to see the effective configuration",
+			},
+		)
+	}
+
+	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return internal.NewConfigurationErrorWithSuggestions(
+			fmt.Sprintf("unknown keys in %s: %s", FileName, strings.Join(keys, ", ")),
+			[]string{
+				"Check for typos in key names",
+				"Run 'garp config show' to list the supported keys",
+			},
+		)
+	}
+
+	for _, f := range c.fields() {
+		if metadata.IsDefined(strings.Split(f.key, ".")...) {
+			c.sources[f.key] = SourceFile
+		}
+	}
+
+	return nil
+}
+
+// Set overrides a value by its dotted key (e.g. "serve.port") and records
+// where it came from
+func (c *Config) Set(key, value, source string) error {
+	for _, f := range c.fields() {
+		if f.key != key {
+			continue
+		}
+		if err := setValue(f.value, value); err != nil {
+			return internal.NewConfigurationError(fmt.Sprintf("invalid value %q for %s (from %s): %v", value, key, source, err))
+		}
+		c.sources[key] = source
+		return nil
+	}
+	return internal.NewConfigurationError(fmt.Sprintf("unknown configuration key: %s", key))
+}
+
+// Source returns where the value of a key came from
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Fields returns every scalar setting with its effective value and source
+func (c *Config) Fields() []Field {
+	var fields []Field
+	for _, f := range c.fields() {
+		fields = append(fields, Field{
+			Key:    f.key,
+			Value:  formatValue(f.value),
+			Source: c.Source(f.key),
+			Env:    f.env,
+			Secret: f.secret,
+		})
+	}
+	return fields
+}
+
+// FormNames returns the forms declared in garp.toml
+func (c *Config) FormNames() []string {
+	names := make([]string, 0, len(c.Forms.Schemas))
+	for name := range c.Forms.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnvironmentNames returns the deployment environments declared in garp.toml
+func (c *Config) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Deploy.Environments))
+	for name := range c.Deploy.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Layout returns the project layout described by the build settings. With
+// build.static enabled the rendered static directory is the output.
+func (c *Config) Layout() internal.ProjectLayout {
+	layout := internal.ProjectLayout{
+		SourceDir:    c.Build.SourceDir,
+		OutputDir:    c.Build.OutputDir,
+		CSSInput:     c.Build.CSSInput,
+		CSSOutput:    c.Build.CSSOutput,
+		SearchOutput: c.Search.OutputDir,
+		StaticDir:    c.Build.StaticDir,
+	}
+	if c.Build.Static {
+		return layout.Static()
+	}
+	return layout
+}
+
+// field describes a settable scalar in the configuration struct
+type field struct {
+	key    string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// fields walks the configuration sections and returns their scalar settings
+func (c *Config) fields() []field {
+	var fields []field
+
+	root := reflect.ValueOf(c).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		sectionType := root.Type().Field(i)
+		section := sectionType.Tag.Get("toml")
+		if section == "" || sectionType.Type.Kind() != reflect.Struct {
+			continue
+		}
+
+		sectionValue := root.Field(i)
+		for j := 0; j < sectionValue.NumField(); j++ {
+			fieldType := sectionValue.Type().Field(j)
+			name := fieldType.Tag.Get("toml")
+			if name == "" || fieldType.Type.Kind() == reflect.Map || fieldType.Type.Kind() == reflect.Struct {
+				continue
+			}
+			fields = append(fields, field{
+				key:    section + "." + name,
+				env:    fieldType.Tag.Get("env"),
+				secret: fieldType.Tag.Get("secret") == "true",
+				value:  sectionValue.Field(j),
+			})
+		}
+	}
+
+	return fields
+}
+
+// setValue parses a string into a reflected scalar
+func setValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Kind())
+	}
+	return nil
+}
+
+// formatValue renders a reflected scalar for display
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile creates a file in dir with the given content
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+// envMap returns a lookup function backed by a map
+func envMap(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := load(t.TempDir(), envMap(nil))
+	if err != nil {
+		t.Fatalf("load returned error: %v", err)
+	}
+
+	if cfg.Serve.Host != "localhost" || cfg.Serve.Port != 8080 {
+		t.Errorf("Unexpected serve defaults: %+v", cfg.Serve)
+	}
+	if cfg.Forms.Port != 4567 {
+		t.Errorf("Expected form port 4567, got %d", cfg.Forms.Port)
+	}
+	if !cfg.Search.Enabled {
+		t.Error("Expected search to be enabled by default")
+	}
+	if cfg.Source("serve.port") != SourceDefault {
+		t.Errorf("Expected default source, got %s", cfg.Source("serve.port"))
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, FileName, `
+[serve]
+host = "0.0.0.0"
+port = 3000
+
+[build]
+output_dir = "dist"
+
+[deploy]
+target = "rsync"
+host = "file.example.com"
+`)
+	writeFile(t, dir, ".env", `
+# Local overrides
+DEV_SERVER_PORT=4000
+export DEPLOY_HOST="dotenv.example.com"
+RESEND_API_KEY='re_secret'
+`)
+
+	cfg, err := load(dir, envMap(map[string]string{
+		"DEPLOY_HOST": "env.example.com",
+	}))
+	if err != nil {
+		t.Fatalf("load returned error: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source string
+	}{
+		{"serve.host", "0.0.0.0", SourceFile},
+		{"serve.port", "4000", ".env:DEV_SERVER_PORT"},
+		{"build.output_dir", "dist", SourceFile},
+		{"build.source_dir", "public", SourceDefault},
+		{"deploy.target", "rsync", SourceFile},
+		{"deploy.host", "env.example.com", "env:DEPLOY_HOST"},
+		{"forms.resend_api_key", "re_secret", ".env:RESEND_API_KEY"},
+	}
+
+	fields := make(map[string]Field)
+	for _, field := range cfg.Fields() {
+		fields[field.Key] = field
+	}
+
+	for _, test := range tests {
+		field, ok := fields[test.key]
+		if !ok {
+			t.Errorf("Missing field %s", test.key)
+			continue
+		}
+		if field.Value != test.value {
+			t.Errorf("%s: expected value %q, got %q", test.key, test.value, field.Value)
+		}
+		if field.Source != test.source {
+			t.Errorf("%s: expected source %q, got %q", test.key, test.source, field.Source)
+		}
+	}
+
+	if err := cfg.Set("serve.port", "5000", "flag:--port"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if cfg.Serve.Port != 5000 || cfg.Source("serve.port") != "flag:--port" {
+		t.Errorf("Flag override not applied: port=%d source=%s", cfg.Serve.Port, cfg.Source("serve.port"))
+	}
+
+	if layout := cfg.Layout(); layout.OutputDir != "dist" || layout.SourceDir != "public" {
+		t.Errorf("Unexpected layout: %+v", layout)
+	}
+}
+
+func TestLoadDeployEnvironments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, FileName, `
+[deploy.environments.production]
+strategy = "rsync"
+host = "example.com"
+user = "deploy"
+path = "/var/www/site"
+excludes = ["*.log", "drafts/"]
+releases = true
+keep_releases = 3
+
+[deploy.environments.staging]
+strategy = "git"
+branch = "staging"
+
+[deploy.environments.preview]
+strategy = "netlify"
+site_id = "abc123"
+api_url = "https://netlify.example.com/api/v1"
+
+[deploy.environments.assets]
+strategy = "s3"
+bucket = "my-site"
+prefix = "www"
+region = "eu-west-1"
+endpoint = "http://localhost:9000"
+`)
+
+	cfg, err := load(dir, envMap(nil))
+	if err != nil {
+		t.Fatalf("load returned error: %v", err)
+	}
+
+	names := cfg.EnvironmentNames()
+	if len(names) != 4 || names[0] != "assets" || names[1] != "preview" || names[2] != "production" || names[3] != "staging" {
+		t.Fatalf("Unexpected environments: %v", names)
+	}
+
+	production := cfg.Deploy.Environments["production"]
+	want := map[string]string{
+		"host":          "example.com",
+		"user":          "deploy",
+		"path":          "/var/www/site",
+		"excludes":      "*.log,drafts/",
+		"releases":      "true",
+		"keep_releases": "3",
+	}
+	if settings := production.Settings(); !reflect.DeepEqual(settings, want) {
+		t.Errorf("Settings() = %v, want %v", settings, want)
+	}
+
+	preview := cfg.Deploy.Environments["preview"]
+	if preview.SiteID != "abc123" || preview.APIURL != "https://netlify.example.com/api/v1" {
+		t.Errorf("Unexpected preview environment: %+v", preview)
+	}
+
+	assets := cfg.Deploy.Environments["assets"]
+	if assets.Bucket != "my-site" || assets.Prefix != "www" || assets.Region != "eu-west-1" || assets.Endpoint != "http://localhost:9000" {
+		t.Errorf("Unexpected assets environment: %+v", assets)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		env    map[string]string
+	}{
+		{"unknown key", "[serve]\nhots = \"localhost\"\n", nil},
+		{"wrong type", "[serve]\nport = \"eighty\"\n", nil},
+		{"invalid syntax", "[serve\n", nil},
+		{"invalid env value", "", map[string]string{"DEV_SERVER_PORT": "abc"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, FileName, test.config)
+			if _, err := load(dir, envMap(test.env)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestSetUnknownKey(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Set("serve.colour", "blue", SourceFlag); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+}
+
+func TestReadDotEnvMissingFile(t *testing.T) {
+	values, err := ReadDotEnv(filepath.Join(t.TempDir(), ".env"))
+	if err != nil {
+		t.Fatalf("ReadDotEnv returned error: %v", err)
+	}
+	if len(values) != 0 {
+		t.Errorf("Expected no values, got %v", values)
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// ReadDotEnv parses a .env file of KEY=value lines. A missing file yields an
+// empty map. Blank lines, comments and an optional "export " prefix are
+// ignored, and values may be wrapped in single or double quotes.
+func ReadDotEnv(path string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to read %s", path), err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, internal.NewConfigurationError(fmt.Sprintf("%s:%d: expected KEY=value", path, lineNumber))
+		}
+
+		values[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to read %s", path), err)
+	}
+
+	return values, nil
+}
+
+// unquote strips matching quotes, or a trailing comment from unquoted values
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	if index := strings.Index(value, " #"); index != -1 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the syntax of a page's front matter
+type Format string
+
+const (
+	FormatNone Format = ""
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+	FormatJSON Format = "json"
+)
+
+// SplitFrontMatter separates a document into its metadata and body, the same
+// way Caddy's splitFrontMatter template function does. YAML (---), TOML
+// (+++) and JSON ({ ... }) front matter are supported.
+func SplitFrontMatter(input string) (map[string]interface{}, Format, string, error) {
+	meta := make(map[string]interface{})
+	input = strings.TrimPrefix(input, "\ufeff")
+
+	// JSON front matter is a leading object terminated by a line with "}"
+	if strings.HasPrefix(input, "{") {
+		end := strings.Index(input, "\n}")
+		if end == -1 {
+			return nil, FormatJSON, "", fmt.Errorf("unterminated JSON front matter")
+		}
+		if err := json.Unmarshal([]byte(input[:end+2]), &meta); err != nil {
+			return nil, FormatJSON, "", fmt.Errorf("invalid JSON front matter: %v", err)
+		}
+		return meta, FormatJSON, trimBody(input[end+2:]), nil
+	}
+
+	fences := []struct {
+		fence  string
+		format Format
+	}{
+		{"---", FormatYAML},
+		{"+++", FormatTOML},
+	}
+
+	for _, f := range fences {
+		if !strings.HasPrefix(input, f.fence+"\n") && !strings.HasPrefix(input, f.fence+"\r\n") {
+			continue
+		}
+
+		rest := input[strings.Index(input, "\n")+1:]
+		front, body, found := cutFence(rest, f.fence)
+		if !found {
+			return nil, f.format, "", fmt.Errorf("unterminated front matter: missing closing %s", f.fence)
+		}
+
+		var err error
+		if f.format == FormatYAML {
+			err = yaml.Unmarshal([]byte(front), &meta)
+		} else {
+			err = toml.Unmarshal([]byte(front), &meta)
+		}
+		if err != nil {
+			return nil, f.format, "", fmt.Errorf("invalid %s front matter: %v", strings.ToUpper(string(f.format)), err)
+		}
+		if meta == nil {
+			meta = make(map[string]interface{})
+		}
+		return meta, f.format, trimBody(body), nil
+	}
+
+	return meta, FormatNone, input, nil
+}
+
+// cutFence splits text at the first line consisting only of fence
+func cutFence(text, fence string) (string, string, bool) {
+	offset := 0
+	for offset <= len(text) {
+		end := strings.Index(text[offset:], "\n")
+		line := text[offset:]
+		if end != -1 {
+			line = text[offset : offset+end]
+		}
+
+		if strings.TrimRight(line, "\r \t") == fence {
+			if end == -1 {
+				return text[:offset], "", true
+			}
+			return text[:offset], text[offset+end+1:], true
+		}
+
+		if end == -1 {
+			break
+		}
+		offset += end + 1
+	}
+	return "", "", false
+}
+
+// trimBody drops the blank lines separating front matter from the body
+func trimBody(body string) string {
+	return strings.TrimLeft(body, "\r\n")
+}
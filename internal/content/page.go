@@ -0,0 +1,323 @@
+package content
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// Kind identifies how a page is authored
+type Kind string
+
+const (
+	KindMarkdown Kind = "markdown"
+	KindHTML     Kind = "html"
+)
+
+// dateLayouts are the formats accepted for frontmatter dates
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Page is a single markdown or HTML page in the source directory
+type Page struct {
+	Path    string // Path on disk, e.g. "public/blog/first.md"
+	RelPath string // Path relative to the source directory, e.g. "blog/first.md"
+	URL     string // URL the page is served on, e.g. "/blog/first/"
+	Kind    Kind
+	Format  Format                 // Front matter syntax, empty when there is none
+	Meta    map[string]interface{} // Raw front matter, exposed to templates as .Meta
+	Body    string                 // Content after the front matter
+
+	Title       string
+	Description string
+	Author      string
+	Category    string
+	Tags        []string
+	Draft       bool
+	Date        time.Time // Publication date, zero when not set
+	LastMod     time.Time // "lastmod" or "updated" front matter, else the file modification time
+}
+
+// LoadPages loads every page under root. Files and directories starting with
+// "_" (templates, partials, generated output) or "." are skipped.
+func LoadPages(root string) ([]*Page, error) {
+	var pages []*Page
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && Hidden(info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !IsPage(path) {
+			return nil
+		}
+
+		page, err := LoadPage(root, path)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*internal.AppError); ok {
+			return nil, err
+		}
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to load pages from %s", root), err)
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].RelPath < pages[j].RelPath
+	})
+	return pages, nil
+}
+
+// LoadPage reads and parses a single page inside root
+func LoadPage(root, path string) (*Page, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to read %s", path), err)
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to read %s", path), err)
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("%s is outside %s", path, root), err)
+	}
+
+	page, err := ParsePage(rel, source)
+	if err != nil {
+		return nil, internal.NewValidationErrorWithSuggestions(
+			fmt.Sprintf("%s: %v", path, err),
+			[]string{"Check the frontmatter syntax at the top of the file"},
+		)
+	}
+
+	page.Path = path
+	if page.LastMod.IsZero() {
+		page.LastMod = info.ModTime()
+	}
+	return page, nil
+}
+
+// ParsePage builds a page from its path relative to the source directory and
+// its content. HTML pages are not split since Caddy serves them as-is.
+func ParsePage(rel string, source []byte) (*Page, error) {
+	page := &Page{
+		Path:    rel,
+		RelPath: rel,
+		Kind:    KindHTML,
+		Meta:    make(map[string]interface{}),
+		Body:    string(source),
+	}
+
+	if !IsMarkdown(rel) {
+		page.URL = URLPath(rel)
+		return page, nil
+	}
+
+	meta, format, body, err := SplitFrontMatter(string(source))
+	if err != nil {
+		return nil, err
+	}
+
+	page.Kind = KindMarkdown
+	page.Format = format
+	page.Meta = meta
+	page.Body = body
+	page.URL = URLPath(page.OutputPath())
+
+	if err := page.applyMeta(); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// applyMeta fills the typed fields from the front matter
+func (p *Page) applyMeta() error {
+	p.Title = metaString(p.Meta, "title")
+	p.Description = metaString(p.Meta, "description")
+	p.Author = metaString(p.Meta, "author")
+	p.Category = metaString(p.Meta, "category")
+	p.Tags = metaStrings(p.Meta, "tags")
+
+	draft, err := metaBool(p.Meta, "draft")
+	if err != nil {
+		return err
+	}
+	p.Draft = draft
+
+	if p.Date, err = metaTime(p.Meta, "date"); err != nil {
+		return err
+	}
+	for _, key := range []string{"lastmod", "updated"} {
+		lastMod, err := metaTime(p.Meta, key)
+		if err != nil {
+			return err
+		}
+		if !lastMod.IsZero() {
+			p.LastMod = lastMod
+			break
+		}
+	}
+	return nil
+}
+
+// OutputPath returns where the rendered page is written, relative to the
+// output directory: "about.md" becomes "about/index.html" and "index.md"
+// stays "index.html" so pages keep the URLs Caddy serves them on
+func (p *Page) OutputPath() string {
+	if p.Kind != KindMarkdown {
+		return p.RelPath
+	}
+	dir, name := filepath.Split(p.RelPath)
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	if strings.EqualFold(base, "index") {
+		return filepath.Join(dir, "index.html")
+	}
+	return filepath.Join(dir, base, "index.html")
+}
+
+// Section returns the first directory of the page path, or "" for pages at
+// the root of the site
+func (p *Page) Section() string {
+	parts := strings.SplitN(filepath.ToSlash(p.RelPath), "/", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+// IsPage reports whether a file is a page (markdown or HTML)
+func IsPage(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+// IsMarkdown reports whether a file is a markdown page
+func IsMarkdown(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".md")
+}
+
+// Hidden reports whether a file or directory name is excluded from the
+// site: templates and partials ("_" prefix) and dotfiles
+func Hidden(name string) bool {
+	return strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".")
+}
+
+// URLPath returns the URL an output file is served on
+func URLPath(rel string) string {
+	path := "/" + filepath.ToSlash(rel)
+	if strings.HasSuffix(path, "/index.html") {
+		return strings.TrimSuffix(path, "index.html")
+	}
+	return path
+}
+
+// ParseDate parses a date in one of the accepted front matter formats
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse date %q (use YYYY-MM-DD or RFC 3339)", value)
+}
+
+// metaString returns a front matter value as a string
+func metaString(meta map[string]interface{}, key string) string {
+	switch v := meta[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// metaStrings returns a list front matter value; a single string is treated
+// as a one-element list
+func metaStrings(meta map[string]interface{}, key string) []string {
+	switch v := meta[key].(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	}
+	return nil
+}
+
+// metaBool returns a boolean front matter value
+func metaBool(meta map[string]interface{}, key string) (bool, error) {
+	switch v := meta[key].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes":
+			return true, nil
+		case "false", "no", "":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%s must be true or false, got %v", key, meta[key])
+}
+
+// metaTime returns a date front matter value
+func metaTime(meta map[string]interface{}, key string) (time.Time, error) {
+	switch v := meta[key].(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case string:
+		if v == "" {
+			return time.Time{}, nil
+		}
+		t, err := ParseDate(v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		return t, nil
+	}
+	// TOML local dates decode to their own types; fall back to their string form
+	t, err := ParseDate(fmt.Sprint(meta[key]))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,286 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format Format
+		title  interface{}
+		body   string
+	}{
+		{"yaml", "---\ntitle: YAML\n---\nbody", FormatYAML, "YAML", "body"},
+		{"toml", "+++\ntitle = \"TOML\"\n+++\n\nbody", FormatTOML, "TOML", "body"},
+		{"json", "{\n\"title\": \"JSON\"\n}\nbody", FormatJSON, "JSON", "body"},
+		{"crlf", "---\r\ntitle: CRLF\r\n---\r\nbody", FormatYAML, "CRLF", "body"},
+		{"byte order mark", "\ufeff---\ntitle: BOM\n---\nbody", FormatYAML, "BOM", "body"},
+		{"none", "# Heading", FormatNone, nil, "# Heading"},
+		{"empty yaml", "---\n---\nbody", FormatYAML, nil, "body"},
+		{"horizontal rule in body", "---\ntitle: Rule\n---\nabove\n---\nbelow", FormatYAML, "Rule", "above\n---\nbelow"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			meta, format, body, err := SplitFrontMatter(test.input)
+			if err != nil {
+				t.Fatalf("SplitFrontMatter returned error: %v", err)
+			}
+			if format != test.format {
+				t.Errorf("Expected format %q, got %q", test.format, format)
+			}
+			if meta["title"] != test.title {
+				t.Errorf("Expected title %v, got %v", test.title, meta["title"])
+			}
+			if body != test.body {
+				t.Errorf("Expected body %q, got %q", test.body, body)
+			}
+		})
+	}
+}
+
+func TestSplitFrontMatterErrors(t *testing.T) {
+	inputs := map[string]string{
+		"unterminated yaml": "---\ntitle: x\n",
+		"unterminated json": "{\n\"title\": \"x\"",
+		"invalid yaml":      "---\ntitle: [\n---\n",
+		"invalid toml":      "+++\ntitle = \n+++\n",
+		"invalid json":      "{\n\"title\": x\n}\n",
+	}
+	for name, input := range inputs {
+		if _, _, _, err := SplitFrontMatter(input); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	source := `---
+title: First Post
+description: An introduction
+author: Jane
+category: news
+tags: [go, garp]
+draft: true
+date: 2024-03-05
+lastmod: 2024-04-01T10:00:00Z
+---
+Hello`
+
+	page, err := ParsePage(filepath.Join("blog", "first-post.md"), []byte(source))
+	if err != nil {
+		t.Fatalf("ParsePage returned error: %v", err)
+	}
+
+	if page.Kind != KindMarkdown || page.Format != FormatYAML {
+		t.Errorf("Unexpected kind/format: %s/%s", page.Kind, page.Format)
+	}
+	if page.Title != "First Post" || page.Description != "An introduction" || page.Author != "Jane" || page.Category != "news" {
+		t.Errorf("Unexpected metadata: %+v", page)
+	}
+	if len(page.Tags) != 2 || page.Tags[0] != "go" {
+		t.Errorf("Unexpected tags: %v", page.Tags)
+	}
+	if !page.Draft {
+		t.Error("Expected page to be a draft")
+	}
+	if !page.Date.Equal(time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date: %v", page.Date)
+	}
+	if !page.LastMod.Equal(time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected lastmod: %v", page.LastMod)
+	}
+	if page.URL != "/blog/first-post/" {
+		t.Errorf("Expected URL /blog/first-post/, got %s", page.URL)
+	}
+	if page.OutputPath() != filepath.Join("blog", "first-post", "index.html") {
+		t.Errorf("Unexpected output path: %s", page.OutputPath())
+	}
+	if page.Section() != "blog" {
+		t.Errorf("Expected section blog, got %q", page.Section())
+	}
+	if page.Body != "Hello" {
+		t.Errorf("Unexpected body: %q", page.Body)
+	}
+}
+
+func TestParsePageTOMLDate(t *testing.T) {
+	page, err := ParsePage("post.md", []byte("+++\ndate = 2024-03-05\ndraft = false\n+++\n"))
+	if err != nil {
+		t.Fatalf("ParsePage returned error: %v", err)
+	}
+	if page.Date.Format("2006-01-02") != "2024-03-05" {
+		t.Errorf("Unexpected date: %v", page.Date)
+	}
+}
+
+func TestParsePageInvalidMeta(t *testing.T) {
+	inputs := map[string]string{
+		"bad date":  "---\ndate: next tuesday\n---\n",
+		"bad draft": "---\ndraft: maybe\n---\n",
+	}
+	for name, input := range inputs {
+		if _, err := ParsePage("page.md", []byte(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestOutputPathAndURL(t *testing.T) {
+	tests := []struct {
+		rel    string
+		output string
+		url    string
+	}{
+		{"index.md", "index.html", "/"},
+		{"about.md", filepath.Join("about", "index.html"), "/about/"},
+		{"blog/index.md", filepath.Join("blog", "index.html"), "/blog/"},
+		{"contact.html", "contact.html", "/contact.html"},
+		{"docs/index.html", filepath.Join("docs", "index.html"), "/docs/"},
+	}
+
+	for _, test := range tests {
+		page, err := ParsePage(filepath.FromSlash(test.rel), nil)
+		if err != nil {
+			t.Fatalf("ParsePage(%s) returned error: %v", test.rel, err)
+		}
+		if page.OutputPath() != test.output {
+			t.Errorf("%s: output path = %s, want %s", test.rel, page.OutputPath(), test.output)
+		}
+		if page.URL != test.url {
+			t.Errorf("%s: URL = %s, want %s", test.rel, page.URL, test.url)
+		}
+	}
+}
+
+func TestLoadPages(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"index.md":           "---\ntitle: Home\n---\n",
+		"about.html":         "<h1>About</h1>",
+		"blog/post.md":       "+++\ntitle = \"Post\"\n+++\n",
+		"_template.html":     "[[.Body]]",
+		"_partials/nav.html": "<nav></nav>",
+		".hidden/page.md":    "hidden",
+		"css/style.css":      "body{}",
+	}
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pages, err := LoadPages(root)
+	if err != nil {
+		t.Fatalf("LoadPages returned error: %v", err)
+	}
+
+	expected := []string{"about.html", filepath.Join("blog", "post.md"), "index.md"}
+	if len(pages) != len(expected) {
+		t.Fatalf("Expected %d pages, got %d", len(expected), len(pages))
+	}
+	for i, page := range pages {
+		if page.RelPath != expected[i] {
+			t.Errorf("Page %d: expected %s, got %s", i, expected[i], page.RelPath)
+		}
+		if page.LastMod.IsZero() {
+			t.Errorf("%s: expected LastMod to default to the file time", page.RelPath)
+		}
+	}
+	if pages[1].Title != "Post" {
+		t.Errorf("Expected TOML title to be parsed, got %q", pages[1].Title)
+	}
+}
+
+func TestLoadPagesInvalidFrontMatter(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "broken.md"), []byte("---\ntitle: [\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPages(root); err == nil {
+		t.Error("Expected an error for invalid front matter")
+	}
+}
+
+func TestPublishOptions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	live := &Page{Date: now.AddDate(0, 0, -1)}
+	draft := &Page{Draft: true}
+	scheduled := &Page{Date: now.AddDate(0, 0, 1)}
+
+	if !live.Published(now) || draft.Published(now) || scheduled.Published(now) {
+		t.Error("Unexpected Published result")
+	}
+
+	tests := []struct {
+		options   PublishOptions
+		draft     bool
+		scheduled bool
+	}{
+		{PublishOptions{}, false, false},
+		{PublishOptions{Drafts: true}, true, false},
+		{PublishOptions{Future: true}, false, true},
+		{PublishOptions{Drafts: true, Future: true}, true, true},
+	}
+	for _, test := range tests {
+		if !test.options.Includes(live, now) {
+			t.Errorf("%+v: expected published page to be included", test.options)
+		}
+		if got := test.options.Includes(draft, now); got != test.draft {
+			t.Errorf("%+v: draft included = %v, want %v", test.options, got, test.draft)
+		}
+		if got := test.options.Includes(scheduled, now); got != test.scheduled {
+			t.Errorf("%+v: scheduled included = %v, want %v", test.options, got, test.scheduled)
+		}
+	}
+
+	if unpublished := Unpublished([]*Page{live, draft, scheduled}, now); len(unpublished) != 2 {
+		t.Errorf("Expected 2 unpublished pages, got %d", len(unpublished))
+	}
+}
+
+func TestFindPage(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"index.md", "about.md", "blog/index.md", "docs/index.html", "contact.html"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("page"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]string{
+		"/":             "index.md",
+		"/about":        "about.md",
+		"/about/":       "about.md",
+		"/blog/":        filepath.Join("blog", "index.md"),
+		"/docs":         filepath.Join("docs", "index.html"),
+		"/contact.html": "contact.html",
+		"/contact":      "contact.html",
+		"/missing/":     "",
+	}
+	for urlPath, expected := range tests {
+		page, err := FindPage(root, urlPath)
+		if err != nil {
+			t.Fatalf("FindPage(%s) returned error: %v", urlPath, err)
+		}
+		got := ""
+		if page != nil {
+			got = page.RelPath
+		}
+		if got != expected {
+			t.Errorf("FindPage(%s) = %q, want %q", urlPath, got, expected)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PublishOptions controls which unpublished pages are included in a build
+type PublishOptions struct {
+	Drafts bool // include pages with draft: true
+	Future bool // include pages dated in the future
+}
+
+// IsFuture reports whether the page is scheduled after now
+func (p *Page) IsFuture(now time.Time) bool {
+	return !p.Date.IsZero() && p.Date.After(now)
+}
+
+// Published reports whether the page is live: not a draft and not
+// scheduled for a later date
+func (p *Page) Published(now time.Time) bool {
+	return !p.Draft && !p.IsFuture(now)
+}
+
+// Includes reports whether a page is part of a build with these options
+func (o PublishOptions) Includes(p *Page, now time.Time) bool {
+	if p.Draft && !o.Drafts {
+		return false
+	}
+	if p.IsFuture(now) && !o.Future {
+		return false
+	}
+	return true
+}
+
+// Unpublished returns the pages that are drafts or scheduled for later
+func Unpublished(pages []*Page, now time.Time) []*Page {
+	var unpublished []*Page
+	for _, page := range pages {
+		if !page.Published(now) {
+			unpublished = append(unpublished, page)
+		}
+	}
+	return unpublished
+}
+
+// FindPage returns the page served at a URL path, resolving it the way the
+// Caddyfile does: "/about" and "/about/" match about.md, about/index.md,
+// about.html or about/index.html. It returns nil when no page matches.
+func FindPage(root, urlPath string) (*Page, error) {
+	clean := strings.Trim(filepath.FromSlash(filepath.Clean("/"+urlPath)), string(filepath.Separator))
+
+	var candidates []string
+	if clean == "" {
+		candidates = []string{"index.md", "index.html"}
+	} else {
+		if IsPage(clean) {
+			candidates = append(candidates, clean)
+		}
+		candidates = append(candidates,
+			clean+".md",
+			filepath.Join(clean, "index.md"),
+			clean+".html",
+			filepath.Join(clean, "index.html"),
+		)
+	}
+
+	for _, candidate := range candidates {
+		path := filepath.Join(root, candidate)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return LoadPage(root, path)
+		}
+	}
+	return nil, nil
+}
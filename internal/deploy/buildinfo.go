@@ -0,0 +1,155 @@
+package deploy
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// defaultExcludes are never deployed
+var defaultExcludes = []string{
+	".git/",
+	".DS_Store",
+	".env",
+	"*.log",
+}
+
+// BuildInfo describes the build that was deployed
+type BuildInfo struct {
+	BuildExecuted   bool   `json:"build_executed"`
+	GarpVersion     string `json:"garp_version,omitempty"`
+	TailwindVersion string `json:"tailwind_version,omitempty"`
+	PagefindVersion string `json:"pagefind_version,omitempty"`
+	FileCount       int    `json:"file_count"`
+	TotalSize       int64  `json:"total_size"`
+
+	// Manifest maps each deployed file, relative to the output directory
+	// and slash separated, to the SHA-256 of its content
+	Manifest map[string]string `json:"manifest,omitempty"`
+}
+
+// CollectBuildInfo records the tool versions of the deployment and the
+// manifest of the files that were deployed, building it when nil
+func CollectBuildInfo(config DeploymentConfig, manifest *Manifest) (*BuildInfo, error) {
+	info := &BuildInfo{
+		BuildExecuted: config.BuildFirst,
+		GarpVersion:   config.GarpVersion,
+	}
+
+	// Tool versions are only meaningful when garp ran the build
+	if config.BuildFirst {
+		if tailwind, err := internal.DetectTailwindCLI(); err == nil && tailwind.IsInstalled {
+			info.TailwindVersion = tailwind.Version
+		}
+		if !config.SkipSearch {
+			if pagefind, err := internal.DetectPagefind(); err == nil && pagefind.IsInstalled {
+				info.PagefindVersion = pagefind.Version
+			}
+		}
+	}
+
+	if manifest == nil {
+		var err error
+		if manifest, err = BuildOutputManifest(config); err != nil {
+			return info, err
+		}
+	}
+	info.Manifest = manifest.Files
+	info.FileCount = len(manifest.Files)
+	info.TotalSize = manifest.Size
+	return info, nil
+}
+
+// buildManifest hashes every file below dir that is not excluded and returns
+// the manifest and the total size of the files
+func buildManifest(dir string, excludes []string, newHash func() hash.Hash) (map[string]string, int64, error) {
+	manifest := make(map[string]string)
+	var size int64
+
+	err := filepath.Walk(dir, func(file string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if excluded(rel, fileInfo.IsDir(), excludes) {
+			if fileInfo.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fileInfo.Mode().IsRegular() {
+			return nil
+		}
+
+		sum, err := hashFile(file, newHash())
+		if err != nil {
+			return err
+		}
+		manifest[rel] = sum
+		size += fileInfo.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build deployment manifest: %v", err)
+	}
+
+	return manifest, size, nil
+}
+
+// excluded reports whether rel matches one of the rsync exclude patterns.
+// Patterns starting with / are anchored to the output directory, patterns
+// ending in / only match directories, and patterns without a slash match
+// the name of a file or directory at any depth.
+func excluded(rel string, isDir bool, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+
+		var matched bool
+		switch {
+		case strings.HasPrefix(pattern, "/"):
+			matched, _ = path.Match(pattern[1:], rel)
+		case !strings.Contains(pattern, "/"):
+			matched, _ = path.Match(pattern, path.Base(rel))
+		default:
+			matched, _ = path.Match(pattern, rel)
+			if !matched {
+				matched, _ = path.Match("*/"+pattern, rel)
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// hashFile returns the hex encoded digest of a file
+func hashFile(file string, digest hash.Hash) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(digest, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
@@ -0,0 +1,97 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{".git/", "*.log", "/drafts/post.html", "assets/*.map"}
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{".git", true, true},
+		{"docs/.git", true, true},
+		{".git", false, false},
+		{"debug.log", false, true},
+		{"nested/debug.log", false, true},
+		{"drafts/post.html", false, true},
+		{"blog/drafts/post.html", false, false},
+		{"assets/app.map", false, true},
+		{"static/assets/app.map", false, true},
+		{"index.html", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := excluded(tt.rel, tt.isDir, patterns); got != tt.want {
+			t.Errorf("excluded(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestBuildManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "index.html"), "hello")
+	writeFile(t, filepath.Join(dir, "blog", "post.html"), "post")
+	writeFile(t, filepath.Join(dir, "blog", "draft.html"), "draft")
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref")
+	writeFile(t, filepath.Join(dir, "build.log"), "log")
+
+	manifest, size, err := buildManifest(dir, append(append([]string{}, defaultExcludes...), "/blog/draft.html"), sha256.New)
+	if err != nil {
+		t.Fatalf("buildManifest failed: %v", err)
+	}
+
+	if len(manifest) != 2 {
+		t.Fatalf("manifest = %v, want index.html and blog/post.html", manifest)
+	}
+	// SHA-256 of "hello"
+	if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; manifest["index.html"] != want {
+		t.Errorf("index.html hash = %s, want %s", manifest["index.html"], want)
+	}
+	if _, ok := manifest["blog/post.html"]; !ok {
+		t.Errorf("manifest is missing blog/post.html: %v", manifest)
+	}
+	if size != int64(len("hello")+len("post")) {
+		t.Errorf("size = %d, want %d", size, len("hello")+len("post"))
+	}
+}
+
+func TestAddRecordStoresGitCommitAndBuildInfo(t *testing.T) {
+	gitRepo(t)
+	commit := commitSite(t, "first")
+
+	history, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := &BuildInfo{GarpVersion: "1.2.3", FileCount: 1, TotalSize: 5, Manifest: map[string]string{"index.html": "abc"}}
+	result := &DeploymentResult{Success: true, Strategy: GitStrategy, BuildExecuted: true, BuildInfo: info}
+	if err := history.AddRecord(result, DeploymentConfig{Strategy: GitStrategy, DeploymentID: "deploy_1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := reloaded.GetDeploymentByID("deploy_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.GitCommit != commit {
+		t.Errorf("GitCommit = %q, want %q", record.GitCommit, commit)
+	}
+
+	want := *info
+	want.BuildExecuted = true
+	if record.BuildInfo == nil || !reflect.DeepEqual(*record.BuildInfo, want) {
+		t.Errorf("BuildInfo = %+v, want %+v", record.BuildInfo, want)
+	}
+}
@@ -0,0 +1,207 @@
+package deploy
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// environmentKeys are the settings an environment accepts for each strategy
+var environmentKeys = map[DeploymentStrategy][]string{
+	GitStrategy:     {"remote", "branch"},
+	RsyncStrategy:   {"host", "user", "path", "excludes", "releases", "keep_releases"},
+	NetlifyStrategy: {"site_id", "api_url", "excludes"},
+	S3Strategy:      {"bucket", "prefix", "region", "endpoint", "excludes"},
+}
+
+// ParseEnvironmentConfig builds an environment from key=value settings and
+// validates them for the strategy. Repeated excludes are combined; excludes
+// may also be given as a comma-separated list.
+func ParseEnvironmentConfig(strategy string, values []string) (EnvironmentConfig, error) {
+	env := EnvironmentConfig{
+		Strategy: strategy,
+		Config:   make(map[string]string),
+	}
+
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return env, internal.NewValidationErrorWithSuggestions(
+				fmt.Sprintf("invalid setting %q", value),
+				[]string{"Use key=value, e.g. --config host=example.com"},
+			)
+		}
+		val = strings.TrimSpace(val)
+
+		if key == "excludes" && env.Config[key] != "" {
+			val = env.Config[key] + "," + val
+		} else if _, exists := env.Config[key]; exists {
+			return env, internal.NewValidationError(fmt.Sprintf("setting %s is given more than once", key))
+		}
+		env.Config[key] = val
+	}
+
+	return env, env.Validate()
+}
+
+// Validate checks that the strategy is known, that every setting applies to
+// it and that values have the right form
+func (ec EnvironmentConfig) Validate() error {
+	strategy, err := ParseStrategy(ec.Strategy)
+	if err != nil {
+		return internal.NewValidationErrorWithSuggestions(
+			fmt.Sprintf("invalid environment strategy: %v", err),
+			[]string{"Use --strategy git, rsync, netlify or s3"},
+		)
+	}
+	allowed := environmentKeys[strategy]
+
+	for _, key := range sortedKeys(ec.Config) {
+		value := ec.Config[key]
+		if !contains(allowed, key) {
+			return internal.NewValidationErrorWithSuggestions(
+				fmt.Sprintf("unknown %s setting: %s", ec.Strategy, key),
+				[]string{fmt.Sprintf("Valid settings for %s are: %s", ec.Strategy, strings.Join(allowed, ", "))},
+			)
+		}
+
+		switch key {
+		case "host", "user", "remote", "branch", "bucket", "region":
+			if value == "" || strings.ContainsAny(value, " \t") {
+				return internal.NewValidationError(fmt.Sprintf("%s must be a single non-empty word, got %q", key, value))
+			}
+		case "path", "site_id":
+			if value == "" {
+				return internal.NewValidationError(fmt.Sprintf("%s must not be empty", key))
+			}
+		case "api_url", "endpoint":
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return internal.NewValidationError(fmt.Sprintf("%s must be an http(s) URL, got %q", key, value))
+			}
+		case "releases":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return internal.NewValidationError(fmt.Sprintf("releases must be true or false, got %q", value))
+			}
+		case "keep_releases":
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return internal.NewValidationError(fmt.Sprintf("keep_releases must be a positive number, got %q", value))
+			}
+		}
+	}
+
+	if strategy == RsyncStrategy && ec.Config["path"] == "" {
+		return internal.NewValidationErrorWithSuggestions(
+			"rsync environments require a path",
+			[]string{"Add --config path=/var/www/site"},
+		)
+	}
+
+	if strategy == S3Strategy && ec.Config["bucket"] == "" {
+		return internal.NewValidationErrorWithSuggestions(
+			"s3 environments require a bucket",
+			[]string{"Add --config bucket=<bucket name>"},
+		)
+	}
+
+	if strategy == NetlifyStrategy && ec.Config["site_id"] == "" {
+		return internal.NewValidationErrorWithSuggestions(
+			"netlify environments require a site_id",
+			[]string{"Add --config site_id=<your site ID>"},
+		)
+	}
+
+	return nil
+}
+
+// Apply points the deployment configuration at the environment. The target
+// settings of the configuration are replaced by those of the environment;
+// other settings such as releases are kept unless the environment sets them.
+func (ec EnvironmentConfig) Apply(config *DeploymentConfig) error {
+	if err := ec.Validate(); err != nil {
+		return err
+	}
+
+	strategy, _ := ParseStrategy(ec.Strategy)
+	config.Strategy = strategy
+	config.Target = ec.Name
+	config.Environment = ec.Name
+	config.RsyncHost = ""
+	config.RsyncUser = ""
+	config.RsyncPath = ""
+	config.GitRemote = ""
+	config.GitBranch = ""
+	config.SiteID = ""
+	config.APIURL = ""
+	config.S3Bucket = ""
+	config.S3Prefix = ""
+	config.S3Region = ""
+	config.S3Endpoint = ""
+
+	for key, value := range ec.Config {
+		switch key {
+		case "host":
+			config.RsyncHost = value
+		case "user":
+			config.RsyncUser = value
+		case "path":
+			config.RsyncPath = value
+		case "remote":
+			config.GitRemote = value
+		case "branch":
+			config.GitBranch = value
+		case "excludes":
+			config.RsyncExcludes = append(config.RsyncExcludes, splitList(value)...)
+		case "releases":
+			config.Releases, _ = strconv.ParseBool(value)
+		case "keep_releases":
+			config.KeepReleases, _ = strconv.Atoi(value)
+		case "site_id":
+			config.SiteID = value
+		case "api_url":
+			config.APIURL = value
+		case "bucket":
+			config.S3Bucket = value
+		case "prefix":
+			config.S3Prefix = value
+		case "region":
+			config.S3Region = value
+		case "endpoint":
+			config.S3Endpoint = value
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
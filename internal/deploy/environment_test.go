@@ -0,0 +1,135 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mattsafaii/garp/internal/config"
+)
+
+func TestParseEnvironmentConfig(t *testing.T) {
+	env, err := ParseEnvironmentConfig("rsync", []string{
+		"host=example.com",
+		"user = deploy",
+		"path=/var/www/site",
+		"excludes=*.map,drafts/",
+		"excludes=/private",
+		"releases=true",
+	})
+	if err != nil {
+		t.Fatalf("ParseEnvironmentConfig failed: %v", err)
+	}
+
+	want := map[string]string{
+		"host":     "example.com",
+		"user":     "deploy",
+		"path":     "/var/www/site",
+		"excludes": "*.map,drafts/,/private",
+		"releases": "true",
+	}
+	if !reflect.DeepEqual(env.Config, want) {
+		t.Fatalf("Config = %v, want %v", env.Config, want)
+	}
+}
+
+func TestParseEnvironmentConfigErrors(t *testing.T) {
+	tests := []struct {
+		strategy string
+		values   []string
+		want     string
+	}{
+		{"ftp", []string{"host=example.com"}, "unknown deployment strategy"},
+		{"rsync", []string{"host"}, "invalid setting"},
+		{"rsync", []string{"path=/srv", "branch=main"}, "unknown rsync setting: branch"},
+		{"git", []string{"host=example.com"}, "unknown git setting: host"},
+		{"rsync", []string{"host=example.com"}, "require a path"},
+		{"rsync", []string{"path=/srv", "host=a", "host=b"}, "more than once"},
+		{"rsync", []string{"path=/srv", "keep_releases=0"}, "keep_releases"},
+		{"git", []string{"branch=my branch"}, "single non-empty word"},
+		{"netlify", []string{"api_url=https://api.example.com"}, "require a site_id"},
+		{"netlify", []string{"site_id=abc", "api_url=ftp://example.com"}, "api_url"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseEnvironmentConfig(tt.strategy, tt.values)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseEnvironmentConfig(%s, %v) error = %v, want %q", tt.strategy, tt.values, err, tt.want)
+		}
+	}
+}
+
+func TestEnvironmentConfigApply(t *testing.T) {
+	config := DeploymentConfig{
+		Strategy:      GitStrategy,
+		GitRemote:     "origin",
+		RsyncHost:     "default.example.com",
+		RsyncExcludes: []string{"/draft.html"},
+		KeepReleases:  5,
+	}
+	env := EnvironmentConfig{
+		Name:     "production",
+		Strategy: "rsync",
+		Config:   map[string]string{"path": "/var/www/site", "excludes": "*.map", "keep_releases": "3"},
+	}
+
+	if err := env.Apply(&config); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if config.Strategy != RsyncStrategy || config.Target != "production" {
+		t.Errorf("strategy/target = %s/%s, want rsync/production", config.Strategy, config.Target)
+	}
+	if config.RsyncHost != "" || config.GitRemote != "" {
+		t.Errorf("settings of the default target leaked into the environment: %+v", config)
+	}
+	if config.RsyncPath != "/var/www/site" || config.KeepReleases != 3 {
+		t.Errorf("environment settings not applied: %+v", config)
+	}
+	if want := []string{"/draft.html", "*.map"}; !reflect.DeepEqual(config.RsyncExcludes, want) {
+		t.Errorf("excludes = %v, want %v", config.RsyncExcludes, want)
+	}
+}
+
+// Every environment setting can also be declared in garp.toml
+func TestEnvironmentKeysInConfigFile(t *testing.T) {
+	for strategy, keys := range environmentKeys {
+		var file strings.Builder
+		fmt.Fprintf(&file, "[deploy.environments.test]\nstrategy = %q\n", strategy.String())
+		for _, key := range keys {
+			switch key {
+			case "releases":
+				fmt.Fprintf(&file, "%s = true\n", key)
+			case "keep_releases":
+				fmt.Fprintf(&file, "%s = 3\n", key)
+			case "api_url", "endpoint":
+				fmt.Fprintf(&file, "%s = \"https://example.com\"\n", key)
+			case "excludes":
+				fmt.Fprintf(&file, "%s = [\"*.map\"]\n", key)
+			default:
+				fmt.Fprintf(&file, "%s = \"value\"\n", key)
+			}
+		}
+
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, config.FileName), []byte(file.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := config.LoadFile(dir)
+		if err != nil {
+			t.Errorf("%s settings are not accepted in garp.toml: %v", strategy, err)
+			continue
+		}
+
+		env := EnvironmentConfig{Strategy: strategy.String(), Config: cfg.Deploy.Environments["test"].Settings()}
+		if len(env.Config) != len(keys) {
+			t.Errorf("%s settings from garp.toml = %v, want %d keys", strategy, env.Config, len(keys))
+		}
+		if err := env.Validate(); err != nil {
+			t.Errorf("%s settings from garp.toml are invalid: %v", strategy, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package deploy
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -82,9 +83,11 @@ func (g *GitDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, error)
 			errMsg := fmt.Sprintf("failed to get current branch: %v", err)
 			result.Errors = append(result.Errors, errMsg)
 			result.Duration = time.Since(start)
-			return result, fmt.Errorf(errMsg)
+			return result, errors.New(errMsg)
 		}
 	}
+	result.GitRemote = remote
+	result.GitBranch = branch
 
 	if config.DryRun {
 		result.Messages = append(result.Messages, fmt.Sprintf("Would push to %s/%s", remote, branch))
@@ -108,7 +111,7 @@ func (g *GitDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, error)
 		errMsg := fmt.Sprintf("git push failed: %v\nOutput: %s", err, string(output))
 		result.Errors = append(result.Errors, errMsg)
 		result.Duration = time.Since(start)
-		return result, fmt.Errorf(errMsg)
+		return result, errors.New(errMsg)
 	}
 
 	result.Messages = append(result.Messages, fmt.Sprintf("Successfully pushed to %s/%s", remote, branch))
@@ -143,6 +146,40 @@ func gitRemoteExists(remote string) bool {
 	return cmd.Run() == nil
 }
 
+// gitOutput runs git and returns its trimmed output, including stderr in
+// the error when the command fails
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %v\nOutput: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// committedFiles returns the given files that are committed to branch, or to
+// the current branch when branch is empty
+func committedFiles(branch string, files []string) ([]string, error) {
+	if branch == "" {
+		branch = "HEAD"
+	}
+	args := append([]string{"ls-tree", "-r", "-z", "--name-only", "--full-name", branch, "--"}, files...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree %s: %v", branch, err)
+	}
+
+	var committed []string
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name != "" {
+			committed = append(committed, name)
+		}
+	}
+	return committed, nil
+}
+
 func getCurrentBranch() (string, error) {
 	cmd := exec.Command("git", "branch", "--show-current")
 	output, err := cmd.Output()
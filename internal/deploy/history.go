@@ -1,14 +1,21 @@
 package deploy
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"time"
 )
 
+// DefaultHistoryLimit is how many deployments are kept in the history when
+// no limit is configured
+const DefaultHistoryLimit = 50
+
 // DeploymentHistory tracks deployment records
 type DeploymentHistory struct {
 	filePath string
@@ -17,18 +24,23 @@ type DeploymentHistory struct {
 
 // DeploymentRecord represents a single deployment
 type DeploymentRecord struct {
-	ID        string                 `json:"id"`
-	Timestamp time.Time              `json:"timestamp"`
-	Strategy  string                 `json:"strategy"`
-	Target    string                 `json:"target,omitempty"`
-	Success   bool                   `json:"success"`
-	Duration  time.Duration          `json:"duration"`
-	URL       string                 `json:"url,omitempty"`
-	GitCommit string                 `json:"git_commit,omitempty"`
-	GitBranch string                 `json:"git_branch,omitempty"`
-	BuildInfo map[string]interface{} `json:"build_info,omitempty"`
-	Errors    []string               `json:"errors,omitempty"`
-	Messages  []string               `json:"messages,omitempty"`
+	ID          string        `json:"id"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Strategy    string        `json:"strategy"`
+	Target      string        `json:"target,omitempty"`
+	Environment string        `json:"environment,omitempty"`
+	Success     bool          `json:"success"`
+	Duration    time.Duration `json:"duration"`
+	URL         string        `json:"url,omitempty"`
+	GitCommit   string        `json:"git_commit,omitempty"`
+	GitRemote   string        `json:"git_remote,omitempty"`
+	GitBranch   string        `json:"git_branch,omitempty"`
+	BuildInfo   *BuildInfo    `json:"build_info,omitempty"`
+	Errors      []string      `json:"errors,omitempty"`
+	Messages    []string      `json:"messages,omitempty"`
+	Release     *ReleaseInfo  `json:"release,omitempty"`
+	RollbackOf  string        `json:"rollback_of,omitempty"` // deployment restored by a rollback
+	Hooks       []HookResult  `json:"hooks,omitempty"`
 }
 
 // NewDeploymentHistory creates or loads deployment history
@@ -58,36 +70,60 @@ func NewDeploymentHistory() (*DeploymentHistory, error) {
 
 // AddRecord adds a new deployment record
 func (h *DeploymentHistory) AddRecord(result *DeploymentResult, config DeploymentConfig) error {
+	id := config.DeploymentID
+	if id == "" {
+		id = generateDeploymentID()
+	}
+
 	record := DeploymentRecord{
-		ID:        generateDeploymentID(),
-		Timestamp: time.Now(),
-		Strategy:  result.Strategy.String(),
-		Target:    config.Target,
-		Success:   result.Success,
-		Duration:  result.Duration,
-		URL:       result.URL,
-		Errors:    result.Errors,
-		Messages:  result.Messages,
+		ID:          id,
+		Timestamp:   time.Now(),
+		Strategy:    result.Strategy.String(),
+		Target:      config.Target,
+		Environment: config.Environment,
+		Success:     result.Success,
+		Duration:    result.Duration,
+		URL:         result.URL,
+		Errors:      result.Errors,
+		Messages:    result.Messages,
+		Release:     result.Release,
+		RollbackOf:  config.RollbackOf,
+		Hooks:       result.Hooks,
+		GitRemote:   result.GitRemote,
 	}
 
 	// Add Git information if available
-	if gitCommit, err := getCurrentGitCommit(); err == nil {
+	if result.GitCommit != "" {
+		record.GitCommit = result.GitCommit
+	} else if gitCommit, err := getCurrentGitCommit(); err == nil {
 		record.GitCommit = gitCommit
 	}
-	if gitBranch, err := getCurrentBranch(); err == nil {
+	if result.GitBranch != "" {
+		record.GitBranch = result.GitBranch
+	} else if gitBranch, err := getCurrentBranch(); err == nil {
 		record.GitBranch = gitBranch
 	}
 
 	// Add build information
-	record.BuildInfo = map[string]interface{}{
-		"build_executed": result.BuildExecuted,
+	record.BuildInfo = result.BuildInfo
+	if record.BuildInfo == nil {
+		record.BuildInfo = &BuildInfo{}
 	}
+	record.BuildInfo.BuildExecuted = result.BuildExecuted
 
+	// Pick up records written by other garp processes since loading
+	if err := h.load(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reload deployment history: %v", err)
+	}
 	h.records = append(h.records, record)
 
-	// Keep only last 50 deployments
-	if len(h.records) > 50 {
-		h.records = h.records[len(h.records)-50:]
+	// Keep only the most recent deployments
+	limit := config.HistoryLimit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if len(h.records) > limit {
+		h.records = h.records[len(h.records)-limit:]
 	}
 
 	return h.save()
@@ -111,26 +147,140 @@ func (h *DeploymentHistory) GetLatestDeployment() (*DeploymentRecord, error) {
 
 // GetRecentDeployments returns recent deployments (up to limit)
 func (h *DeploymentHistory) GetRecentDeployments(limit int) []DeploymentRecord {
+	return h.Query(HistoryFilter{Limit: limit})
+}
+
+// HistoryFilter selects deployments from the history
+type HistoryFilter struct {
+	Environment string    // deployments to this named environment
+	Strategy    string    // deployments using this strategy
+	FailedOnly  bool      // only failed deployments
+	Since       time.Time // deployments at or after this time
+	Limit       int       // maximum number of deployments, all when zero
+}
+
+// Query returns the deployments matching filter, newest first
+func (h *DeploymentHistory) Query(filter HistoryFilter) []DeploymentRecord {
 	// Sort by timestamp descending
 	sort.Slice(h.records, func(i, j int) bool {
 		return h.records[i].Timestamp.After(h.records[j].Timestamp)
 	})
 
-	if limit > len(h.records) {
-		limit = len(h.records)
+	matches := []DeploymentRecord{}
+	for _, record := range h.records {
+		if filter.Limit > 0 && len(matches) == filter.Limit {
+			break
+		}
+		if filter.Environment != "" && record.EnvironmentName() != filter.Environment {
+			continue
+		}
+		if filter.Strategy != "" && record.Strategy != filter.Strategy {
+			continue
+		}
+		if filter.FailedOnly && record.Success {
+			continue
+		}
+		if record.Timestamp.Before(filter.Since) {
+			continue
+		}
+		matches = append(matches, record)
 	}
+	return matches
+}
 
-	return h.records[:limit]
+// EnvironmentName returns the named environment the deployment went to, or
+// an empty string for deployments to the default target
+func (r DeploymentRecord) EnvironmentName() string {
+	if r.Environment != "" {
+		return r.Environment
+	}
+	// Older records only kept the environment name as target
+	if r.Target != r.Strategy {
+		return r.Target
+	}
+	return ""
 }
 
-// GetDeploymentByID finds a deployment by ID
+// GetDeploymentByID finds a deployment by ID. Histories written before IDs
+// were unique may contain an ID twice, in which case the newest deployment
+// is returned.
 func (h *DeploymentHistory) GetDeploymentByID(id string) (*DeploymentRecord, error) {
+	var found *DeploymentRecord
+	for i, record := range h.records {
+		if record.ID == id && (found == nil || record.Timestamp.After(found.Timestamp)) {
+			found = &h.records[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("deployment with ID %s not found", id)
+	}
+	record := *found
+	return &record, nil
+}
+
+// relativeSince matches durations such as 30m, 12h, 7d or 2w
+var relativeSince = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+// ParseSince parses the start of a history query relative to now. It accepts
+// a duration in minutes, hours, days or weeks (e.g. 7d), a date (2006-01-02)
+// or an RFC 3339 timestamp.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if match := relativeSince.FindStringSubmatch(value); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		unit := map[string]time.Duration{
+			"m": time.Minute,
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}[match[2]]
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use a duration such as 24h or 7d, a date (2006-01-02) or an RFC 3339 timestamp", value)
+}
+
+// GetRollbackRelease returns the newest successful release deployment to the
+// same target as current that is not the live release
+func (h *DeploymentHistory) GetRollbackRelease(current ReleaseInfo) (*DeploymentRecord, error) {
+	sort.Slice(h.records, func(i, j int) bool {
+		return h.records[i].Timestamp.After(h.records[j].Timestamp)
+	})
+
 	for _, record := range h.records {
-		if record.ID == id {
+		release := record.Release
+		if !record.Success || release == nil || release.Name == current.Name {
+			continue
+		}
+		if release.Host == current.Host && release.Path == current.Path {
 			return &record, nil
 		}
 	}
-	return nil, fmt.Errorf("deployment with ID %s not found", id)
+
+	return nil, fmt.Errorf("no earlier release found for %s", current.Target())
+}
+
+// GetRollbackCommit returns the newest successful git deployment made before
+// current that deployed a different commit
+func (h *DeploymentHistory) GetRollbackCommit(current *DeploymentRecord) (*DeploymentRecord, error) {
+	sort.Slice(h.records, func(i, j int) bool {
+		return h.records[i].Timestamp.After(h.records[j].Timestamp)
+	})
+
+	for _, record := range h.records {
+		if !record.Success || record.Strategy != GitStrategy.String() || record.GitCommit == "" {
+			continue
+		}
+		if record.Timestamp.Before(current.Timestamp) && record.GitCommit != current.GitCommit {
+			return &record, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no earlier git deployment with a different commit found")
 }
 
 // load reads the history from file
@@ -150,19 +300,21 @@ func (h *DeploymentHistory) save() error {
 		return err
 	}
 
-	return os.WriteFile(h.filePath, data, 0644)
+	return writeFileAtomic(h.filePath, data, 0644)
 }
 
 // Helper functions
 
+// generateDeploymentID returns a unique ID that sorts by deployment time,
+// e.g. deploy_20250730-142501.123_9f86d0
 func generateDeploymentID() string {
-	return fmt.Sprintf("deploy_%d", time.Now().Unix())
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return fmt.Sprintf("deploy_%s_%x", time.Now().UTC().Format("20060102-150405.000"), suffix)
 }
 
 func getCurrentGitCommit() (string, error) {
-	// This is a simplified implementation
-	// In production, you would use git commands or a Git library
-	return "unknown", fmt.Errorf("git commit detection not implemented")
+	return gitOutput("rev-parse", "--verify", "HEAD")
 }
 
 // Configuration management
@@ -252,5 +404,5 @@ func (cm *ConfigManager) save() error {
 		return err
 	}
 
-	return os.WriteFile(cm.configPath, data, 0644)
+	return writeFileAtomic(cm.configPath, data, 0644)
 }
@@ -0,0 +1,139 @@
+package deploy
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestGenerateDeploymentIDIsUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := generateDeploymentID()
+		if seen[id] {
+			t.Fatalf("duplicate deployment ID %s", id)
+		}
+		seen[id] = true
+	}
+
+	// IDs sort by time, also after IDs in the old deploy_<unix seconds> format
+	older := generateDeploymentID()
+	time.Sleep(2 * time.Millisecond)
+	newer := generateDeploymentID()
+	ids := []string{newer, older, "deploy_1753885501"}
+	sort.Strings(ids)
+	if want := []string{"deploy_1753885501", older, newer}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDs sort as %v, want %v", ids, want)
+	}
+}
+
+// testHistory returns an empty history stored in a temporary directory
+func testHistory(t *testing.T) *DeploymentHistory {
+	t.Helper()
+	return &DeploymentHistory{filePath: filepath.Join(t.TempDir(), "history.json")}
+}
+
+func TestAddRecordHistoryLimit(t *testing.T) {
+	history := testHistory(t)
+
+	for i := 0; i < 5; i++ {
+		config := DeploymentConfig{DeploymentID: generateDeploymentID(), HistoryLimit: 3}
+		if err := history.AddRecord(&DeploymentResult{Success: true, Strategy: GitStrategy}, config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(history.Query(HistoryFilter{})); got != 3 {
+		t.Fatalf("kept %d records, want 3", got)
+	}
+
+	// Without a configured limit the default applies
+	for i := 0; i < DefaultHistoryLimit; i++ {
+		if err := history.AddRecord(&DeploymentResult{Success: true, Strategy: GitStrategy}, DeploymentConfig{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(history.Query(HistoryFilter{})); got != DefaultHistoryLimit {
+		t.Fatalf("kept %d records, want %d", got, DefaultHistoryLimit)
+	}
+}
+
+func TestHistoryQuery(t *testing.T) {
+	now := time.Now()
+	history := testHistory(t)
+	history.records = []DeploymentRecord{
+		{ID: "a", Timestamp: now.Add(-72 * time.Hour), Strategy: "git", Target: "git", Success: true},
+		{ID: "b", Timestamp: now.Add(-48 * time.Hour), Strategy: "rsync", Environment: "production", Success: false},
+		{ID: "c", Timestamp: now.Add(-2 * time.Hour), Strategy: "rsync", Environment: "production", Success: true},
+		{ID: "d", Timestamp: now.Add(-1 * time.Hour), Strategy: "rsync", Target: "staging", Success: false},
+	}
+
+	ids := func(records []DeploymentRecord) []string {
+		result := []string{}
+		for _, record := range records {
+			result = append(result, record.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{"all", HistoryFilter{}, []string{"d", "c", "b", "a"}},
+		{"limit", HistoryFilter{Limit: 2}, []string{"d", "c"}},
+		{"environment", HistoryFilter{Environment: "production"}, []string{"c", "b"}},
+		{"legacy environment", HistoryFilter{Environment: "staging"}, []string{"d"}},
+		{"strategy", HistoryFilter{Strategy: "git"}, []string{"a"}},
+		{"failed", HistoryFilter{FailedOnly: true}, []string{"d", "b"}},
+		{"since", HistoryFilter{Since: now.Add(-24 * time.Hour)}, []string{"d", "c"}},
+		{"combined", HistoryFilter{Environment: "production", FailedOnly: true, Limit: 1}, []string{"b"}},
+	}
+	for _, tt := range tests {
+		if got := ids(history.Query(tt.filter)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetDeploymentByIDPrefersNewestDuplicate(t *testing.T) {
+	now := time.Now()
+	history := testHistory(t)
+	history.records = []DeploymentRecord{
+		{ID: "deploy_1753885501", Timestamp: now, Strategy: "rsync"},
+		{ID: "deploy_1753885501", Timestamp: now.Add(time.Millisecond), Strategy: "git"},
+	}
+
+	record, err := history.GetDeploymentByID("deploy_1753885501")
+	if err != nil || record.Strategy != "git" {
+		t.Fatalf("got %+v (%v), want the newest record", record, err)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 7, 30, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"30m", now.Add(-30 * time.Minute)},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"2025-07-01", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"2025-07-01T08:00:00Z", time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v (%v), want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseSince("yesterday", now); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
@@ -0,0 +1,200 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// HookStage is a point in a deployment where hook commands run
+type HookStage string
+
+const (
+	// PreBuildHook runs before the pre-deployment build
+	PreBuildHook HookStage = "pre-build"
+	// PreDeployHook runs after the build and validation, before deploying
+	PreDeployHook HookStage = "pre-deploy"
+	// PostDeployHook runs after a successful deployment
+	PostDeployHook HookStage = "post-deploy"
+	// FailureHook runs when the deployment fails at any stage
+	FailureHook HookStage = "on-failure"
+)
+
+// Hooks maps each stage to the shell commands run at that stage
+type Hooks map[HookStage][]string
+
+// HookTimeout is how long a single hook command may run
+var HookTimeout = 10 * time.Minute
+
+// maxHookOutput is how much of the output of a hook is kept in the history
+const maxHookOutput = 16 * 1024
+
+// HookResult records a hook command that was run
+type HookResult struct {
+	Stage    HookStage     `json:"stage"`
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Success reports whether the hook command exited cleanly
+func (h HookResult) Success() bool {
+	return h.ExitCode == 0 && h.Error == ""
+}
+
+// hookRunner runs the hooks of one deployment and collects their results
+type hookRunner struct {
+	config  DeploymentConfig
+	commit  string
+	results []HookResult
+}
+
+func newHookRunner(config DeploymentConfig) *hookRunner {
+	return &hookRunner{config: config}
+}
+
+// run executes the commands of a stage. Pre-build and pre-deploy hooks stop
+// at the first failing command and return its error; the commands of the
+// other stages all run and their failures are only recorded.
+func (h *hookRunner) run(stage HookStage, result *DeploymentResult, deployErr error) error {
+	commands := h.config.Hooks[stage]
+	if len(commands) == 0 {
+		return nil
+	}
+
+	if h.config.DryRun {
+		for _, command := range commands {
+			fmt.Printf("🪝 Would run %s hook: %s\n", stage, command)
+		}
+		return nil
+	}
+
+	env := h.environment(stage, result, deployErr)
+	for _, command := range commands {
+		fmt.Printf("🪝 Running %s hook: %s\n", stage, command)
+		hook := runHook(stage, command, env, h.config.Verbose)
+		h.results = append(h.results, hook)
+		if hook.Success() {
+			continue
+		}
+
+		err := fmt.Errorf("%s hook '%s' failed: %s", stage, command, hook.Error)
+		if stage == PreBuildHook || stage == PreDeployHook {
+			return err
+		}
+		if result != nil {
+			result.Messages = append(result.Messages, fmt.Sprintf("⚠️  %v", err))
+		}
+	}
+	return nil
+}
+
+// environment returns the process environment of hooks with the deployment
+// metadata added as GARP_* variables
+func (h *hookRunner) environment(stage HookStage, result *DeploymentResult, deployErr error) []string {
+	config := h.config
+
+	commit := ""
+	if result != nil && result.GitCommit != "" {
+		commit = result.GitCommit
+	} else {
+		if h.commit == "" {
+			h.commit, _ = getCurrentGitCommit()
+		}
+		commit = h.commit
+	}
+
+	url := ""
+	if result != nil {
+		url = result.URL
+	}
+
+	errMsg := ""
+	if deployErr != nil {
+		errMsg = deployErr.Error()
+	} else if result != nil && len(result.Errors) > 0 {
+		errMsg = strings.Join(result.Errors, "; ")
+	}
+
+	return append(os.Environ(),
+		"GARP_HOOK_STAGE="+string(stage),
+		"GARP_DEPLOY_ID="+config.DeploymentID,
+		"GARP_DEPLOY_STRATEGY="+config.Strategy.String(),
+		"GARP_DEPLOY_TARGET="+ManifestTarget(config),
+		"GARP_DEPLOY_ENVIRONMENT="+config.Environment,
+		"GARP_DEPLOY_URL="+url,
+		"GARP_DEPLOY_COMMIT="+commit,
+		"GARP_DEPLOY_ERROR="+errMsg,
+		"GARP_OUTPUT_DIR="+internal.GetProjectLayout().OutputDir,
+	)
+}
+
+// runHook runs a single hook command through the shell
+func runHook(stage HookStage, command string, env []string, verbose bool) HookResult {
+	hook := HookResult{Stage: stage, Command: command}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), HookTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = env
+
+	var output bytes.Buffer
+	if verbose {
+		cmd.Stdout = io.MultiWriter(&output, os.Stdout)
+		cmd.Stderr = io.MultiWriter(&output, os.Stderr)
+	} else {
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+	}
+
+	err := cmd.Run()
+	hook.Duration = time.Since(start)
+	hook.Output = tail(output.String(), maxHookOutput)
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		hook.ExitCode = -1
+		hook.Error = fmt.Sprintf("timed out after %v", HookTimeout)
+	case err != nil:
+		hook.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			hook.ExitCode = exitErr.ExitCode()
+		}
+		hook.Error = err.Error()
+	}
+
+	if !hook.Success() && !verbose && hook.Output != "" {
+		fmt.Print(hook.Output)
+		if !strings.HasSuffix(hook.Output, "\n") {
+			fmt.Println()
+		}
+	}
+
+	return hook
+}
+
+// tail returns the last max bytes of s
+func tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return "... (" + strconv.Itoa(len(s)-max) + " bytes truncated)\n" + s[len(s)-max:]
+}
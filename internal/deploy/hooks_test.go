@@ -0,0 +1,152 @@
+package deploy
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// stubDeployer records whether it was asked to deploy
+type stubDeployer struct {
+	err      error
+	deployed bool
+}
+
+func (s *stubDeployer) Name() string                           { return "Stub" }
+func (s *stubDeployer) Validate(config DeploymentConfig) error { return nil }
+
+func (s *stubDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, error) {
+	s.deployed = true
+	if s.err != nil {
+		return &DeploymentResult{Strategy: config.Strategy, Errors: []string{s.err.Error()}}, s.err
+	}
+	return &DeploymentResult{Success: true, Strategy: config.Strategy, URL: "https://example.com"}, nil
+}
+
+// hookProject creates a project with a single page in a temporary directory
+// and returns a manager deploying it with deployer
+func hookProject(t *testing.T, deployer Deployer) *Manager {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+	writeFile(t, "public/index.html", "<html><head><title>Home</title></head><body></body></html>")
+
+	manager := NewManager()
+	manager.deployers[RsyncStrategy] = deployer
+	return manager
+}
+
+func hookConfig() DeploymentConfig {
+	return DeploymentConfig{
+		Strategy:         RsyncStrategy,
+		RsyncHost:        "example.com",
+		RsyncPath:        "/srv/site",
+		Environment:      "production",
+		SkipContentCheck: true,
+		DeploymentID:     "deploy_test",
+		Hooks: Hooks{
+			PreBuildHook:   {"echo pre-build >> hooks.log"},
+			PreDeployHook:  {`echo "pre-deploy $GARP_DEPLOY_ID $GARP_DEPLOY_ENVIRONMENT" >> hooks.log`},
+			PostDeployHook: {`echo "post-deploy $GARP_DEPLOY_URL" >> hooks.log`, "echo warming caches; exit 1"},
+			FailureHook:    {`echo "on-failure $GARP_DEPLOY_ERROR" >> hooks.log`},
+		},
+	}
+}
+
+func readHookLog(t *testing.T) string {
+	t.Helper()
+
+	data, err := os.ReadFile("hooks.log")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestDeployRunsHooks(t *testing.T) {
+	deployer := &stubDeployer{}
+	manager := hookProject(t, deployer)
+
+	result, err := manager.Deploy(hookConfig())
+	if err != nil || !result.Success {
+		t.Fatalf("deploy failed: %v", err)
+	}
+
+	// pre-build only runs when garp builds the site first
+	want := "pre-deploy deploy_test production\npost-deploy https://example.com\n"
+	if got := readHookLog(t); got != want {
+		t.Errorf("hooks ran as\n%s\nwant\n%s", got, want)
+	}
+
+	if len(result.Hooks) != 3 {
+		t.Fatalf("recorded %d hooks, want 3: %+v", len(result.Hooks), result.Hooks)
+	}
+	failed := result.Hooks[2]
+	if failed.Stage != PostDeployHook || failed.ExitCode != 1 || failed.Output != "warming caches\n" {
+		t.Errorf("unexpected result of the failing post-deploy hook: %+v", failed)
+	}
+	if !strings.Contains(strings.Join(result.Messages, "\n"), "post-deploy hook 'echo warming caches; exit 1' failed") {
+		t.Errorf("expected a warning about the failing hook, got %v", result.Messages)
+	}
+
+	history, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := history.GetDeploymentByID("deploy_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Hooks) != 3 || record.Hooks[0].Stage != PreDeployHook || !record.Hooks[0].Success() {
+		t.Errorf("hooks not recorded in history: %+v", record.Hooks)
+	}
+}
+
+func TestDeployFailingPreDeployHookAborts(t *testing.T) {
+	deployer := &stubDeployer{}
+	manager := hookProject(t, deployer)
+
+	config := hookConfig()
+	config.Hooks[PreDeployHook] = []string{"exit 3"}
+	result, err := manager.Deploy(config)
+	if err == nil || result.Success {
+		t.Fatal("expected the deployment to fail")
+	}
+	if deployer.deployed {
+		t.Error("the site must not be deployed after a failing pre-deploy hook")
+	}
+
+	if got, want := readHookLog(t), "on-failure pre-deploy hook 'exit 3' failed: exit status 3\n"; got != want {
+		t.Errorf("hooks ran as %q, want %q", got, want)
+	}
+	if len(result.Hooks) != 2 || result.Hooks[0].ExitCode != 3 {
+		t.Errorf("unexpected hook results: %+v", result.Hooks)
+	}
+}
+
+func TestDeployFailureRunsOnFailureHooks(t *testing.T) {
+	manager := hookProject(t, &stubDeployer{err: errors.New("rsync failed: exit status 23")})
+
+	if _, err := manager.Deploy(hookConfig()); err == nil {
+		t.Fatal("expected the deployment to fail")
+	}
+	want := "pre-deploy deploy_test production\non-failure rsync failed: exit status 23\n"
+	if got := readHookLog(t); got != want {
+		t.Errorf("hooks ran as\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDryRunSkipsHooks(t *testing.T) {
+	manager := hookProject(t, &stubDeployer{})
+
+	config := hookConfig()
+	config.DryRun = true
+	result, err := manager.Deploy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readHookLog(t); got != "" || len(result.Hooks) != 0 {
+		t.Errorf("a dry run must not run hooks, ran %q", got)
+	}
+}
@@ -0,0 +1,223 @@
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// LockFile is held while a deployment or rollback runs
+var LockFile = filepath.Join(".garp", "deploy.lock")
+
+// StaleLockAge is the age after which a lock whose owner cannot be checked
+// is considered abandoned
+const StaleLockAge = 2 * time.Hour
+
+// beforeTakeover runs when a stale or forced lock is about to be taken over,
+// so tests can line up concurrent takeovers
+var beforeTakeover = func() {}
+
+// LockInfo describes the process holding the deploy lock
+type LockInfo struct {
+	PID      int       `json:"pid"`
+	Host     string    `json:"host"`
+	Command  string    `json:"command"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// String describes the lock holder
+func (l LockInfo) String() string {
+	return fmt.Sprintf("'%s' (pid %d on %s, since %s)", l.Command, l.PID, l.Host, l.Acquired.Format("2006-01-02 15:04:05"))
+}
+
+// LockedError is returned when another deployment holds the lock
+type LockedError struct {
+	Holder LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("another deployment is in progress: %s; if it is no longer running, retry with --force-unlock", e.Holder)
+}
+
+// DeployLock is a held project-level deploy lock
+type DeployLock struct {
+	path string
+	info LockInfo
+}
+
+// AcquireLock takes the deploy lock for command. A lock left behind by a
+// process that is no longer running is taken over; a lock held by a running
+// deployment returns a *LockedError unless force is set.
+func AcquireLock(command string, force bool) (*DeployLock, error) {
+	if err := os.MkdirAll(filepath.Dir(LockFile), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %v", err)
+	}
+
+	host, _ := os.Hostname()
+	lock := &DeployLock{
+		path: LockFile,
+		info: LockInfo{PID: os.Getpid(), Host: host, Command: command, Acquired: time.Now()},
+	}
+	data, err := json.Marshal(lock.info)
+	if err != nil {
+		return nil, err
+	}
+
+	// The second attempt follows the removal of a stale or forced lock
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(lock.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, writeErr := file.Write(data)
+			closeErr := file.Close()
+			if writeErr != nil || closeErr != nil {
+				os.Remove(lock.path)
+				return nil, fmt.Errorf("failed to write deploy lock: %v", errors.Join(writeErr, closeErr))
+			}
+			return lock, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create deploy lock: %v", err)
+		}
+
+		holder, err := readLock(lock.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // released in the meantime
+			}
+			return nil, err
+		}
+		if !force && !holder.stale(host) {
+			return nil, &LockedError{Holder: holder}
+		}
+		beforeTakeover()
+		if err := removeLock(lock.path, holder); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("failed to acquire deploy lock: %s keeps being recreated", lock.path)
+}
+
+// Release gives up the lock unless another process has taken it over
+func (l *DeployLock) Release() error {
+	holder, err := readLock(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !holder.same(l.info) {
+		return nil
+	}
+	return os.Remove(l.path)
+}
+
+// removeLock removes the lock at path if it is still held by holder. The lock
+// is first moved aside, so that of several processes taking over the same
+// stale lock only one removes it, and a lock another process created in the
+// meantime is put back instead. A *LockedError reports such a lock.
+func removeLock(path string, holder LockInfo) error {
+	aside, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".stale-*")
+	if err != nil {
+		return fmt.Errorf("failed to remove deploy lock: %v", err)
+	}
+	aside.Close()
+	defer os.Remove(aside.Name())
+
+	if err := os.Rename(path, aside.Name()); err != nil {
+		if os.IsNotExist(err) {
+			return nil // removed or taken over in the meantime
+		}
+		return fmt.Errorf("failed to remove deploy lock: %v", err)
+	}
+
+	moved, err := readLock(aside.Name())
+	if err != nil {
+		return err
+	}
+	if moved.same(holder) {
+		return nil
+	}
+
+	// Link rather than rename the lock back, so a lock created since it was
+	// moved aside is not replaced
+	if err := os.Link(aside.Name(), path); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("failed to restore deploy lock: %v", err)
+	}
+	return &LockedError{Holder: moved}
+}
+
+// readLock returns the holder of the lock at path. A lock that cannot be
+// parsed, e.g. because its holder is still writing it, is reported with
+// its modification time so it can still age out.
+func readLock(path string) (LockInfo, error) {
+	var info LockInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		stat, statErr := os.Stat(path)
+		if statErr != nil {
+			return info, statErr
+		}
+		return LockInfo{Command: "unknown", Acquired: stat.ModTime()}, nil
+	}
+	return info, nil
+}
+
+// same reports whether l and other describe the same lock acquisition
+func (l LockInfo) same(other LockInfo) bool {
+	return l.PID == other.PID && l.Host == other.Host && l.Command == other.Command && l.Acquired.Equal(other.Acquired)
+}
+
+// stale reports whether the lock holder is gone. Processes on this host are
+// checked directly; other locks are considered stale after StaleLockAge.
+func (l LockInfo) stale(host string) bool {
+	if l.PID > 0 && l.Host == host {
+		return !processRunning(l.PID)
+	}
+	return time.Since(l.Acquired) > StaleLockAge
+}
+
+// processRunning reports whether a process with the pid exists. Where this
+// cannot be determined the process is assumed to be running.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
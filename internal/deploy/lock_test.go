@@ -0,0 +1,194 @@
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	lock, err := AcquireLock("garp deploy", false)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	// This process is alive, so its lock is not stale
+	_, err = AcquireLock("garp rollback", false)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Holder.PID != os.Getpid() || locked.Holder.Command != "garp deploy" {
+		t.Fatalf("expected the lock to be held by this process, got %v", err)
+	}
+
+	forced, err := AcquireLock("garp rollback", true)
+	if err != nil {
+		t.Fatalf("forced acquire failed: %v", err)
+	}
+
+	// The first lock was taken over, so releasing it keeps the new one
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(LockFile); err != nil {
+		t.Fatalf("releasing a taken over lock removed the new lock: %v", err)
+	}
+
+	if err := forced.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(LockFile); !os.IsNotExist(err) {
+		t.Fatalf("lock still exists after release: %v", err)
+	}
+}
+
+func TestAcquireLockTakesOverStaleLock(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// A process that has exited leaves a stale lock behind
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Skipf("cannot run a child process: %v", err)
+	}
+	host, _ := os.Hostname()
+	writeLock(t, LockInfo{PID: exited.Process.Pid, Host: host, Command: "garp deploy", Acquired: time.Now()})
+
+	lock, err := AcquireLock("garp deploy", false)
+	if err != nil {
+		t.Fatalf("expected the stale lock to be taken over, got %v", err)
+	}
+	lock.Release()
+
+	// Locks from other hosts age out
+	writeLock(t, LockInfo{PID: 1, Host: "ci-runner", Command: "garp deploy", Acquired: time.Now().Add(-StaleLockAge - time.Minute)})
+	lock, err = AcquireLock("garp deploy", false)
+	if err != nil {
+		t.Fatalf("expected the old lock to be taken over, got %v", err)
+	}
+	lock.Release()
+
+	writeLock(t, LockInfo{PID: 1, Host: "ci-runner", Command: "garp deploy", Acquired: time.Now()})
+	if _, err := AcquireLock("garp deploy", false); err == nil {
+		t.Fatal("expected a recent lock from another host to be respected")
+	}
+}
+
+func TestAcquireLockStaleTakeoverRace(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// Both deployments find the stale lock before either takes it over
+	var found sync.WaitGroup
+	beforeTakeover = func() {
+		found.Done()
+		found.Wait()
+	}
+	t.Cleanup(func() { beforeTakeover = func() {} })
+
+	for round := 0; round < 20; round++ {
+		writeLock(t, LockInfo{PID: 1, Host: "ci-runner", Command: "garp deploy", Acquired: time.Now().Add(-StaleLockAge - time.Minute)})
+
+		start := make(chan struct{})
+		locks := make([]*DeployLock, 2)
+		errs := make([]error, len(locks))
+		found.Add(len(locks))
+		var wg sync.WaitGroup
+		for i := range locks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				locks[i], errs[i] = AcquireLock(fmt.Sprintf("garp deploy %d", i), false)
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		var held *DeployLock
+		for i, lock := range locks {
+			var locked *LockedError
+			switch {
+			case lock == nil && !errors.As(errs[i], &locked):
+				t.Fatalf("round %d: unexpected error %v", round, errs[i])
+			case lock != nil && held != nil:
+				t.Fatalf("round %d: both deployments took over the stale lock", round)
+			case lock != nil:
+				held = lock
+			}
+		}
+		if held == nil {
+			t.Fatalf("round %d: neither deployment took over the stale lock: %v", round, errs)
+		}
+		if holder, err := readLock(LockFile); err != nil || !holder.same(held.info) {
+			t.Fatalf("round %d: lock file holds %+v (%v), want %+v", round, holder, err, held.info)
+		}
+		if err := held.Release(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nothing is left behind by the takeovers
+	entries, err := os.ReadDir(filepath.Dir(LockFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("unexpected files left in %s: %v", filepath.Dir(LockFile), entries)
+	}
+}
+
+func writeLock(t *testing.T, info LockInfo) {
+	t.Helper()
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, LockFile, string(data))
+}
+
+func TestAddRecordKeepsConcurrentRecords(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	first, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &DeploymentResult{Success: true, Strategy: RsyncStrategy}
+	if err := first.AddRecord(result, DeploymentConfig{DeploymentID: "deploy_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.AddRecord(result, DeploymentConfig{DeploymentID: "deploy_2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"deploy_1", "deploy_2"} {
+		if _, err := history.GetDeploymentByID(id); err != nil {
+			t.Errorf("record %s was lost: %v", id, err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(history.filePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "deployment-history.json" {
+			t.Errorf("unexpected file %s left in .garp", entry.Name())
+		}
+	}
+}
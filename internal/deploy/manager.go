@@ -3,7 +3,9 @@ package deploy
 import (
 	"fmt"
 	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/static"
 	"strings"
+	"time"
 )
 
 // Manager coordinates deployment operations
@@ -20,28 +22,77 @@ func NewManager() *Manager {
 	// Register available deployers
 	m.deployers[GitStrategy] = NewGitDeployer()
 	m.deployers[RsyncStrategy] = NewRsyncDeployer()
+	m.deployers[NetlifyStrategy] = NewNetlifyDeployer()
+	m.deployers[S3Strategy] = NewS3Deployer()
 
 	return m
 }
 
-// Deploy executes deployment with the specified configuration
+// Deploy executes deployment with the specified configuration, running the
+// configured hooks and recording the deployment in history
 func (m *Manager) Deploy(config DeploymentConfig) (*DeploymentResult, error) {
 	deployer, exists := m.deployers[config.Strategy]
 	if !exists {
 		return nil, fmt.Errorf("unsupported deployment strategy: %s", config.Strategy.String())
 	}
 
+	// Name the deployment up front so release directories, hooks and
+	// history match
+	if config.DeploymentID == "" {
+		config.DeploymentID = generateDeploymentID()
+	}
+
+	hooks := newHookRunner(config)
+	result, err := m.deploy(deployer, config, hooks)
+	if result != nil && !result.Success {
+		hooks.run(FailureHook, result, err)
+	}
+
+	// Record deployment in history
+	if result != nil {
+		result.Hooks = hooks.results
+		history, histErr := NewDeploymentHistory()
+		if histErr == nil {
+			if recordErr := history.AddRecord(result, config); recordErr != nil && config.Verbose {
+				fmt.Printf("Warning: Failed to record deployment history: %v\n", recordErr)
+			}
+		} else if config.Verbose {
+			fmt.Printf("Warning: Failed to initialize deployment history: %v\n", histErr)
+		}
+	}
+
+	return result, err
+}
+
+// deploy builds, validates and deploys the site, running the hooks of each
+// stage except on-failure
+func (m *Manager) deploy(deployer Deployer, config DeploymentConfig, hooks *hookRunner) (*DeploymentResult, error) {
 	// Execute pre-deployment build if requested
 	if config.BuildFirst {
+		if err := hooks.run(PreBuildHook, nil, nil); err != nil {
+			return &DeploymentResult{
+				Success:  false,
+				Strategy: config.Strategy,
+				Errors:   []string{err.Error()},
+			}, err
+		}
+
 		if config.Verbose {
 			fmt.Println("🔨 Running pre-deployment build...")
 		}
 
 		buildOptions := internal.BuildOptions{
-			Verbose: config.Verbose,
+			SkipSearch: config.SkipSearch,
+			BaseURL:    config.BaseURL,
+			Verbose:    config.Verbose,
+		}
+
+		build := internal.BuildAll
+		if internal.GetProjectLayout().IsStatic() {
+			build = static.Build
 		}
 
-		buildResult, err := internal.BuildAll(buildOptions)
+		buildResult, err := build(buildOptions)
 		if err != nil {
 			return &DeploymentResult{
 				Success:       false,
@@ -74,7 +125,7 @@ func (m *Manager) Deploy(config DeploymentConfig) (*DeploymentResult, error) {
 		validationOptions := GetDefaultValidationOptions()
 		validationOptions.Verbose = config.Verbose
 
-		validationResult, err := ValidateDeployment("site/", validationOptions)
+		validationResult, err := ValidateDeployment(internal.GetProjectLayout().OutputDir, validationOptions)
 		if err != nil {
 			return &DeploymentResult{
 				Success:  false,
@@ -122,27 +173,132 @@ func (m *Manager) Deploy(config DeploymentConfig) (*DeploymentResult, error) {
 		}
 	}
 
+	// Keep drafts and scheduled pages out of the deployed site
+	layout := internal.GetProjectLayout()
+	unpublished, err := UnpublishedPages(layout, time.Now())
+	if err != nil {
+		return &DeploymentResult{
+			Success:  false,
+			Strategy: config.Strategy,
+			Errors:   []string{fmt.Sprintf("failed to load pages: %v", err)},
+		}, err
+	}
+	config.Unpublished = anchoredPatterns(layout.OutputDir, unpublished)
+	if config.Verbose && len(unpublished) > 0 {
+		fmt.Printf("🚧 Excluding %d drafts and scheduled pages\n", len(unpublished))
+	}
+
+	// A git deployment pushes the repository as it is, so it cannot leave
+	// committed pages out
+	var pushedUnpublished []string
+	if config.Strategy == GitStrategy && len(unpublished) > 0 {
+		pushedUnpublished, err = committedFiles(config.GitBranch, unpublished)
+		if err != nil {
+			return &DeploymentResult{
+				Success:  false,
+				Strategy: config.Strategy,
+				Errors:   []string{fmt.Sprintf("failed to check for committed drafts: %v", err)},
+			}, err
+		}
+		if len(pushedUnpublished) > 0 && !config.AllowUnpublished {
+			err := internal.NewValidationErrorWithSuggestions(
+				fmt.Sprintf("%d drafts or scheduled pages are committed and would be published by git push: %s",
+					len(pushedUnpublished), strings.Join(pushedUnpublished, ", ")),
+				[]string{
+					"Remove them from the branch, e.g. git rm --cached <file>, and commit",
+					"Or deploy with --allow-unpublished to push them anyway",
+				},
+			)
+			return &DeploymentResult{
+				Success:  false,
+				Strategy: config.Strategy,
+				Errors:   []string{err.Message},
+			}, err
+		}
+	}
+
+	// Compare the output with what was last deployed to the target
+	manifest, err := BuildOutputManifest(config)
+	if err != nil {
+		return &DeploymentResult{
+			Success:  false,
+			Strategy: config.Strategy,
+			Errors:   []string{fmt.Sprintf("failed to build output manifest: %v", err)},
+		}, err
+	}
+	target := ManifestTarget(config)
+	if !config.FullSync {
+		previous, err := LoadDeployedManifest(target)
+		if err != nil && config.Verbose {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		if previous != nil {
+			config.Changes = manifest.Diff(previous)
+		}
+	}
+	printChanges(config.Changes, manifest, config.Verbose)
+
+	if err := hooks.run(PreDeployHook, nil, nil); err != nil {
+		return &DeploymentResult{
+			Success:  false,
+			Strategy: config.Strategy,
+			Errors:   []string{err.Error()},
+		}, err
+	}
+
 	// Execute deployment
 	result, err := deployer.Deploy(config)
 	if result != nil {
 		result.BuildExecuted = config.BuildFirst
-	}
+		if result.Success {
+			buildInfo, infoErr := CollectBuildInfo(config, manifest)
+			if infoErr != nil && config.Verbose {
+				fmt.Printf("Warning: Failed to collect build information: %v\n", infoErr)
+			}
+			result.BuildInfo = buildInfo
 
-	// Record deployment in history
-	if result != nil {
-		history, histErr := NewDeploymentHistory()
-		if histErr == nil {
-			if recordErr := history.AddRecord(result, config); recordErr != nil && config.Verbose {
-				fmt.Printf("Warning: Failed to record deployment history: %v\n", recordErr)
+			if !config.DryRun {
+				if saveErr := SaveDeployedManifest(target, config.DeploymentID, manifest); saveErr != nil && config.Verbose {
+					fmt.Printf("Warning: Failed to save deployed manifest: %v\n", saveErr)
+				}
 			}
-		} else if config.Verbose {
-			fmt.Printf("Warning: Failed to initialize deployment history: %v\n", histErr)
+		}
+		if result.Success && len(pushedUnpublished) > 0 {
+			result.Messages = append(result.Messages, fmt.Sprintf("⚠️  Pushed %d drafts or scheduled pages (--allow-unpublished)", len(pushedUnpublished)))
+		}
+		if result.Success {
+			hooks.run(PostDeployHook, result, nil)
 		}
 	}
 
 	return result, err
 }
 
+// printChanges summarizes what the deployment changes on the target
+func printChanges(changes *ManifestDiff, manifest *Manifest, verbose bool) {
+	if changes == nil {
+		fmt.Printf("📋 Deploying %d files (no previous deployment to compare with)\n", len(manifest.Files))
+		return
+	}
+	if changes.Empty() {
+		fmt.Println("📋 No changes since the last deployment")
+		return
+	}
+
+	fmt.Printf("📋 Changes since the last deployment: %s\n", changes.Summary())
+	if verbose {
+		for _, file := range changes.Added {
+			fmt.Printf("  + %s\n", file)
+		}
+		for _, file := range changes.Changed {
+			fmt.Printf("  ~ %s\n", file)
+		}
+		for _, file := range changes.Removed {
+			fmt.Printf("  - %s\n", file)
+		}
+	}
+}
+
 // Validate checks if deployment configuration is valid
 func (m *Manager) Validate(config DeploymentConfig) error {
 	deployer, exists := m.deployers[config.Strategy]
@@ -169,6 +325,10 @@ func ParseStrategy(s string) (DeploymentStrategy, error) {
 		return GitStrategy, nil
 	case "rsync":
 		return RsyncStrategy, nil
+	case "netlify":
+		return NetlifyStrategy, nil
+	case "s3":
+		return S3Strategy, nil
 	default:
 		return -1, fmt.Errorf("unknown deployment strategy: %s", s)
 	}
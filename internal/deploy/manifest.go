@@ -0,0 +1,161 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// ManifestDir holds the manifest last deployed to each target
+var ManifestDir = filepath.Join(".garp", "manifests")
+
+// Manifest lists the files of the output directory that are deployed
+type Manifest struct {
+	// Files maps each file, relative to the output directory and slash
+	// separated, to the SHA-256 of its content
+	Files map[string]string
+	Size  int64
+}
+
+// ManifestDiff lists the files that differ from the last deployment
+type ManifestDiff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// deployedManifest is the manifest stored after a successful deployment
+type deployedManifest struct {
+	Target       string            `json:"target"`
+	DeploymentID string            `json:"deployment_id"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Files        map[string]string `json:"files"`
+}
+
+// BuildOutputManifest hashes the files of the output directory that the
+// deployment will transfer
+func BuildOutputManifest(config DeploymentConfig) (*Manifest, error) {
+	excludes := deployExcludes(config)
+	files, size, err := buildManifest(internal.GetProjectLayout().OutputDir, excludes, sha256.New)
+	if err != nil {
+		return nil, err
+	}
+	return &Manifest{Files: files, Size: size}, nil
+}
+
+// Diff compares the manifest with the files of a previous deployment
+func (m *Manifest) Diff(previous map[string]string) *ManifestDiff {
+	diff := &ManifestDiff{}
+	for file, sum := range m.Files {
+		old, ok := previous[file]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, file)
+		case old != sum:
+			diff.Changed = append(diff.Changed, file)
+		}
+	}
+	for file := range previous {
+		if _, ok := m.Files[file]; !ok {
+			diff.Removed = append(diff.Removed, file)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// Empty reports whether nothing changed
+func (d *ManifestDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// Summary describes the diff in one line
+func (d *ManifestDiff) Summary() string {
+	return fmt.Sprintf("%d added, %d changed, %d removed", len(d.Added), len(d.Changed), len(d.Removed))
+}
+
+// Files returns every added, changed and removed file, sorted
+func (d *ManifestDiff) Files() []string {
+	files := append(append(append([]string{}, d.Added...), d.Changed...), d.Removed...)
+	sort.Strings(files)
+	return files
+}
+
+// ManifestTarget identifies where a deployment goes, so that manifests of
+// different targets are kept apart
+func ManifestTarget(config DeploymentConfig) string {
+	switch config.Strategy {
+	case GitStrategy:
+		return fmt.Sprintf("git:%s/%s", config.GitRemote, config.GitBranch)
+	case RsyncStrategy:
+		if config.RsyncHost == "" {
+			return "rsync:" + config.RsyncPath
+		}
+		return fmt.Sprintf("rsync:%s:%s", sshDestination(config.RsyncUser, config.RsyncHost), config.RsyncPath)
+	case NetlifyStrategy:
+		return fmt.Sprintf("netlify:%s", config.SiteID)
+	case S3Strategy:
+		return fmt.Sprintf("s3:%s/%s/%s", config.S3Endpoint, config.S3Bucket, s3Prefix(config.S3Prefix))
+	default:
+		return config.Strategy.String()
+	}
+}
+
+// LoadDeployedManifest returns the files last deployed to the target, or
+// nil when nothing was deployed there yet
+func LoadDeployedManifest(target string) (map[string]string, error) {
+	data, err := os.ReadFile(manifestPath(target))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read deployed manifest: %v", err)
+	}
+
+	var stored deployedManifest
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid deployed manifest %s: %v", manifestPath(target), err)
+	}
+	return stored.Files, nil
+}
+
+// SaveDeployedManifest records the manifest deployed to the target
+func SaveDeployedManifest(target, deploymentID string, manifest *Manifest) error {
+	if err := os.MkdirAll(ManifestDir, 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(deployedManifest{
+		Target:       target,
+		DeploymentID: deploymentID,
+		Timestamp:    time.Now(),
+		Files:        manifest.Files,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(manifestPath(target), data, 0644)
+}
+
+// manifestPath returns the file the manifest of a target is stored in
+func manifestPath(target string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, target)
+	return filepath.Join(ManifestDir, name+".json")
+}
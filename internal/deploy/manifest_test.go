@@ -0,0 +1,132 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManifestDiff(t *testing.T) {
+	manifest := &Manifest{Files: map[string]string{
+		"index.html":     "new",
+		"about.html":     "same",
+		"css/style.css":  "added",
+		"blog/post.html": "added",
+	}}
+	diff := manifest.Diff(map[string]string{
+		"index.html": "old",
+		"about.html": "same",
+		"old.html":   "gone",
+	})
+
+	want := &ManifestDiff{
+		Added:   []string{"blog/post.html", "css/style.css"},
+		Changed: []string{"index.html"},
+		Removed: []string{"old.html"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("diff = %+v, want %+v", diff, want)
+	}
+	if got := diff.Summary(); got != "2 added, 1 changed, 1 removed" {
+		t.Errorf("summary = %q", got)
+	}
+	if diff.Empty() {
+		t.Error("diff should not be empty")
+	}
+	if !manifest.Diff(manifest.Files).Empty() {
+		t.Error("a manifest compared with itself should have no changes")
+	}
+}
+
+func TestDeployedManifestPerTarget(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	staging := ManifestTarget(DeploymentConfig{Strategy: RsyncStrategy, RsyncUser: "deploy", RsyncHost: "example.com", RsyncPath: "/srv/staging"})
+	production := ManifestTarget(DeploymentConfig{Strategy: RsyncStrategy, RsyncUser: "deploy", RsyncHost: "example.com", RsyncPath: "/srv/production"})
+
+	if files, err := LoadDeployedManifest(staging); err != nil || files != nil {
+		t.Fatalf("expected no manifest before the first deploy, got %v (%v)", files, err)
+	}
+
+	manifest := &Manifest{Files: map[string]string{"index.html": "abc"}}
+	if err := SaveDeployedManifest(staging, "deploy_1", manifest); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	files, err := LoadDeployedManifest(staging)
+	if err != nil || !reflect.DeepEqual(files, manifest.Files) {
+		t.Fatalf("loaded %v (%v), want %v", files, err, manifest.Files)
+	}
+	if files, _ := LoadDeployedManifest(production); files != nil {
+		t.Errorf("another target must not share the manifest, got %v", files)
+	}
+}
+
+// recordingRsync puts an rsync on PATH that records its arguments and the
+// file list it was given, and returns the path of the argument log
+func recordingRsync(t *testing.T) string {
+	t.Helper()
+
+	bin := t.TempDir()
+	log := filepath.Join(bin, "args.log")
+	script := `#!/bin/sh
+printf '%s\n' "$@" > "$RSYNC_LOG"
+for arg; do
+	case $arg in --files-from=*) cp "${arg#--files-from=}" "$RSYNC_LOG.files" ;; esac
+done
+`
+	if err := os.WriteFile(filepath.Join(bin, "rsync"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("RSYNC_LOG", log)
+	return log
+}
+
+func TestRsyncTransfersOnlyChanges(t *testing.T) {
+	outputSite(t)
+	log := recordingRsync(t)
+
+	config := DeploymentConfig{
+		Strategy:       RsyncStrategy,
+		RsyncHost:      "example.com",
+		RsyncPath:      "/srv/site",
+		SkipValidation: true,
+		Changes: &ManifestDiff{
+			Changed: []string{"index.html"},
+			Removed: []string{"old.html"},
+		},
+	}
+	if _, err := NewRsyncDeployer().Deploy(config); err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !contains(args, "--delete-missing-args") || contains(args, "--delete") {
+		t.Errorf("unexpected rsync arguments %v", args)
+	}
+	files, err := os.ReadFile(log + ".files")
+	if err != nil {
+		t.Fatalf("rsync was not given a file list: %v", err)
+	}
+	if string(files) != "index.html\nold.html\n" {
+		t.Errorf("file list = %q", files)
+	}
+
+	// Nothing to transfer when nothing changed
+	os.Remove(log)
+	config.Changes = &ManifestDiff{}
+	result, err := NewRsyncDeployer().Deploy(config)
+	if err != nil || !result.Success {
+		t.Fatalf("deploy without changes failed: %v", err)
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Error("rsync should not run when nothing changed")
+	}
+}
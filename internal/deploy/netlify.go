@@ -0,0 +1,284 @@
+package deploy
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// DefaultNetlifyAPIURL is the API used when no api_url is configured
+const DefaultNetlifyAPIURL = "https://api.netlify.com/api/v1"
+
+// NetlifyDeployer uploads the output directory to a static host with the
+// Netlify file digest API: it sends a manifest of SHA1 digests and then
+// uploads only the files the host does not have yet
+type NetlifyDeployer struct {
+	client       *http.Client
+	pollInterval time.Duration
+	pollTimeout  time.Duration
+}
+
+// NewNetlifyDeployer creates a new Netlify deployer
+func NewNetlifyDeployer() *NetlifyDeployer {
+	return &NetlifyDeployer{
+		client:       &http.Client{Timeout: 2 * time.Minute},
+		pollInterval: 2 * time.Second,
+		pollTimeout:  5 * time.Minute,
+	}
+}
+
+// Name returns the deployer name
+func (n *NetlifyDeployer) Name() string {
+	return "Netlify"
+}
+
+// netlifyDeploy is the deploy object returned by the API
+type netlifyDeploy struct {
+	ID           string   `json:"id"`
+	State        string   `json:"state"`
+	Required     []string `json:"required"`
+	URL          string   `json:"url"`
+	SSLURL       string   `json:"ssl_url"`
+	DeploySSLURL string   `json:"deploy_ssl_url"`
+	ErrorMessage string   `json:"error_message"`
+}
+
+// Validate checks if a Netlify deployment is possible
+func (n *NetlifyDeployer) Validate(config DeploymentConfig) error {
+	if config.APIKey == "" {
+		return fmt.Errorf("an API key is required - set DEPLOY_API_KEY or pass --api-key")
+	}
+
+	if config.SiteID == "" {
+		return fmt.Errorf("a site ID is required - set deploy.site_id in garp.toml or pass --site-id")
+	}
+
+	if _, err := url.Parse(n.apiURL(config)); err != nil {
+		return fmt.Errorf("invalid API URL: %v", err)
+	}
+
+	outputDir := internal.GetProjectLayout().OutputDir
+	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+		return fmt.Errorf("output directory '%s/' does not exist - run 'garp build' first", outputDir)
+	}
+
+	return nil
+}
+
+// Deploy executes a Netlify deployment
+func (n *NetlifyDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, error) {
+	result := &DeploymentResult{
+		Strategy: NetlifyStrategy,
+	}
+	start := time.Now()
+
+	fail := func(err error) (*DeploymentResult, error) {
+		result.Errors = append(result.Errors, err.Error())
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	if config.Verbose {
+		fmt.Printf("🚀 Starting Netlify deployment to site %s\n", config.SiteID)
+	}
+
+	if err := n.Validate(config); err != nil {
+		return fail(err)
+	}
+
+	// Netlify identifies files by their SHA1 and expects absolute paths
+	outputDir := internal.GetProjectLayout().OutputDir
+	excludes := deployExcludes(config)
+	manifest, _, err := buildManifest(outputDir, excludes, sha1.New)
+	if err != nil {
+		return fail(err)
+	}
+	files := make(map[string]string, len(manifest))
+	for rel, sum := range manifest {
+		files["/"+rel] = sum
+	}
+
+	if config.DryRun {
+		result.Messages = append(result.Messages, fmt.Sprintf("Dry run completed - would deploy %d files to site %s", len(files), config.SiteID))
+		result.Success = true
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	var deploy netlifyDeploy
+	endpoint := fmt.Sprintf("/sites/%s/deploys", url.PathEscape(config.SiteID))
+	if err := n.request(config, http.MethodPost, endpoint, map[string]interface{}{"files": files}, &deploy); err != nil {
+		return fail(fmt.Errorf("failed to create deploy: %v", err))
+	}
+	if config.Verbose {
+		fmt.Printf("Created deploy %s, %d of %d files need uploading\n", deploy.ID, len(deploy.Required), len(files))
+	}
+
+	// Upload one file for each digest the host is missing
+	required := make(map[string]bool, len(deploy.Required))
+	for _, sum := range deploy.Required {
+		required[sum] = true
+	}
+	paths := make([]string, 0, len(files))
+	for file := range files {
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+
+	uploaded := 0
+	for _, file := range paths {
+		sum := files[file]
+		if !required[sum] {
+			continue
+		}
+		delete(required, sum)
+
+		if config.Verbose {
+			fmt.Printf("Uploading %s\n", file)
+		}
+		if err := n.upload(config, deploy.ID, file, filepath.Join(outputDir, filepath.FromSlash(file[1:]))); err != nil {
+			return fail(fmt.Errorf("failed to upload %s: %v", file, err))
+		}
+		uploaded++
+	}
+	if len(required) > 0 {
+		return fail(fmt.Errorf("the host requested %d files that are not in the output directory", len(required)))
+	}
+
+	deploy, err = n.waitUntilReady(config, deploy)
+	if err != nil {
+		return fail(err)
+	}
+
+	result.URL = firstNonEmpty(deploy.SSLURL, deploy.URL, deploy.DeploySSLURL)
+	result.Messages = append(result.Messages,
+		fmt.Sprintf("Uploaded %d of %d files to site %s (deploy %s)", uploaded, len(files), config.SiteID, deploy.ID))
+	result.Success = true
+	result.Duration = time.Since(start)
+
+	if config.Verbose {
+		fmt.Printf("✅ Netlify deployment completed in %v\n", result.Duration)
+	}
+
+	return result, nil
+}
+
+// waitUntilReady polls the deploy until the host has processed it
+func (n *NetlifyDeployer) waitUntilReady(config DeploymentConfig, deploy netlifyDeploy) (netlifyDeploy, error) {
+	deadline := time.Now().Add(n.pollTimeout)
+	for {
+		switch deploy.State {
+		case "ready":
+			return deploy, nil
+		case "error":
+			return deploy, fmt.Errorf("deploy %s failed: %s", deploy.ID, deploy.ErrorMessage)
+		}
+		if time.Now().After(deadline) {
+			return deploy, fmt.Errorf("deploy %s is still %s after %v", deploy.ID, deploy.State, n.pollTimeout)
+		}
+
+		time.Sleep(n.pollInterval)
+		if err := n.request(config, http.MethodGet, "/deploys/"+url.PathEscape(deploy.ID), nil, &deploy); err != nil {
+			return deploy, fmt.Errorf("failed to check deploy status: %v", err)
+		}
+	}
+}
+
+// upload sends the content of a file to the deploy
+func (n *NetlifyDeployer) upload(config DeploymentConfig, deployID, file, localPath string) error {
+	content, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	segments := strings.Split(strings.TrimPrefix(file, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	endpoint := fmt.Sprintf("/deploys/%s/files/%s", url.PathEscape(deployID), strings.Join(segments, "/"))
+
+	req, err := http.NewRequest(http.MethodPut, n.apiURL(config)+endpoint, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return n.do(config, req, nil)
+}
+
+// request sends a JSON request to the API and decodes the JSON response
+func (n *NetlifyDeployer) request(config DeploymentConfig, method, endpoint string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, n.apiURL(config)+endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return n.do(config, req, out)
+}
+
+// do authenticates and sends a request, turning error statuses into errors
+func (n *NetlifyDeployer) do(config DeploymentConfig, req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+config.APIKey)
+	req.Header.Set("User-Agent", "garp")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if resp.StatusCode == http.StatusUnauthorized {
+			return errors.New("the API rejected the API key (401 Unauthorized)")
+		}
+		return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from %s: %v", req.URL.Path, err)
+	}
+	return nil
+}
+
+// apiURL returns the configured API URL without a trailing slash
+func (n *NetlifyDeployer) apiURL(config DeploymentConfig) string {
+	if config.APIURL == "" {
+		return DefaultNetlifyAPIURL
+	}
+	return strings.TrimSuffix(config.APIURL, "/")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
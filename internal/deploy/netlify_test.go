@@ -0,0 +1,181 @@
+package deploy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// fakeNetlify is a stand-in for the Netlify deploy API that already has the
+// files whose digests are listed in existing
+type fakeNetlify struct {
+	t        *testing.T
+	existing map[string]bool
+
+	mu       sync.Mutex
+	files    map[string]string // manifest received when creating the deploy
+	uploaded map[string]string // uploaded path -> content
+	polls    int
+}
+
+func (f *fakeNetlify) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if got := r.Header.Get("Authorization"); got != "Bearer secret-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/sites/site-123/deploys":
+		var body struct {
+			Files map[string]string `json:"files"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.t.Errorf("invalid deploy body: %v", err)
+		}
+		f.files = body.Files
+
+		var required []string
+		for _, sum := range body.Files {
+			if !f.existing[sum] {
+				required = append(required, sum)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "dep-1", "state": "uploading", "required": required})
+
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/api/v1/deploys/dep-1/files/"):
+		if got := r.Header.Get("Content-Type"); got != "application/octet-stream" {
+			f.t.Errorf("upload Content-Type = %q", got)
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.uploaded[strings.TrimPrefix(r.URL.Path, "/api/v1/deploys/dep-1/files")] = string(data)
+		w.Write([]byte("{}"))
+
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/deploys/dep-1":
+		f.polls++
+		state := "processing"
+		if f.polls > 1 {
+			state = "ready"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "dep-1", "state": state, "ssl_url": "https://site.example.com"})
+
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func sha1Hex(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// outputSite creates an output directory and makes it the project output
+func outputSite(t *testing.T) {
+	t.Helper()
+
+	output := t.TempDir()
+	writeFile(t, filepath.Join(output, "index.html"), "home")
+	writeFile(t, filepath.Join(output, "about us", "index.html"), "about")
+	writeFile(t, filepath.Join(output, "css", "style.css"), "body{}")
+	writeFile(t, filepath.Join(output, "drafts", "post.html"), "draft")
+
+	previous := internal.GetProjectLayout()
+	layout := internal.DefaultProjectLayout()
+	layout.OutputDir = output
+	internal.SetProjectLayout(layout)
+	t.Cleanup(func() { internal.SetProjectLayout(previous) })
+}
+
+func testNetlifyDeployer() *NetlifyDeployer {
+	deployer := NewNetlifyDeployer()
+	deployer.pollInterval = time.Millisecond
+	deployer.pollTimeout = time.Second
+	return deployer
+}
+
+func TestNetlifyDeployUploadsMissingFiles(t *testing.T) {
+	outputSite(t)
+	api := &fakeNetlify{t: t, existing: map[string]bool{sha1Hex("body{}"): true}, uploaded: map[string]string{}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	result, err := testNetlifyDeployer().Deploy(DeploymentConfig{
+		Strategy:      NetlifyStrategy,
+		APIURL:        server.URL + "/api/v1/",
+		APIKey:        "secret-token",
+		SiteID:        "site-123",
+		RsyncExcludes: []string{"/drafts/post.html"},
+	})
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+
+	wantFiles := map[string]string{
+		"/index.html":          sha1Hex("home"),
+		"/about us/index.html": sha1Hex("about"),
+		"/css/style.css":       sha1Hex("body{}"),
+	}
+	if !reflect.DeepEqual(api.files, wantFiles) {
+		t.Errorf("manifest = %v, want %v", api.files, wantFiles)
+	}
+
+	wantUploads := map[string]string{
+		"/index.html":          "home",
+		"/about us/index.html": "about",
+	}
+	if !reflect.DeepEqual(api.uploaded, wantUploads) {
+		t.Errorf("uploaded = %v, want %v", api.uploaded, wantUploads)
+	}
+
+	if !result.Success || result.URL != "https://site.example.com" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if api.polls < 2 {
+		t.Errorf("expected the deploy to be polled until ready, got %d polls", api.polls)
+	}
+}
+
+func TestNetlifyDeployErrors(t *testing.T) {
+	outputSite(t)
+	api := &fakeNetlify{t: t, uploaded: map[string]string{}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	config := DeploymentConfig{Strategy: NetlifyStrategy, APIURL: server.URL + "/api/v1", SiteID: "site-123"}
+	if _, err := testNetlifyDeployer().Deploy(config); err == nil || !strings.Contains(err.Error(), "API key is required") {
+		t.Errorf("expected a missing API key error, got %v", err)
+	}
+
+	config.APIKey = "wrong"
+	result, err := testNetlifyDeployer().Deploy(config)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected the API key to be rejected, got %v", err)
+	}
+	if result == nil || result.Success || len(result.Errors) == 0 {
+		t.Errorf("expected a failed result, got %+v", result)
+	}
+
+	config.APIKey = "secret-token"
+	config.DryRun = true
+	result, err = testNetlifyDeployer().Deploy(config)
+	if err != nil || !result.Success {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if api.files != nil {
+		t.Error("a dry run must not call the API")
+	}
+}
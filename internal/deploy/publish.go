@@ -0,0 +1,58 @@
+package deploy
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/content"
+)
+
+// UnpublishedPages returns the paths of drafts and scheduled pages that live
+// in the output directory. Static builds already leave them out, so this only
+// matters when the source directory is shipped as-is.
+func UnpublishedPages(layout internal.ProjectLayout, now time.Time) ([]string, error) {
+	pages, err := content.LoadPages(layout.SourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, page := range content.Unpublished(pages, now) {
+		rel, err := filepath.Rel(layout.OutputDir, page.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		paths = append(paths, page.Path)
+	}
+	return paths, nil
+}
+
+// anchoredPatterns returns rsync patterns that match only the pages in
+// outputDir, anchored at the transfer root
+func anchoredPatterns(outputDir string, pages []string) []string {
+	patterns := make([]string, 0, len(pages))
+	for _, page := range pages {
+		rel, _ := filepath.Rel(outputDir, page)
+		patterns = append(patterns, "/"+filepath.ToSlash(rel))
+	}
+	return patterns
+}
+
+// unpublishedRemoved reports whether a page that was deployed before has
+// become a draft or scheduled page since
+func unpublishedRemoved(changes *ManifestDiff, unpublished []string) bool {
+	for _, file := range changes.Removed {
+		if contains(unpublished, "/"+file) {
+			return true
+		}
+	}
+	return false
+}
+
+// deployExcludes returns the patterns of output files that are not deployed
+func deployExcludes(config DeploymentConfig) []string {
+	excludes := append(append([]string{}, defaultExcludes...), config.RsyncExcludes...)
+	return append(excludes, config.Unpublished...)
+}
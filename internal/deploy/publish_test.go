@@ -0,0 +1,142 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitDeployRefusesCommittedDrafts(t *testing.T) {
+	gitRepo(t)
+	writeFile(t, "public/index.md", "---\ntitle: Home\n---\nHello")
+	writeFile(t, "public/draft.md", "---\ntitle: Coming soon\ndraft: true\n---\nSoon")
+	git(t, "add", "-A")
+	git(t, "commit", "--quiet", "-m", "site with a draft")
+
+	config := DeploymentConfig{
+		Strategy:         GitStrategy,
+		GitRemote:        "origin",
+		SkipContentCheck: true,
+	}
+
+	result, err := NewManager().Deploy(config)
+	if err == nil || result.Success || !strings.Contains(err.Error(), "public/draft.md") {
+		t.Fatalf("expected the deployment to be refused, got %v", err)
+	}
+	if pushed := git(t, "ls-remote", "origin", "main"); pushed != "" {
+		t.Fatalf("the draft was pushed: %s", pushed)
+	}
+
+	config.AllowUnpublished = true
+	result, err = NewManager().Deploy(config)
+	if err != nil || !result.Success {
+		t.Fatalf("deploy with --allow-unpublished failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(result.Messages, "\n"), "Pushed 1 drafts or scheduled pages") {
+		t.Errorf("expected a warning about the pushed draft, got %v", result.Messages)
+	}
+
+	// Drafts that are kept out of the repository are not pushed
+	writeFile(t, ".gitignore", ".garp/\npublic/draft.md\n")
+	git(t, "rm", "--quiet", "--cached", "public/draft.md")
+	git(t, "add", ".gitignore")
+	git(t, "commit", "--quiet", "-m", "keep the draft local")
+	config.AllowUnpublished = false
+	if result, err := NewManager().Deploy(config); err != nil || !result.Success {
+		t.Fatalf("deploy with an uncommitted draft failed: %v", err)
+	}
+}
+
+// mirrorRsync puts an rsync on PATH that copies the source into the
+// destination directory with rsync's filter semantics: excluded files are
+// left alone on the receiver, while hidden files are only skipped when sending
+func mirrorRsync(t *testing.T) {
+	t.Helper()
+
+	bin := t.TempDir()
+	script := `#!/bin/sh
+hidden= excluded= files= delete=
+while [ $# -gt 2 ]; do
+	case $1 in
+	--filter) shift; hidden="$hidden ${1#H }" ;;
+	--exclude) shift; excluded="$excluded $1" ;;
+	--files-from=*) files=${1#--files-from=} ;;
+	--delete|--delete-missing-args) delete=1 ;;
+	esac
+	shift
+done
+src=$1 dst=${2#*:}
+has() { case " $1 " in *" /$2 "*) return 0 ;; esac; return 1; }
+
+if [ -z "$files" ]; then
+	files=$(mktemp)
+	(cd "$src" && find . -type f | sed 's|^\./||') > "$files"
+	if [ -n "$delete" ]; then
+		(cd "$dst" && find . -type f | sed 's|^\./||') | while read -r rel; do
+			has "$excluded" "$rel" && continue
+			if [ ! -f "$src$rel" ] || has "$hidden" "$rel"; then rm "$dst$rel"; fi
+		done
+	fi
+fi
+while read -r rel; do
+	if has "$excluded" "$rel" || has "$hidden" "$rel"; then continue; fi
+	if [ -f "$src$rel" ]; then
+		mkdir -p "$(dirname "$dst$rel")" && cp "$src$rel" "$dst$rel"
+	elif [ -n "$delete" ]; then
+		rm -f "$dst$rel"
+	fi
+done < "$files"
+`
+	if err := os.WriteFile(filepath.Join(bin, "rsync"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRsyncDeployRemovesUnpublishedPages(t *testing.T) {
+	t.Chdir(t.TempDir())
+	mirrorRsync(t)
+	writeFile(t, "public/index.md", "---\ntitle: Home\n---\nHello")
+	writeFile(t, "public/post.md", "---\ntitle: Post\n---\nPublished")
+
+	target := t.TempDir()
+	config := DeploymentConfig{
+		Strategy:         RsyncStrategy,
+		RsyncHost:        "example.com",
+		RsyncPath:        target,
+		SkipValidation:   true,
+		SkipContentCheck: true,
+	}
+	deployed := func(name string) bool {
+		t.Helper()
+		if result, err := NewManager().Deploy(config); err != nil || !result.Success {
+			t.Fatalf("deploy failed: %v", err)
+		}
+		_, err := os.Stat(filepath.Join(target, name))
+		return err == nil
+	}
+
+	if !deployed("post.md") {
+		t.Fatal("the published post was not deployed")
+	}
+
+	// Only the draft changed, so the target is updated incrementally
+	writeFile(t, "public/post.md", "---\ntitle: Post\ndraft: true\n---\nPublished")
+	if deployed("post.md") {
+		t.Error("the post that became a draft is still on the target")
+	}
+	if !deployed("index.md") {
+		t.Error("the home page was removed from the target")
+	}
+
+	writeFile(t, "public/post.md", "---\ntitle: Post\n---\nPublished")
+	if !deployed("post.md") {
+		t.Fatal("the republished post was not deployed")
+	}
+	writeFile(t, "public/post.md", "---\ntitle: Post\ndraft: true\n---\nPublished")
+	config.FullSync = true
+	if deployed("post.md") {
+		t.Error("a full sync left the draft on the target")
+	}
+}
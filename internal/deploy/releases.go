@@ -0,0 +1,280 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// ReleasesDir holds one directory per release below the deploy path
+	ReleasesDir = "releases"
+
+	// CurrentLink is the symlink below the deploy path that points at the
+	// live release; the web server should serve this path
+	CurrentLink = "current"
+
+	// DefaultKeepReleases is how many releases are kept when none is configured
+	DefaultKeepReleases = 5
+)
+
+// ReleaseInfo identifies a release directory on a deployment target
+type ReleaseInfo struct {
+	Host string `json:"host,omitempty"`
+	User string `json:"user,omitempty"`
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+// Target returns the host and deploy path of the release
+func (ri ReleaseInfo) Target() string {
+	if ri.Host == "" {
+		return ri.Path
+	}
+	return sshDestination(ri.User, ri.Host) + ":" + ri.Path
+}
+
+// releaseTarget performs file operations on the host that holds the releases
+type releaseTarget interface {
+	Join(elem ...string) string
+	MkdirAll(dir string) error
+	Exists(path string) (bool, error)
+	List(dir string) ([]string, error)
+	Readlink(link string) (string, error)
+	Symlink(target, link string) error
+	RemoveAll(path string) error
+}
+
+// newReleaseTarget returns a target for a local path when host is empty and
+// an SSH target otherwise
+func newReleaseTarget(host, user string) releaseTarget {
+	if host == "" {
+		return localTarget{}
+	}
+	return sshTarget{destination: sshDestination(user, host)}
+}
+
+// CurrentRelease returns the name of the release the current symlink points
+// at, or an empty string when no release is live
+func CurrentRelease(info ReleaseInfo) (string, error) {
+	return currentRelease(newReleaseTarget(info.Host, info.User), info.Path)
+}
+
+// RollbackRelease points the current symlink back at an existing release
+func RollbackRelease(info ReleaseInfo) error {
+	target := newReleaseTarget(info.Host, info.User)
+	dir := target.Join(info.Path, ReleasesDir, info.Name)
+
+	exists, err := target.Exists(dir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("release %s no longer exists on %s (it may have been pruned)", info.Name, info.Target())
+	}
+
+	return activateRelease(target, info.Path, info.Name)
+}
+
+// currentRelease reads the release name from the current symlink
+func currentRelease(target releaseTarget, base string) (string, error) {
+	link, err := target.Readlink(target.Join(base, CurrentLink))
+	if err != nil || link == "" {
+		return "", err
+	}
+	return path.Base(filepath.ToSlash(link)), nil
+}
+
+// activateRelease atomically switches the current symlink to a release. The
+// link is relative so the deploy path can be moved or mounted elsewhere.
+func activateRelease(target releaseTarget, base, name string) error {
+	return target.Symlink(ReleasesDir+"/"+name, target.Join(base, CurrentLink))
+}
+
+// pruneReleases removes the oldest releases so that at most keep remain. The
+// live release is never removed. It returns the names of removed releases.
+func pruneReleases(target releaseTarget, base string, keep int) ([]string, error) {
+	if keep <= 0 {
+		keep = DefaultKeepReleases
+	}
+
+	releases, err := target.List(target.Join(base, ReleasesDir))
+	if err != nil {
+		return nil, err
+	}
+	live, err := currentRelease(target, base)
+	if err != nil {
+		return nil, err
+	}
+
+	// Release names start with a deployment ID, so they sort by age
+	sort.Sort(sort.Reverse(sort.StringSlice(releases)))
+
+	var removed []string
+	kept := 0
+	for _, name := range releases {
+		if kept < keep || name == live {
+			kept++
+			continue
+		}
+		if err := target.RemoveAll(target.Join(base, ReleasesDir, name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
+
+// localTarget manages releases in a directory on this machine
+type localTarget struct{}
+
+func (localTarget) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (localTarget) MkdirAll(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+	return nil
+}
+
+func (localTarget) Exists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (localTarget) List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list releases: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (localTarget) Readlink(link string) (string, error) {
+	target, err := os.Readlink(link)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %v", link, err)
+	}
+	return target, nil
+}
+
+// Symlink creates the link under a temporary name and renames it into
+// place, which replaces an existing link atomically
+func (localTarget) Symlink(target, link string) error {
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("failed to create symlink: %v", err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to switch %s: %v", link, err)
+	}
+	return nil
+}
+
+func (localTarget) RemoveAll(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", path, err)
+	}
+	return nil
+}
+
+// sshTarget manages releases on a remote host over SSH
+type sshTarget struct {
+	destination string
+}
+
+func (sshTarget) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (t sshTarget) run(script string) (string, error) {
+	cmd := exec.Command("ssh", "-o", "BatchMode=yes", t.destination, script)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("ssh %s failed: %v\nOutput: %s", t.destination, err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+func (t sshTarget) MkdirAll(dir string) error {
+	_, err := t.run("mkdir -p " + shellQuote(dir))
+	return err
+}
+
+func (t sshTarget) Exists(path string) (bool, error) {
+	output, err := t.run(fmt.Sprintf("if [ -e %s ]; then echo yes; fi", shellQuote(path)))
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) == "yes", nil
+}
+
+func (t sshTarget) List(dir string) ([]string, error) {
+	output, err := t.run(fmt.Sprintf("if [ -d %[1]s ]; then cd %[1]s && for d in */; do [ -d \"$d\" ] && echo \"${d%%/}\"; done; fi", shellQuote(dir)))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+func (t sshTarget) Readlink(link string) (string, error) {
+	output, err := t.run(fmt.Sprintf("if [ -L %[1]s ]; then readlink %[1]s; fi", shellQuote(link)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Symlink switches the link with mv -T, which renames over the old link
+// atomically instead of moving into the directory it points at
+func (t sshTarget) Symlink(target, link string) error {
+	tmp := link + ".tmp"
+	_, err := t.run(fmt.Sprintf("ln -sfn %s %s && mv -Tf %s %s",
+		shellQuote(target), shellQuote(tmp), shellQuote(tmp), shellQuote(link)))
+	return err
+}
+
+func (t sshTarget) RemoveAll(path string) error {
+	_, err := t.run("rm -rf " + shellQuote(path))
+	return err
+}
+
+// sshDestination returns user@host, or host when no user is set
+func sshDestination(user, host string) string {
+	if user == "" {
+		return host
+	}
+	return user + "@" + host
+}
+
+// shellQuote quotes a value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
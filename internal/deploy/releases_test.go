@@ -0,0 +1,160 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// fakeRsync puts an rsync on PATH that copies the source into the destination
+func fakeRsync(t *testing.T) {
+	t.Helper()
+
+	bin := t.TempDir()
+	script := `#!/bin/sh
+for arg; do src=$dst; dst=$arg; done
+mkdir -p "$dst" && cp -R "$src". "$dst"
+`
+	if err := os.WriteFile(filepath.Join(bin, "rsync"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// deploySite deploys a site whose index.html contains body as release id
+func deploySite(t *testing.T, target, id, body string, keep int) *DeploymentResult {
+	t.Helper()
+
+	output := t.TempDir()
+	if err := os.WriteFile(filepath.Join(output, "index.html"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := internal.GetProjectLayout()
+	layout := internal.DefaultProjectLayout()
+	layout.OutputDir = output
+	internal.SetProjectLayout(layout)
+	t.Cleanup(func() { internal.SetProjectLayout(previous) })
+
+	result, err := NewRsyncDeployer().Deploy(DeploymentConfig{
+		Strategy:     RsyncStrategy,
+		RsyncPath:    target,
+		Releases:     true,
+		KeepReleases: keep,
+		DeploymentID: id,
+	})
+	if err != nil {
+		t.Fatalf("deploy %s failed: %v", id, err)
+	}
+	return result
+}
+
+func readCurrent(t *testing.T, target string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(target, CurrentLink, "index.html"))
+	if err != nil {
+		t.Fatalf("failed to read the live site: %v", err)
+	}
+	return string(data)
+}
+
+func TestRsyncReleaseDeployAndRollback(t *testing.T) {
+	fakeRsync(t)
+	target := t.TempDir()
+
+	first := deploySite(t, target, "deploy_1", "first", 5)
+	if first.Release == nil || first.Release.Name != "deploy_1" || first.Release.Path != target {
+		t.Fatalf("unexpected release: %+v", first.Release)
+	}
+	deploySite(t, target, "deploy_2", "second", 5)
+
+	if got := readCurrent(t, target); got != "second" {
+		t.Fatalf("live site = %q, want second", got)
+	}
+	link, err := os.Readlink(filepath.Join(target, CurrentLink))
+	if err != nil || link != "releases/deploy_2" {
+		t.Fatalf("current link = %q (%v), want a relative link to releases/deploy_2", link, err)
+	}
+
+	if err := RollbackRelease(*first.Release); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if got := readCurrent(t, target); got != "first" {
+		t.Fatalf("live site after rollback = %q, want first", got)
+	}
+	if live, err := CurrentRelease(*first.Release); err != nil || live != "deploy_1" {
+		t.Fatalf("CurrentRelease = %q (%v), want deploy_1", live, err)
+	}
+
+	missing := *first.Release
+	missing.Name = "deploy_0"
+	if err := RollbackRelease(missing); err == nil {
+		t.Fatal("expected rollback to a missing release to fail")
+	}
+	if got := readCurrent(t, target); got != "first" {
+		t.Fatalf("failed rollback changed the live site to %q", got)
+	}
+}
+
+func TestPruneReleasesKeepsLiveRelease(t *testing.T) {
+	target := t.TempDir()
+	for _, name := range []string{"deploy_1", "deploy_2", "deploy_3", "deploy_4"} {
+		if err := os.MkdirAll(filepath.Join(target, ReleasesDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	local := localTarget{}
+	if err := activateRelease(local, target, "deploy_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := pruneReleases(local, target, 2)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if want := []string{"deploy_2"}; !reflect.DeepEqual(removed, want) {
+		t.Fatalf("removed %v, want %v", removed, want)
+	}
+
+	remaining, err := local.List(filepath.Join(target, ReleasesDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"deploy_1", "deploy_3", "deploy_4"}; !reflect.DeepEqual(remaining, want) {
+		t.Fatalf("remaining releases %v, want %v", remaining, want)
+	}
+}
+
+func TestGetRollbackRelease(t *testing.T) {
+	history := &DeploymentHistory{filePath: filepath.Join(t.TempDir(), "history.json")}
+	config := DeploymentConfig{Strategy: RsyncStrategy}
+
+	for _, name := range []string{"deploy_1", "deploy_2", "deploy_3"} {
+		config.DeploymentID = name
+		result := &DeploymentResult{
+			Success:  name != "deploy_2",
+			Strategy: RsyncStrategy,
+			Release:  &ReleaseInfo{Path: "/srv/site", Name: name},
+		}
+		if err := history.AddRecord(result, config); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	record, err := history.GetRollbackRelease(ReleaseInfo{Path: "/srv/site", Name: "deploy_3"})
+	if err != nil {
+		t.Fatalf("GetRollbackRelease failed: %v", err)
+	}
+	if record.ID != "deploy_1" {
+		t.Fatalf("rollback target = %s, want deploy_1 (deploy_2 failed)", record.ID)
+	}
+
+	if _, err := history.GetRollbackRelease(ReleaseInfo{Path: "/srv/other", Name: "deploy_3"}); err == nil {
+		t.Fatal("expected no release for another target")
+	}
+}
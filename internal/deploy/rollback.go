@@ -0,0 +1,176 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GitRollback configures the rollback of a git deployment
+type GitRollback struct {
+	Remote  string // defaults to the remote of the record, then origin
+	Branch  string // deploy branch, defaults to the branch of the record
+	Force   bool   // force push the recorded commit instead of committing a revert
+	DryRun  bool
+	Verbose bool
+
+	HistoryLimit int // records kept in the history, defaults to DefaultHistoryLimit
+}
+
+// RollbackGit deploys the commit of a previous git deployment again. By
+// default it commits the tree of that commit on top of the deploy branch,
+// reverting everything deployed since, and pushes it. With Force the recorded
+// commit itself is force pushed to the deploy branch. The rollback is added
+// to the deployment history, linked to the record it restored.
+func RollbackGit(record *DeploymentRecord, rollback GitRollback) (*DeploymentResult, error) {
+	result := &DeploymentResult{Strategy: GitStrategy}
+	start := time.Now()
+
+	if rollback.Remote == "" {
+		rollback.Remote = record.GitRemote
+	}
+	if rollback.Remote == "" {
+		rollback.Remote = "origin"
+	}
+	if rollback.Branch == "" {
+		rollback.Branch = record.GitBranch
+	}
+
+	config := DeploymentConfig{
+		Strategy:     GitStrategy,
+		Target:       record.Target,
+		Environment:  record.Environment,
+		HistoryLimit: rollback.HistoryLimit,
+		DryRun:       rollback.DryRun,
+		Verbose:      rollback.Verbose,
+		GitRemote:    rollback.Remote,
+		GitBranch:    rollback.Branch,
+		RollbackOf:   record.ID,
+	}
+
+	err := rollbackGit(record, rollback, config, result)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		result.Success = true
+	}
+
+	if !rollback.DryRun {
+		history, histErr := NewDeploymentHistory()
+		if histErr == nil {
+			histErr = history.AddRecord(result, config)
+		}
+		if histErr != nil && rollback.Verbose {
+			fmt.Printf("Warning: Failed to record rollback in deployment history: %v\n", histErr)
+		}
+	}
+
+	return result, err
+}
+
+// rollbackGit performs the rollback and fills in the result
+func rollbackGit(record *DeploymentRecord, rollback GitRollback, config DeploymentConfig, result *DeploymentResult) error {
+	// The same checks as a deployment, including a clean working tree
+	if err := NewGitDeployer().Validate(config); err != nil {
+		return err
+	}
+
+	if record.GitCommit == "" {
+		return errors.New("no Git commit information available for rollback")
+	}
+	target, err := gitOutput("rev-parse", "--verify", "--quiet", record.GitCommit+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("commit %s is not available locally - run 'git fetch %s' and try again", record.GitCommit, rollback.Remote)
+	}
+
+	current, err := getCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %v", err)
+	}
+	branch := rollback.Branch
+	if branch == "" {
+		branch = current
+	}
+	result.GitRemote = rollback.Remote
+	result.GitBranch = branch
+
+	if rollback.Force {
+		return forcePushCommit(target, branch, rollback, result)
+	}
+
+	if branch != current {
+		return fmt.Errorf("the deploy branch %s is not checked out - switch to it or use --force to push commit %s directly", branch, shortCommit(target))
+	}
+
+	headTree, err := gitOutput("rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return err
+	}
+	targetTree, err := gitOutput("rev-parse", target+"^{tree}")
+	if err != nil {
+		return err
+	}
+	if headTree == targetTree {
+		return fmt.Errorf("%s already matches deployment %s - nothing to roll back", branch, record.ID)
+	}
+
+	if rollback.DryRun {
+		result.Messages = append(result.Messages, fmt.Sprintf("Would commit the content of %s on %s and push to %s/%s", shortCommit(target), branch, rollback.Remote, branch))
+		return nil
+	}
+
+	// A commit with the tree of the target reverts every later change at
+	// once, including merges, without conflicts
+	message := fmt.Sprintf("Roll back to deployment %s\n\nRestores the content of commit %s.", record.ID, target)
+	revert, err := gitOutput("commit-tree", targetTree, "-p", "HEAD", "-m", message)
+	if err != nil {
+		return err
+	}
+	if _, err := gitOutput("merge", "--ff-only", "--quiet", revert); err != nil {
+		return err
+	}
+	result.GitCommit = revert
+	result.Messages = append(result.Messages, fmt.Sprintf("Created revert commit %s on %s", shortCommit(revert), branch))
+
+	if rollback.Verbose {
+		fmt.Printf("Executing: git push %s %s\n", rollback.Remote, branch)
+	}
+	if _, err := gitOutput("push", rollback.Remote, branch); err != nil {
+		return fmt.Errorf("git push failed: %v", err)
+	}
+	result.Messages = append(result.Messages, fmt.Sprintf("Successfully pushed to %s/%s", rollback.Remote, branch))
+	return nil
+}
+
+// forcePushCommit replaces the remote deploy branch with commit
+func forcePushCommit(commit, branch string, rollback GitRollback, result *DeploymentResult) error {
+	refspec := fmt.Sprintf("%s:refs/heads/%s", commit, branch)
+
+	if rollback.DryRun {
+		result.Messages = append(result.Messages, fmt.Sprintf("Would force push %s to %s/%s", shortCommit(commit), rollback.Remote, branch))
+		return nil
+	}
+
+	if rollback.Verbose {
+		fmt.Printf("Executing: git push --force %s %s\n", rollback.Remote, refspec)
+	}
+	if _, err := gitOutput("push", "--force", rollback.Remote, refspec); err != nil {
+		return fmt.Errorf("git push failed: %v", err)
+	}
+
+	result.GitCommit = commit
+	result.Messages = append(result.Messages,
+		fmt.Sprintf("Force pushed %s to %s/%s", shortCommit(commit), rollback.Remote, branch),
+		fmt.Sprintf("Your local %s branch was not changed; reset it to %s/%s to continue from the rolled-back state", branch, rollback.Remote, branch),
+	)
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for messages
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
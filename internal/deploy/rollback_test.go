@@ -0,0 +1,198 @@
+package deploy
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a repository with a bare origin and makes it the working
+// directory for the rest of the test
+func gitRepo(t *testing.T) {
+	t.Helper()
+
+	root := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+
+	git(t, "init", "--quiet", "--bare", "origin.git")
+	git(t, "init", "--quiet", "-b", "main", "site")
+	if err := os.Chdir("site"); err != nil {
+		t.Fatal(err)
+	}
+	git(t, "remote", "add", "origin", "../origin.git")
+	writeFile(t, ".gitignore", ".garp/\n")
+}
+
+func git(t *testing.T, args ...string) string {
+	t.Helper()
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// commitSite commits index.html with content and returns the commit
+func commitSite(t *testing.T, content string) string {
+	t.Helper()
+
+	writeFile(t, "index.html", content)
+	git(t, "add", "-A")
+	git(t, "commit", "--quiet", "-m", content)
+	return git(t, "rev-parse", "HEAD")
+}
+
+func TestRollbackGitCreatesRevertCommit(t *testing.T) {
+	gitRepo(t)
+	first := commitSite(t, "first")
+	writeFile(t, "extra.html", "added later")
+	commitSite(t, "second")
+	git(t, "push", "--quiet", "origin", "main")
+
+	record := &DeploymentRecord{ID: "deploy_1", Strategy: "git", Success: true, GitCommit: first, GitBranch: "main"}
+	result, err := RollbackGit(record, GitRollback{})
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+
+	if got := git(t, "rev-parse", "HEAD^{tree}"); got != git(t, "rev-parse", first+"^{tree}") {
+		t.Fatal("HEAD does not have the content of the rolled back commit")
+	}
+	if _, err := os.Stat("extra.html"); !os.IsNotExist(err) {
+		t.Fatal("files added after the target commit should be removed")
+	}
+	if got := git(t, "rev-parse", "origin/main"); got != result.GitCommit {
+		t.Fatalf("origin/main = %s, want the revert commit %s", got, result.GitCommit)
+	}
+	if parents := git(t, "rev-list", "--count", "HEAD"); parents != "3" {
+		t.Fatalf("expected the revert to be a new commit on top of history, got %s commits", parents)
+	}
+
+	history, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := history.GetLatestDeployment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.RollbackOf != "deploy_1" || latest.GitCommit != result.GitCommit || latest.GitBranch != "main" {
+		t.Fatalf("unexpected rollback record: %+v", latest)
+	}
+
+	// Rolling back again has nothing to do
+	if _, err := RollbackGit(record, GitRollback{}); err == nil || !strings.Contains(err.Error(), "nothing to roll back") {
+		t.Fatalf("expected nothing to roll back, got %v", err)
+	}
+}
+
+func TestRollbackGitForcePush(t *testing.T) {
+	gitRepo(t)
+	first := commitSite(t, "first")
+	second := commitSite(t, "second")
+	git(t, "push", "--quiet", "origin", "main")
+
+	record := &DeploymentRecord{ID: "deploy_1", Strategy: "git", Success: true, GitCommit: first, GitBranch: "main"}
+	result, err := RollbackGit(record, GitRollback{Force: true})
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+
+	if remote := git(t, "ls-remote", "origin", "refs/heads/main"); !strings.HasPrefix(remote, first) {
+		t.Fatalf("origin main = %s, want %s", remote, first)
+	}
+	if got := git(t, "rev-parse", "HEAD"); got != second {
+		t.Fatal("a force push rollback must not move the local branch")
+	}
+	if result.GitCommit != first {
+		t.Fatalf("result commit = %s, want %s", result.GitCommit, first)
+	}
+}
+
+func TestRollbackGitRefusesDirtyTree(t *testing.T) {
+	gitRepo(t)
+	first := commitSite(t, "first")
+	commitSite(t, "second")
+	writeFile(t, "index.html", "uncommitted")
+
+	record := &DeploymentRecord{ID: "deploy_1", Strategy: "git", Success: true, GitCommit: first, GitBranch: "main"}
+	_, err := RollbackGit(record, GitRollback{})
+	if err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Fatalf("expected the dirty working tree to be refused, got %v", err)
+	}
+	if got := git(t, "rev-list", "--count", "HEAD"); got != "2" {
+		t.Fatal("a refused rollback must not create commits")
+	}
+}
+
+func TestRollbackGitUsesRecordedRemote(t *testing.T) {
+	gitRepo(t)
+	git(t, "init", "--quiet", "--bare", "../staging.git")
+	git(t, "remote", "add", "staging", "../staging.git")
+	first := commitSite(t, "first")
+	git(t, "push", "--quiet", "origin", "main")
+
+	// The staging deployment is recorded with its remote and branch
+	git(t, "checkout", "--quiet", "-b", "preview")
+	writeFile(t, "public/index.html", "second")
+	commitSite(t, "second")
+	result, err := NewManager().Deploy(DeploymentConfig{
+		Strategy:         GitStrategy,
+		GitRemote:        "staging",
+		GitBranch:        "preview",
+		Environment:      "staging",
+		SkipContentCheck: true,
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("deploy failed: %v", err)
+	}
+	history, err := NewDeploymentHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployed, err := history.GetLatestDeployment()
+	if err != nil || deployed.GitRemote != "staging" || deployed.GitBranch != "preview" {
+		t.Fatalf("unexpected deployment record %+v (%v)", deployed, err)
+	}
+
+	record := *deployed
+	record.ID, record.GitCommit = "deploy_1", first
+	if _, err := RollbackGit(&record, GitRollback{}); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if got := git(t, "rev-parse", "staging/preview^{tree}"); got != git(t, "rev-parse", first+"^{tree}") {
+		t.Error("the rollback was not pushed to the recorded remote and branch")
+	}
+	if got := git(t, "rev-parse", "origin/main"); got != first {
+		t.Errorf("origin/main moved to %s", got)
+	}
+	if branches := git(t, "ls-remote", "--heads", "origin"); strings.Contains(branches, "preview") {
+		t.Errorf("the rollback was pushed to origin: %s", branches)
+	}
+}
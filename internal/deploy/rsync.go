@@ -1,12 +1,15 @@
 package deploy
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/mattsafaii/garp/internal"
 )
 
 // RsyncDeployer implements Rsync-based deployment
@@ -29,8 +32,9 @@ func (r *RsyncDeployer) Validate(config DeploymentConfig) error {
 		return fmt.Errorf("rsync command not found: %v", err)
 	}
 
-	// Check required configuration
-	if config.RsyncHost == "" {
+	// Check required configuration; without a host the path is a local
+	// directory, which is only supported for release deployments
+	if config.RsyncHost == "" && !config.Releases {
 		return fmt.Errorf("rsync host is required")
 	}
 
@@ -39,12 +43,13 @@ func (r *RsyncDeployer) Validate(config DeploymentConfig) error {
 	}
 
 	// Check if source directory exists
-	if _, err := os.Stat("site/"); os.IsNotExist(err) {
-		return fmt.Errorf("source directory 'site/' does not exist - run 'garp build' first")
+	outputDir := internal.GetProjectLayout().OutputDir
+	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+		return fmt.Errorf("output directory '%s/' does not exist - run 'garp build' first", outputDir)
 	}
 
 	// Test SSH connection if user is specified and validation is not skipped
-	if config.RsyncUser != "" && !config.SkipValidation {
+	if config.RsyncHost != "" && config.RsyncUser != "" && !config.SkipValidation {
 		target := fmt.Sprintf("%s@%s", config.RsyncUser, config.RsyncHost)
 		if err := testSSHConnection(target); err != nil {
 			return fmt.Errorf("SSH connection test failed: %v", err)
@@ -62,7 +67,7 @@ func (r *RsyncDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, erro
 	start := time.Now()
 
 	if config.Verbose {
-		fmt.Printf("🚀 Starting Rsync deployment to %s\n", config.RsyncHost)
+		fmt.Printf("🚀 Starting Rsync deployment to %s\n", sshDestination(config.RsyncUser, config.RsyncHost)+":"+config.RsyncPath)
 	}
 
 	// Validate first
@@ -79,27 +84,95 @@ func (r *RsyncDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, erro
 		"--delete", // delete files that don't exist in source
 	}
 
-	// Add exclusions
-	defaultExcludes := []string{
-		".git/",
-		".DS_Store",
-		".env",
-		"*.log",
+	// Without releases the target holds the last deployment, so only the
+	// files that changed since then are transferred; removed files are
+	// listed too and deleted by --delete-missing-args. Pages that became
+	// drafts are still in the output, so only a full sync removes them.
+	incremental := config.Changes != nil && !config.Releases
+	if incremental && unpublishedRemoved(config.Changes, config.Unpublished) {
+		incremental = false
+		result.Messages = append(result.Messages, "Syncing everything to remove pages that are no longer published")
+	}
+	if incremental {
+		if config.Changes.Empty() {
+			result.Messages = append(result.Messages, "No changes to deploy")
+			result.Success = true
+			result.Duration = time.Since(start)
+			return result, nil
+		}
+
+		fileList, err := writeFileList(config.Changes.Files())
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			result.Duration = time.Since(start)
+			return result, err
+		}
+		defer os.Remove(fileList)
+
+		args = []string{
+			"-avz",
+			"--progress",
+			"--files-from=" + fileList,
+			"--delete-missing-args",
+		}
+		result.Messages = append(result.Messages, fmt.Sprintf("Transferring changes only: %s", config.Changes.Summary()))
 	}
 
-	excludes := append(defaultExcludes, config.RsyncExcludes...)
+	// Add exclusions. Excluded files are also left alone on the target,
+	// while unpublished pages are only hidden from the sender so that
+	// copies published earlier are deleted.
+	excludes := append(append([]string{}, defaultExcludes...), config.RsyncExcludes...)
 	for _, exclude := range excludes {
 		args = append(args, "--exclude", exclude)
 	}
+	for _, page := range config.Unpublished {
+		args = append(args, "--filter", "H "+page)
+	}
 
 	// Source and destination
-	source := "site/"
+	source := internal.GetProjectLayout().OutputDir + "/"
+
+	destPath := config.RsyncPath
+
+	// Release deployments sync into a new directory below releases/, hard
+	// linking unchanged files against the live release
+	var release *ReleaseInfo
+	var target releaseTarget
+	if config.Releases {
+		release = &ReleaseInfo{
+			Host: config.RsyncHost,
+			User: config.RsyncUser,
+			Path: config.RsyncPath,
+			Name: config.DeploymentID,
+		}
+		if release.Name == "" {
+			release.Name = generateDeploymentID()
+		}
+		target = newReleaseTarget(config.RsyncHost, config.RsyncUser)
 
-	var destination string
-	if config.RsyncUser != "" {
-		destination = fmt.Sprintf("%s@%s:%s", config.RsyncUser, config.RsyncHost, config.RsyncPath)
-	} else {
-		destination = fmt.Sprintf("%s:%s", config.RsyncHost, config.RsyncPath)
+		live, err := currentRelease(target, config.RsyncPath)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			result.Duration = time.Since(start)
+			return result, err
+		}
+		if live != "" {
+			args = append(args, "--link-dest=../"+live)
+		}
+
+		destPath = target.Join(config.RsyncPath, ReleasesDir, release.Name)
+		if !config.DryRun {
+			if err := target.MkdirAll(destPath); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				result.Duration = time.Since(start)
+				return result, err
+			}
+		}
+	}
+
+	destination := destPath + "/"
+	if config.RsyncHost != "" {
+		destination = sshDestination(config.RsyncUser, config.RsyncHost) + ":" + destination
 	}
 
 	args = append(args, source, destination)
@@ -126,7 +199,7 @@ func (r *RsyncDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, erro
 		errMsg := fmt.Sprintf("rsync failed: %v", err)
 		result.Errors = append(result.Errors, errMsg)
 		result.Duration = time.Since(start)
-		return result, fmt.Errorf(errMsg)
+		return result, errors.New(errMsg)
 	}
 
 	if config.DryRun {
@@ -135,6 +208,24 @@ func (r *RsyncDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, erro
 		result.Messages = append(result.Messages, fmt.Sprintf("Successfully synced to %s", destination))
 	}
 
+	if release != nil && !config.DryRun {
+		if err := activateRelease(target, config.RsyncPath, release.Name); err != nil {
+			errMsg := fmt.Sprintf("failed to activate release %s: %v", release.Name, err)
+			result.Errors = append(result.Errors, errMsg)
+			result.Duration = time.Since(start)
+			return result, errors.New(errMsg)
+		}
+		result.Release = release
+		result.Messages = append(result.Messages, fmt.Sprintf("Release %s is live at %s", release.Name, target.Join(config.RsyncPath, CurrentLink)))
+
+		removed, err := pruneReleases(target, config.RsyncPath, config.KeepReleases)
+		if err != nil {
+			result.Messages = append(result.Messages, fmt.Sprintf("⚠️  Failed to prune old releases: %v", err))
+		} else if len(removed) > 0 {
+			result.Messages = append(result.Messages, fmt.Sprintf("Removed %d old releases", len(removed)))
+		}
+	}
+
 	result.Success = true
 	result.Duration = time.Since(start)
 
@@ -147,6 +238,22 @@ func (r *RsyncDeployer) Deploy(config DeploymentConfig) (*DeploymentResult, erro
 
 // Helper functions
 
+// writeFileList writes files, one per line, to a temporary file for
+// rsync --files-from and returns its path
+func writeFileList(files []string) (string, error) {
+	list, err := os.CreateTemp("", "garp-files-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create file list: %v", err)
+	}
+	defer list.Close()
+
+	if _, err := list.WriteString(strings.Join(files, "\n") + "\n"); err != nil {
+		os.Remove(list.Name())
+		return "", fmt.Errorf("failed to write file list: %v", err)
+	}
+	return list.Name(), nil
+}
+
 func testSSHConnection(target string) error {
 	// Test SSH connection with a simple command
 	cmd := exec.Command("ssh", "-o", "ConnectTimeout=10", "-o", "BatchMode=yes", target, "echo 'connection test'")
@@ -159,11 +266,11 @@ func testSSHConnection(target string) error {
 	return nil
 }
 
-// GetSiteSize returns the size of the site directory
+// GetSiteSize returns the size of the output directory
 func GetSiteSize() (int64, error) {
 	var size int64
 
-	err := filepath.Walk("site", func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(internal.GetProjectLayout().OutputDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
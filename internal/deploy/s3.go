@@ -0,0 +1,452 @@
+package deploy
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// DefaultS3Region is used when no region is configured
+const DefaultS3Region = "us-east-1"
+
+// S3Deployer syncs the output directory to a bucket of S3 or an
+// S3-compatible object store such as MinIO
+type S3Deployer struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Deployer creates a new S3 deployer
+func NewS3Deployer() *S3Deployer {
+	return &S3Deployer{
+		client: &http.Client{Timeout: 5 * time.Minute},
+		now:    time.Now,
+	}
+}
+
+// Name returns the deployer name
+func (s *S3Deployer) Name() string {
+	return "S3"
+}
+
+// Validate checks if an S3 deployment is possible
+func (s *S3Deployer) Validate(config DeploymentConfig) error {
+	if config.S3Bucket == "" {
+		return fmt.Errorf("a bucket is required - set deploy.bucket in garp.toml or pass --bucket")
+	}
+
+	if config.S3AccessKeyID == "" || config.S3SecretAccessKey == "" {
+		return fmt.Errorf("S3 credentials are required - set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	if _, err := s.endpoint(config); err != nil {
+		return err
+	}
+
+	outputDir := internal.GetProjectLayout().OutputDir
+	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+		return fmt.Errorf("output directory '%s/' does not exist - run 'garp build' first", outputDir)
+	}
+
+	return nil
+}
+
+// s3Object is an object listed in the bucket
+type s3Object struct {
+	Key  string `xml:"Key"`
+	ETag string `xml:"ETag"`
+}
+
+// Deploy uploads new and changed files and deletes objects below the prefix
+// that are no longer part of the site
+func (s *S3Deployer) Deploy(config DeploymentConfig) (*DeploymentResult, error) {
+	result := &DeploymentResult{
+		Strategy: S3Strategy,
+	}
+	start := time.Now()
+
+	fail := func(err error) (*DeploymentResult, error) {
+		result.Errors = append(result.Errors, err.Error())
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	if err := s.Validate(config); err != nil {
+		return fail(err)
+	}
+
+	prefix := s3Prefix(config.S3Prefix)
+	target := "s3://" + path.Join(config.S3Bucket, prefix)
+	if config.Verbose {
+		fmt.Printf("🚀 Starting S3 deployment to %s\n", target)
+	}
+
+	// Single part uploads have the MD5 of their content as ETag, which
+	// tells which files are unchanged
+	outputDir := internal.GetProjectLayout().OutputDir
+	excludes := deployExcludes(config)
+	manifest, _, err := buildManifest(outputDir, excludes, md5.New)
+	if err != nil {
+		return fail(err)
+	}
+
+	remote, err := s.list(config, prefix)
+	if err != nil {
+		return fail(fmt.Errorf("failed to list %s: %v", target, err))
+	}
+
+	var uploads, deletes []string
+	for rel, sum := range manifest {
+		if strings.Trim(remote[prefix+rel], `"`) != sum {
+			uploads = append(uploads, rel)
+		}
+	}
+	for key := range remote {
+		if _, ok := manifest[strings.TrimPrefix(key, prefix)]; !ok {
+			deletes = append(deletes, key)
+		}
+	}
+	sort.Strings(uploads)
+	sort.Strings(deletes)
+
+	if config.DryRun {
+		for _, rel := range uploads {
+			result.Messages = append(result.Messages, fmt.Sprintf("Would upload %s", rel))
+		}
+		for _, key := range deletes {
+			result.Messages = append(result.Messages, fmt.Sprintf("Would delete %s", key))
+		}
+		result.Messages = append(result.Messages, fmt.Sprintf("Dry run completed - would upload %d and delete %d objects in %s", len(uploads), len(deletes), target))
+		result.Success = true
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	for _, rel := range uploads {
+		if config.Verbose {
+			fmt.Printf("Uploading %s\n", rel)
+		}
+		if err := s.put(config, prefix+rel, filepath.Join(outputDir, filepath.FromSlash(rel))); err != nil {
+			return fail(fmt.Errorf("failed to upload %s: %v", rel, err))
+		}
+	}
+
+	// Delete stale objects only once the new site is complete
+	for _, key := range deletes {
+		if config.Verbose {
+			fmt.Printf("Deleting %s\n", key)
+		}
+		if err := s.do(config, http.MethodDelete, key, nil, nil, nil); err != nil {
+			return fail(fmt.Errorf("failed to delete %s: %v", key, err))
+		}
+	}
+
+	result.Messages = append(result.Messages, fmt.Sprintf("Synced %d files to %s: %d uploaded, %d unchanged, %d deleted",
+		len(manifest), target, len(uploads), len(manifest)-len(uploads), len(deletes)))
+	result.Success = true
+	result.Duration = time.Since(start)
+
+	if config.Verbose {
+		fmt.Printf("✅ S3 deployment completed in %v\n", result.Duration)
+	}
+
+	return result, nil
+}
+
+// list returns the ETag of every object below prefix, keyed by object key
+func (s *S3Deployer) list(config DeploymentConfig, prefix string) (map[string]string, error) {
+	objects := make(map[string]string)
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		var page struct {
+			Contents              []s3Object `xml:"Contents"`
+			IsTruncated           bool       `xml:"IsTruncated"`
+			NextContinuationToken string     `xml:"NextContinuationToken"`
+		}
+		if err := s.do(config, http.MethodGet, "", query, nil, &page); err != nil {
+			return nil, err
+		}
+
+		for _, object := range page.Contents {
+			objects[object.Key] = object.ETag
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// put uploads a file with the content type and cache policy of its type
+func (s *S3Deployer) put(config DeploymentConfig, key, file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", ContentType(key))
+	headers.Set("Cache-Control", CacheControl(key))
+	return s.do(config, http.MethodPut, key, nil, content, nil, headers)
+}
+
+// do sends a signed request for an object key, or for the bucket when key is
+// empty, and decodes an XML response into out
+func (s *S3Deployer) do(config DeploymentConfig, method, key string, query url.Values, body []byte, out interface{}, headers ...http.Header) error {
+	endpoint, err := s.endpoint(config)
+	if err != nil {
+		return err
+	}
+
+	// Path-style addressing works with both AWS and MinIO
+	u := *endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + config.S3Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = s3Escape(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for _, h := range headers {
+		for name := range h {
+			req.Header.Set(name, h.Get(name))
+		}
+	}
+
+	signS3Request(req, body, s3Credentials{
+		AccessKeyID:     config.S3AccessKeyID,
+		SecretAccessKey: config.S3SecretAccessKey,
+		SessionToken:    config.S3SessionToken,
+		Region:          s3Region(config),
+	}, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var s3Err struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+			return fmt.Errorf("%s: %s (%s)", s3Err.Code, s3Err.Message, resp.Status)
+		}
+		return fmt.Errorf("%s %s returned %s", method, u.Path, resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from %s: %v", u.Path, err)
+	}
+	return nil
+}
+
+// endpoint returns the configured endpoint, or the AWS endpoint of the region
+func (s *S3Deployer) endpoint(config DeploymentConfig) (*url.URL, error) {
+	raw := config.S3Endpoint
+	if raw == "" {
+		raw = fmt.Sprintf("https://s3.%s.amazonaws.com", s3Region(config))
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q - use a URL such as http://localhost:9000", raw)
+	}
+	return u, nil
+}
+
+func s3Region(config DeploymentConfig) string {
+	if config.S3Region == "" {
+		return DefaultS3Region
+	}
+	return config.S3Region
+}
+
+// s3Prefix normalizes a key prefix to "" or "dir/"
+func s3Prefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
+// ContentType returns the Content-Type an object is served with
+func ContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	switch ext {
+	case ".html", ".htm":
+		return "text/html; charset=utf-8"
+	case ".md":
+		return "text/markdown; charset=utf-8"
+	case ".webmanifest":
+		return "application/manifest+json"
+	case ".pf_meta", ".pf_index", ".pf_fragment", ".pagefind":
+		return "application/octet-stream"
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// CacheControl returns the Cache-Control an object is served with. Pages
+// are revalidated on every request so deployments show up immediately;
+// stylesheets and scripts keep their names between builds, so they are
+// cached briefly, while images, fonts and media are cached for a day.
+func CacheControl(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm", ".xml", ".json", ".txt", ".md", ".webmanifest":
+		return "public, max-age=0, must-revalidate"
+	case ".css", ".js", ".mjs":
+		return "public, max-age=3600"
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".svg", ".ico",
+		".woff", ".woff2", ".ttf", ".otf", ".mp4", ".webm", ".mp3", ".pdf":
+		return "public, max-age=86400"
+	default:
+		return "public, max-age=3600"
+	}
+}
+
+// s3Credentials sign requests with AWS Signature Version 4
+type s3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+}
+
+// signS3Request adds the AWS Signature Version 4 headers to req. Every
+// header already set on the request is signed.
+func signS3Request(req *http.Request, body []byte, creds s3Credentials, now time.Time) {
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3Escape(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + creds.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, creds.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by name, as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes everything except unreserved characters, and
+// slashes unless encodeSlash is set
+func s3Escape(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -8,6 +8,8 @@ type DeploymentStrategy int
 const (
 	GitStrategy DeploymentStrategy = iota
 	RsyncStrategy
+	NetlifyStrategy
+	S3Strategy
 )
 
 func (s DeploymentStrategy) String() string {
@@ -16,6 +18,10 @@ func (s DeploymentStrategy) String() string {
 		return "git"
 	case RsyncStrategy:
 		return "rsync"
+	case NetlifyStrategy:
+		return "netlify"
+	case S3Strategy:
+		return "s3"
 	default:
 		return "unknown"
 	}
@@ -25,11 +31,17 @@ func (s DeploymentStrategy) String() string {
 type DeploymentConfig struct {
 	Strategy         DeploymentStrategy
 	Target           string
+	Environment      string // named environment deployed to with --env
 	DryRun           bool
 	Verbose          bool
 	BuildFirst       bool
+	SkipSearch       bool
+	BaseURL          string // site URL used for the sitemap of static builds
 	SkipValidation   bool
 	SkipContentCheck bool
+	AllowUnpublished bool     // let git deployments push committed drafts and scheduled pages
+	Unpublished      []string // drafts and scheduled pages left out, as patterns anchored at the output directory
+	GarpVersion      string   // version of garp recorded with the deployment
 
 	// Git-specific config
 	GitRemote string
@@ -40,11 +52,38 @@ type DeploymentConfig struct {
 	RsyncUser     string
 	RsyncPath     string
 	RsyncExcludes []string
+	Releases      bool // deploy into releases/<id>/ and switch the current symlink
+	KeepReleases  int  // number of releases to keep, defaults to DefaultKeepReleases
+
+	// DeploymentID names the deployment in history and on release targets
+	DeploymentID string
+	HistoryLimit int // records kept in the history, defaults to DefaultHistoryLimit
+
+	// RollbackOf is the ID of the deployment a rollback restores
+	RollbackOf string
+
+	// Changes lists the files that differ from the last deployment to the
+	// target; nil when unknown, in which case everything is transferred
+	Changes  *ManifestDiff
+	FullSync bool // ignore the last deployed manifest and transfer everything
+
+	// Hooks are shell commands run at the stages of the deployment
+	Hooks Hooks
 
 	// Static hosting config
+	APIURL    string // API base URL, defaults to the Netlify API
 	APIKey    string
 	ProjectID string
 	SiteID    string
+
+	// S3-specific config
+	S3Bucket          string
+	S3Prefix          string
+	S3Region          string
+	S3Endpoint        string // endpoint of an S3-compatible store, e.g. MinIO
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3SessionToken    string
 }
 
 // DeploymentResult contains information about a completed deployment
@@ -56,6 +95,12 @@ type DeploymentResult struct {
 	URL           string
 	Errors        []string
 	Messages      []string
+	Release       *ReleaseInfo // release created by a release deployment
+	GitCommit     string       // commit that was pushed, when it is not HEAD
+	GitRemote     string       // remote that was pushed to
+	GitBranch     string       // branch that was pushed
+	BuildInfo     *BuildInfo   // tool versions and manifest of the deployed output
+	Hooks         []HookResult // hook commands run during the deployment
 }
 
 // Deployer interface for different deployment strategies
@@ -8,6 +8,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/content"
 )
 
 // ValidationOptions configures deployment validation
@@ -17,6 +20,8 @@ type ValidationOptions struct {
 	CheckFileSize bool
 	MaxFileSize   int64 // in bytes
 	RequiredFiles []string
+	IndexFiles    []string // at least one of these must exist
+	ContentDir    string   // source directory whose pages' frontmatter is checked
 	Verbose       bool
 }
 
@@ -87,7 +92,7 @@ func ValidateDeployment(sourceDir string, options ValidationOptions) (*Validatio
 
 		// Validate HTML files
 		if strings.HasSuffix(strings.ToLower(path), ".html") || strings.HasSuffix(strings.ToLower(path), ".htm") {
-			if err := validateHTMLFile(path, options, result); err != nil {
+			if err := validateHTMLFile(sourceDir, path, options, result); err != nil {
 				if options.Verbose {
 					fmt.Printf("Warning: Could not validate %s: %v\n", path, err)
 				}
@@ -101,6 +106,11 @@ func ValidateDeployment(sourceDir string, options ValidationOptions) (*Validatio
 		return nil, fmt.Errorf("error walking directory: %v", err)
 	}
 
+	// Check page frontmatter in the source directory
+	if options.ContentDir != "" {
+		validateContent(options.ContentDir, result)
+	}
+
 	// Check for required files
 	for _, requiredFile := range options.RequiredFiles {
 		fullPath := filepath.Join(sourceDir, requiredFile)
@@ -115,6 +125,25 @@ func ValidateDeployment(sourceDir string, options ValidationOptions) (*Validatio
 		}
 	}
 
+	// Check that the site has a homepage
+	if len(options.IndexFiles) > 0 {
+		found := false
+		for _, indexFile := range options.IndexFiles {
+			if _, err := os.Stat(filepath.Join(sourceDir, indexFile)); err == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.Issues = append(result.Issues, ValidationIssue{
+				Type:     "error",
+				Category: "file",
+				Message:  fmt.Sprintf("Required file missing: %s", strings.Join(options.IndexFiles, " or ")),
+				File:     sourceDir,
+			})
+		}
+	}
+
 	// Check if there are any errors
 	for _, issue := range result.Issues {
 		if issue.Type == "error" {
@@ -130,8 +159,40 @@ func ValidateDeployment(sourceDir string, options ValidationOptions) (*Validatio
 	return result, nil
 }
 
+// validateContent reports pages whose frontmatter cannot be parsed
+func validateContent(contentDir string, result *ValidationResult) {
+	filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != contentDir && content.Hidden(info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !content.IsMarkdown(path) {
+			return nil
+		}
+
+		if _, err := content.LoadPage(contentDir, path); err != nil {
+			message := err.Error()
+			if appErr, ok := err.(*internal.AppError); ok {
+				message = appErr.Message
+			}
+			result.Issues = append(result.Issues, ValidationIssue{
+				Type:     "error",
+				Category: "content",
+				Message:  fmt.Sprintf("Invalid page: %s", message),
+				File:     path,
+			})
+		}
+		return nil
+	})
+}
+
 // validateHTMLFile validates an individual HTML file
-func validateHTMLFile(filePath string, options ValidationOptions, result *ValidationResult) error {
+func validateHTMLFile(siteRoot, filePath string, options ValidationOptions, result *ValidationResult) error {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return err
@@ -147,19 +208,19 @@ func validateHTMLFile(filePath string, options ValidationOptions, result *Valida
 
 	// Check for broken internal links
 	if options.CheckLinks {
-		validateLinks(filePath, htmlContent, result)
+		validateLinks(siteRoot, filePath, htmlContent, result)
 	}
 
 	// Check for missing images
 	if options.CheckImages {
-		validateImages(filePath, htmlContent, result)
+		validateImages(siteRoot, filePath, htmlContent, result)
 	}
 
 	return nil
 }
 
 // validateLinks checks for broken internal links in HTML content
-func validateLinks(filePath, content string, result *ValidationResult) {
+func validateLinks(siteRoot, filePath, content string, result *ValidationResult) {
 	// Regex to find href attributes
 	linkRegex := regexp.MustCompile(`href=["']([^"']+)["']`)
 	matches := linkRegex.FindAllStringSubmatch(content, -1)
@@ -198,7 +259,6 @@ func validateLinks(filePath, content string, result *ValidationResult) {
 		var targetPath string
 		if filepath.IsAbs(linkPath) {
 			// Absolute path relative to site root
-			siteRoot := filepath.Dir(filepath.Dir(filePath))   // Assuming filePath is like "site/page.html"
 			targetPath = filepath.Join(siteRoot, linkPath[1:]) // Remove leading slash
 		} else {
 			// Relative path
@@ -206,14 +266,7 @@ func validateLinks(filePath, content string, result *ValidationResult) {
 		}
 
 		// Check if target exists
-		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
-			// Also try with .html extension
-			if !strings.HasSuffix(targetPath, ".html") {
-				if _, err := os.Stat(targetPath + ".html"); err == nil {
-					continue // Found with .html extension
-				}
-			}
-
+		if !linkTargetExists(targetPath) {
 			result.Issues = append(result.Issues, ValidationIssue{
 				Type:     "warning",
 				Category: "link",
@@ -225,7 +278,7 @@ func validateLinks(filePath, content string, result *ValidationResult) {
 }
 
 // validateImages checks for missing images in HTML content
-func validateImages(filePath, content string, result *ValidationResult) {
+func validateImages(siteRoot, filePath, content string, result *ValidationResult) {
 	// Regex to find src attributes in img tags
 	imgRegex := regexp.MustCompile(`<img[^>]+src=["']([^"']+)["']`)
 	matches := imgRegex.FindAllStringSubmatch(content, -1)
@@ -250,8 +303,7 @@ func validateImages(filePath, content string, result *ValidationResult) {
 		var targetPath string
 		if filepath.IsAbs(src) {
 			// Absolute path relative to site root
-			siteRoot := filepath.Dir(filepath.Dir(filePath)) // Assuming filePath is like "site/page.html"
-			targetPath = filepath.Join(siteRoot, src[1:])    // Remove leading slash
+			targetPath = filepath.Join(siteRoot, src[1:]) // Remove leading slash
 		} else {
 			// Relative path
 			targetPath = filepath.Join(baseDir, src)
@@ -269,17 +321,45 @@ func validateImages(filePath, content string, result *ValidationResult) {
 	}
 }
 
+// linkTargetExists checks a link target the way the server resolves it:
+// the exact file, then .html and .md variants, then a directory index
+func linkTargetExists(targetPath string) bool {
+	candidates := []string{
+		targetPath,
+		targetPath + ".html",
+		targetPath + ".md",
+		filepath.Join(targetPath, "index.html"),
+		filepath.Join(targetPath, "index.md"),
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDefaultValidationOptions returns recommended validation options
 func GetDefaultValidationOptions() ValidationOptions {
-	return ValidationOptions{
+	options := ValidationOptions{
 		CheckLinks:    true,
 		CheckImages:   true,
 		CheckFileSize: true,
 		MaxFileSize:   10 * 1024 * 1024, // 10MB
-		RequiredFiles: []string{
+		IndexFiles: []string{
 			"index.html",
-			"style.css",
+			"index.md",
 		},
 		Verbose: false,
 	}
+
+	// The compiled stylesheet must be shipped with the site
+	layout := internal.GetProjectLayout()
+	options.ContentDir = layout.SourceDir
+	if stylesheet, err := layout.OutputPath(layout.CSSOutput); err == nil {
+		options.RequiredFiles = append(options.RequiredFiles, stylesheet)
+	}
+
+	return options
 }
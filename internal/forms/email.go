@@ -0,0 +1,279 @@
+package forms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// DefaultResendURL is the Resend endpoint for sending email
+const DefaultResendURL = "https://api.resend.com/emails"
+
+// Email backends selected with forms.email_backend
+const (
+	ResendBackend = "resend"
+	SMTPBackend   = "smtp"
+	FileBackend   = "file"
+)
+
+// Email is a message to deliver
+type Email struct {
+	To      string
+	From    string
+	Subject string
+	HTML    string
+	Text    string
+	ReplyTo string
+}
+
+// Sender delivers notification emails
+type Sender interface {
+	// Name describes the backend in logs and messages
+	Name() string
+	// Send delivers the email and returns an ID identifying it
+	Send(email Email) (string, error)
+}
+
+// NewSender creates the sender of the configured email backend. The error
+// explains why email delivery is unavailable.
+func NewSender(config Config) (Sender, error) {
+	switch config.EmailBackend {
+	case "", ResendBackend:
+		if config.ResendAPIKey == "" || strings.Contains(config.ResendAPIKey, "your_resend_api_key_here") {
+			return nil, errors.New("RESEND_API_KEY not configured")
+		}
+		if config.FromEmail == "" || config.ToEmail == "" {
+			return nil, errors.New("FORM_FROM_EMAIL and FORM_TO_EMAIL are required")
+		}
+		return NewResendClient(config.ResendAPIKey, config.ResendURL)
+	case SMTPBackend:
+		if config.FromEmail == "" || config.ToEmail == "" {
+			return nil, errors.New("FORM_FROM_EMAIL and FORM_TO_EMAIL are required")
+		}
+		return NewSMTPSender(config.SMTP)
+	case FileBackend:
+		return NewMailDirSender(config.MailDir)
+	default:
+		return nil, fmt.Errorf("unknown email backend %q (use %s, %s or %s)", config.EmailBackend, ResendBackend, SMTPBackend, FileBackend)
+	}
+}
+
+// ResendClient sends email through the Resend API
+type ResendClient struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+// NewResendClient creates a Resend client for the API key. An empty url
+// uses DefaultResendURL.
+func NewResendClient(apiKey, url string) (*ResendClient, error) {
+	if apiKey == "" {
+		return nil, errors.New("Resend API key is required")
+	}
+	if url == "" {
+		url = DefaultResendURL
+	}
+	return &ResendClient{
+		apiKey: apiKey,
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Name returns the name of the backend
+func (c *ResendClient) Name() string {
+	return "Resend"
+}
+
+// Send delivers the email and returns the Resend email ID
+func (c *ResendClient) Send(email Email) (string, error) {
+	if email.HTML == "" && email.Text == "" {
+		return "", errors.New("either html or text content is required")
+	}
+
+	payload := map[string]interface{}{
+		"to":      []string{email.To},
+		"from":    email.From,
+		"subject": email.Subject,
+	}
+	if email.HTML != "" {
+		payload["html"] = email.HTML
+	}
+	if email.Text != "" {
+		payload["text"] = email.Text
+	}
+	if email.ReplyTo != "" {
+		payload["reply_to"] = []string{email.ReplyTo}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", errors.New("request timeout - please try again")
+		}
+		return "", errors.New("network error - unable to connect to Resend API")
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var reply struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		if err := json.Unmarshal(data, &reply); err != nil {
+			return "", fmt.Errorf("invalid JSON response from Resend API: %v", err)
+		}
+		return reply.ID, nil
+	case http.StatusBadRequest:
+		return "", fmt.Errorf("bad request: %s", resendMessage(data, "Bad request"))
+	case http.StatusUnauthorized:
+		return "", errors.New("unauthorized: invalid API key")
+	case http.StatusUnprocessableEntity:
+		return "", fmt.Errorf("validation error: %s", resendMessage(data, "Validation error"))
+	case http.StatusTooManyRequests:
+		return "", errors.New("rate limit exceeded")
+	default:
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+}
+
+// resendMessage returns the message of a Resend error response
+func resendMessage(data []byte, fallback string) string {
+	var reply struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &reply) != nil || reply.Message == "" {
+		return fallback
+	}
+	return reply.Message
+}
+
+// emailData is passed to the notification templates
+type emailData struct {
+	ProjectName  string
+	FormTitle    string
+	SubmissionID string
+	Timestamp    string
+	Fields       []emailField
+}
+
+type emailField struct {
+	Label     string
+	Value     string
+	Multiline bool
+}
+
+var htmlEmail = htmltemplate.Must(htmltemplate.New("email").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>{{.FormTitle}} Form Submission</title>
+  <style>
+    body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+    .header { background: #f8f9fa; padding: 20px; border-radius: 8px; margin-bottom: 20px; }
+    .content { background: white; padding: 20px; border: 1px solid #e9ecef; border-radius: 8px; }
+    .field { margin-bottom: 15px; }
+    .label { font-weight: 600; color: #495057; display: block; margin-bottom: 5px; }
+    .value { background: #f8f9fa; padding: 10px; border-radius: 4px; border-left: 3px solid #007bff; }
+    .message-content { white-space: pre-wrap; }
+    .footer { margin-top: 20px; padding: 15px; background: #f8f9fa; border-radius: 8px; font-size: 14px; color: #6c757d; }
+  </style>
+</head>
+<body>
+  <div class="header">
+    <h1 style="margin: 0; color: #007bff;">📧 New {{.FormTitle}} Form Submission</h1>
+    <p style="margin: 5px 0 0 0; color: #6c757d;">Received on {{.Timestamp}}</p>
+  </div>
+
+  <div class="content">
+{{- range .Fields}}
+    <div class="field">
+      <span class="label">{{.Label}}:</span>
+      <div class="value{{if .Multiline}} message-content{{end}}">{{.Value}}</div>
+    </div>
+{{- end}}
+  </div>
+
+  <div class="footer">
+    <p><strong>Submission ID:</strong> {{.SubmissionID}}</p>
+    <p><strong>Source:</strong> {{.ProjectName}} {{.FormTitle}} Form</p>
+  </div>
+</body>
+</html>`))
+
+var textEmail = texttemplate.Must(texttemplate.New("email").Funcs(texttemplate.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}).Parse(`NEW {{upper .FormTitle}} FORM SUBMISSION
+
+Received on: {{.Timestamp}}
+Submission ID: {{.SubmissionID}}
+
+{{range .Fields}}{{if .Multiline}}
+{{.Label}}:
+{{.Value}}
+
+{{else}}{{.Label}}: {{.Value}}
+{{end}}{{end}}
+---
+This message was sent via the {{.ProjectName}} {{lower .FormTitle}} form.`))
+
+// BuildSubmissionEmail renders the HTML and plain text notification for a
+// submission of the form
+func BuildSubmissionEmail(schema *Schema, data map[string]string, submissionID, projectName string, now time.Time) (string, string, error) {
+	values := emailData{
+		ProjectName:  projectName,
+		FormTitle:    schema.DisplayTitle(),
+		SubmissionID: submissionID,
+		Timestamp:    now.Format("January 02, 2006 at 03:04 PM MST"),
+	}
+	for _, field := range schema.Fields {
+		values.Fields = append(values.Fields, emailField{
+			Label:     field.DisplayLabel(),
+			Value:     valueOr(data[field.Name], "Not provided"),
+			Multiline: field.Type == TextareaField,
+		})
+	}
+
+	var html, text bytes.Buffer
+	if err := htmlEmail.Execute(&html, values); err != nil {
+		return "", "", err
+	}
+	if err := textEmail.Execute(&text, values); err != nil {
+		return "", "", err
+	}
+	return html.String(), text.String(), nil
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
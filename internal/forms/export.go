@@ -0,0 +1,87 @@
+package forms
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Export formats of stored submissions
+const (
+	CSVFormat  = "csv"
+	JSONFormat = "json"
+)
+
+// csvColumns are the leading columns of a CSV export, followed by the fields
+var csvColumns = []string{"id", "form", "received_at", "status", "email_error", "ip"}
+
+// Export writes submissions to w as CSV or JSON. A CSV export has a column
+// for every field used by the submissions, in order of first use.
+func Export(w io.Writer, format string, submissions []Submission) error {
+	switch format {
+	case CSVFormat:
+		return exportCSV(w, submissions)
+	case JSONFormat:
+		if submissions == nil {
+			submissions = []Submission{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(submissions)
+	default:
+		return fmt.Errorf("unknown export format %q, use %s or %s", format, CSVFormat, JSONFormat)
+	}
+}
+
+func exportCSV(w io.Writer, submissions []Submission) error {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, submission := range submissions {
+		// Map order is random, so new fields of a submission are sorted
+		var added []string
+		for name := range submission.Fields {
+			if !seen[name] {
+				seen[name] = true
+				added = append(added, name)
+			}
+		}
+		sort.Strings(added)
+		fields = append(fields, added...)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string{}, csvColumns...), fields...)); err != nil {
+		return err
+	}
+	for _, submission := range submissions {
+		row := []string{
+			submission.ID,
+			submission.Form,
+			submission.ReceivedAt.Format(time.RFC3339),
+			submission.Status,
+			submission.EmailError,
+			submission.IP,
+		}
+		for _, name := range fields {
+			row = append(row, csvValue(submission.Fields[name]))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvValue keeps spreadsheets from running submitted values as formulas by
+// prefixing them with a quote
+func csvValue(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package forms
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultMailDir is where the file backend writes emails, relative to the
+// project
+const DefaultMailDir = ".garp/mail"
+
+// MailDirSender writes emails into a Maildir for local development. Each
+// email is a complete message that mail clients can open.
+type MailDirSender struct {
+	dir string
+	now func() time.Time
+}
+
+// NewMailDirSender creates the Maildir at dir. An empty dir uses
+// DefaultMailDir.
+func NewMailDirSender(dir string) (*MailDirSender, error) {
+	if dir == "" {
+		dir = DefaultMailDir
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %v", err)
+		}
+	}
+	return &MailDirSender{dir: dir, now: time.Now}, nil
+}
+
+// Dir returns the Maildir emails are written to
+func (s *MailDirSender) Dir() string {
+	return s.dir
+}
+
+// Name returns the name of the backend
+func (s *MailDirSender) Name() string {
+	return "Maildir"
+}
+
+// Send writes the email to the new directory of the Maildir and returns its
+// file name
+func (s *MailDirSender) Send(email Email) (string, error) {
+	now := s.now()
+	message, id, err := buildMessage(email, now)
+	if err != nil {
+		return "", err
+	}
+
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	// Maildir file names must not contain '/' or ':'
+	host = strings.NewReplacer("/", "_", ":", "_").Replace(host)
+	name := fmt.Sprintf("%d.%s.%s", now.Unix(), id[:strings.Index(id, "@")], host)
+
+	// Deliver through tmp so readers never see a partial message
+	tmp := filepath.Join(s.dir, "tmp", name)
+	if err := os.WriteFile(tmp, message, 0644); err != nil {
+		return "", fmt.Errorf("failed to write email: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, "new", name)); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to deliver email: %v", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,168 @@
+package forms
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimits are the maximum submissions per client in each window
+type RateLimits struct {
+	PerMinute int `json:"per_minute"`
+	PerHour   int `json:"per_hour"`
+	PerDay    int `json:"per_day"`
+}
+
+// DefaultRateLimits match the limits of the original form server
+var DefaultRateLimits = RateLimits{PerMinute: 5, PerHour: 20, PerDay: 100}
+
+// cleanupInterval is how often submissions older than a day are dropped
+const cleanupInterval = 15 * time.Minute
+
+// Violation describes an exceeded rate limit
+type Violation struct {
+	Window string `json:"window"`
+	Count  int    `json:"count"`
+	Limit  int    `json:"limit"`
+}
+
+// RetryAfter returns how long the client should wait, in seconds
+func (v Violation) RetryAfter() int {
+	switch v.Window {
+	case "hour":
+		return 3600
+	case "day":
+		return 86400
+	default:
+		return 60
+	}
+}
+
+// RateCheck is the outcome of checking a client against the limits
+type RateCheck struct {
+	Allowed    bool
+	Violations []Violation
+	Minute     int
+	Hour       int
+	Day        int
+}
+
+// RateStats summarizes the tracked submissions
+type RateStats struct {
+	TotalIPs         int       `json:"total_ips"`
+	TotalSubmissions int       `json:"total_submissions"`
+	LastCleanup      time.Time `json:"last_cleanup"`
+}
+
+// RateLimiter tracks submissions per client IP in memory
+type RateLimiter struct {
+	limits RateLimits
+
+	mu          sync.Mutex
+	submissions map[string][]time.Time
+	lastCleanup time.Time
+}
+
+// NewRateLimiter creates a rate limiter enforcing limits
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return &RateLimiter{
+		limits:      limits,
+		submissions: make(map[string][]time.Time),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Limits returns the enforced limits
+func (r *RateLimiter) Limits() RateLimits {
+	return r.limits
+}
+
+// Check counts the recent submissions of ip and reports the limits they
+// exceed
+func (r *RateLimiter) Check(ip string, now time.Time) RateCheck {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.lastCleanup) > cleanupInterval {
+		r.cleanup(now)
+	}
+
+	timestamps := r.prune(ip, now)
+	check := RateCheck{
+		Minute: countSince(timestamps, now.Add(-time.Minute)),
+		Hour:   countSince(timestamps, now.Add(-time.Hour)),
+		Day:    countSince(timestamps, now.Add(-24*time.Hour)),
+	}
+
+	windows := []struct {
+		name  string
+		count int
+		limit int
+	}{
+		{"minute", check.Minute, r.limits.PerMinute},
+		{"hour", check.Hour, r.limits.PerHour},
+		{"day", check.Day, r.limits.PerDay},
+	}
+	for _, window := range windows {
+		if window.count >= window.limit {
+			check.Violations = append(check.Violations, Violation{Window: window.name, Count: window.count, Limit: window.limit})
+		}
+	}
+	check.Allowed = len(check.Violations) == 0
+	return check
+}
+
+// Record counts a submission of ip
+func (r *RateLimiter) Record(ip string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.submissions[ip] = append(r.submissions[ip], now)
+}
+
+// Stats drops expired submissions and summarizes the remaining ones
+func (r *RateLimiter) Stats(now time.Time) RateStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cleanup(now)
+	stats := RateStats{TotalIPs: len(r.submissions), LastCleanup: r.lastCleanup}
+	for _, timestamps := range r.submissions {
+		stats.TotalSubmissions += len(timestamps)
+	}
+	return stats
+}
+
+// prune drops the submissions of ip older than a day and returns the rest
+func (r *RateLimiter) prune(ip string, now time.Time) []time.Time {
+	cutoff := now.Add(-24 * time.Hour)
+	kept := r.submissions[ip][:0]
+	for _, t := range r.submissions[ip] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		delete(r.submissions, ip)
+		return nil
+	}
+	r.submissions[ip] = kept
+	return kept
+}
+
+// cleanup drops the submissions of every client older than a day
+func (r *RateLimiter) cleanup(now time.Time) {
+	for ip := range r.submissions {
+		r.prune(ip, now)
+	}
+	r.lastCleanup = now
+}
+
+func countSince(timestamps []time.Time, since time.Time) int {
+	count := 0
+	for _, t := range timestamps {
+		if t.After(since) {
+			count++
+		}
+	}
+	return count
+}
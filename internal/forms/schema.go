@@ -0,0 +1,248 @@
+package forms
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// Field types
+const (
+	TextField     = "text"
+	EmailField    = "email"
+	TextareaField = "textarea"
+	URLField      = "url"
+	TelField      = "tel"
+	NumberField   = "number"
+	CheckboxField = "checkbox"
+	SelectField   = "select"
+)
+
+// FieldTypes lists the supported field types
+var FieldTypes = []string{TextField, EmailField, TextareaField, URLField, TelField, NumberField, CheckboxField, SelectField}
+
+// Default maximum lengths of fields that do not set one
+const (
+	DefaultMaxLength         = 255
+	DefaultTextareaMaxLength = 5000
+)
+
+// ContactFormName is the form served at /submit
+const ContactFormName = "contact"
+
+var (
+	schemaNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	fieldNamePattern  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	telPattern        = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{3,}$`)
+)
+
+// Schema declares a form served at /forms/<name>
+type Schema struct {
+	Name      string
+	Title     string // defaults to the name in title case, e.g. "Quote Request"
+	Recipient string // defaults to Config.ToEmail
+	Subject   string // text/template over the field values, e.g. "Quote for {{.company}}"
+	Redirect  string // where browsers are sent after a form post
+	Fields    []Field
+}
+
+// Field declares a field of a form
+type Field struct {
+	Name      string
+	Label     string // defaults to the capitalized name
+	Type      string // defaults to text
+	Required  bool
+	MaxLength int      // defaults by type
+	Options   []string // allowed values of a select
+}
+
+// ContactSchema is the built-in contact form, used unless the project
+// declares its own contact form
+var ContactSchema = Schema{
+	Name:    ContactFormName,
+	Title:   "Contact",
+	Subject: "New submission from {{.name}}",
+	Fields: []Field{
+		{Name: "name", Type: TextField, Required: true, MaxLength: MaxNameLength},
+		{Name: "email", Type: EmailField, Required: true, MaxLength: MaxEmailLength},
+		{Name: "message", Type: TextareaField, Required: true, MaxLength: MaxMessageLength},
+		{Name: "subject", Type: TextField, MaxLength: MaxSubjectLength},
+	},
+}
+
+// Validate checks the declaration of the form
+func (s *Schema) Validate() error {
+	if !schemaNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("invalid form name %q: use lowercase letters, digits and hyphens", s.Name)
+	}
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("form %s has no fields", s.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range s.Fields {
+		if !fieldNamePattern.MatchString(field.Name) {
+			return fmt.Errorf("form %s: invalid field name %q", s.Name, field.Name)
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("form %s: duplicate field %s", s.Name, field.Name)
+		}
+		seen[field.Name] = true
+		if slices.Contains(HoneypotFields, field.Name) {
+			return fmt.Errorf("form %s: field %s is reserved for spam detection", s.Name, field.Name)
+		}
+
+		if field.Type != "" && !slices.Contains(FieldTypes, field.Type) {
+			return fmt.Errorf("form %s: field %s has unknown type %q (use %s)", s.Name, field.Name, field.Type, strings.Join(FieldTypes, ", "))
+		}
+		if field.Type == SelectField && len(field.Options) == 0 {
+			return fmt.Errorf("form %s: select field %s needs options", s.Name, field.Name)
+		}
+		if field.MaxLength < 0 {
+			return fmt.Errorf("form %s: field %s has a negative max_length", s.Name, field.Name)
+		}
+	}
+
+	if _, err := s.subjectTemplate(); err != nil {
+		return fmt.Errorf("form %s: invalid subject: %v", s.Name, err)
+	}
+	if s.Redirect != "" {
+		if _, err := url.Parse(s.Redirect); err != nil {
+			return fmt.Errorf("form %s: invalid redirect: %v", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// DisplayTitle returns the title of the form
+func (s *Schema) DisplayTitle() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	words := strings.FieldsFunc(s.Name, func(r rune) bool { return r == '-' })
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// Values returns the submitted values of the declared fields
+func (s *Schema) Values(data map[string]string) map[string]string {
+	values := make(map[string]string, len(s.Fields))
+	for _, field := range s.Fields {
+		if value, ok := data[field.Name]; ok {
+			values[field.Name] = value
+		}
+	}
+	return values
+}
+
+// ValidateSubmission checks a sanitized submission against the declared
+// fields
+func (s *Schema) ValidateSubmission(data map[string]string) ValidationResult {
+	result := ValidationResult{Errors: []string{}, Warnings: []string{}}
+
+	for _, field := range s.Fields {
+		value := strings.TrimSpace(data[field.Name])
+		if value == "" {
+			if field.Required {
+				result.Errors = append(result.Errors, field.DisplayLabel()+" is required")
+			}
+			continue
+		}
+
+		if message := field.checkValue(value); message != "" {
+			result.Errors = append(result.Errors, message)
+		}
+		if max := field.maxLength(); utf8.RuneCountInString(value) > max {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s is too long (maximum %d characters)", field.DisplayLabel(), max))
+		}
+		if field.Type == TextareaField {
+			result.Warnings = append(result.Warnings, suspiciousContent(value)...)
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// subject renders the subject of the notification email, without the prefix
+func (s *Schema) subject(data map[string]string) (string, error) {
+	tmpl, err := s.subjectTemplate()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(buf.String()), " "), nil
+}
+
+func (s *Schema) subjectTemplate() (*template.Template, error) {
+	text := s.Subject
+	if text == "" {
+		text = "New " + s.DisplayTitle() + " submission"
+	}
+	return template.New("subject").Option("missingkey=zero").Parse(text)
+}
+
+// DisplayLabel returns the label of the field
+func (f Field) DisplayLabel() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return capitalize(strings.NewReplacer("_", " ", "-", " ").Replace(f.Name))
+}
+
+func (f Field) maxLength() int {
+	switch {
+	case f.MaxLength > 0:
+		return f.MaxLength
+	case f.Type == TextareaField:
+		return DefaultTextareaMaxLength
+	default:
+		return DefaultMaxLength
+	}
+}
+
+// checkValue validates a value against the type of the field and returns
+// the error message, if any
+func (f Field) checkValue(value string) string {
+	switch f.Type {
+	case EmailField:
+		if !validEmail(value) {
+			return f.DisplayLabel() + " format is invalid"
+		}
+	case URLField:
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return f.DisplayLabel() + " must be an http or https URL"
+		}
+	case TelField:
+		if !telPattern.MatchString(value) {
+			return f.DisplayLabel() + " must be a phone number"
+		}
+	case NumberField:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return f.DisplayLabel() + " must be a number"
+		}
+	case CheckboxField:
+		switch strings.ToLower(value) {
+		case "on", "true", "yes", "1":
+		default:
+			return f.DisplayLabel() + " must be checked or left out"
+		}
+	case SelectField:
+		if !slices.Contains(f.Options, value) {
+			return fmt.Sprintf("%s must be one of: %s", f.DisplayLabel(), strings.Join(f.Options, ", "))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package forms
+
+import (
+	"strings"
+	"testing"
+)
+
+var quoteSchema = Schema{
+	Name:      "quote-request",
+	Recipient: "sales@example.com",
+	Subject:   "Quote for {{.company}}",
+	Redirect:  "/thanks/",
+	Fields: []Field{
+		{Name: "company", Required: true, MaxLength: 20},
+		{Name: "email", Type: EmailField, Required: true},
+		{Name: "site_url", Label: "Website", Type: URLField},
+		{Name: "phone", Label: "Phone number", Type: TelField},
+		{Name: "seats", Type: NumberField},
+		{Name: "budget", Type: SelectField, Options: []string{"small", "large"}},
+		{Name: "terms", Type: CheckboxField, Required: true},
+		{Name: "details", Type: TextareaField},
+	},
+}
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema Schema
+		err    string
+	}{
+		{"invalid name", Schema{Name: "Quote Form", Fields: []Field{{Name: "a"}}}, "invalid form name"},
+		{"no fields", Schema{Name: "quote"}, "has no fields"},
+		{"duplicate field", Schema{Name: "quote", Fields: []Field{{Name: "a"}, {Name: "a"}}}, "duplicate field a"},
+		{"honeypot field", Schema{Name: "quote", Fields: []Field{{Name: "url"}}}, "reserved for spam detection"},
+		{"unknown type", Schema{Name: "quote", Fields: []Field{{Name: "a", Type: "date"}}}, `unknown type "date"`},
+		{"select without options", Schema{Name: "quote", Fields: []Field{{Name: "a", Type: SelectField}}}, "needs options"},
+		{"invalid subject", Schema{Name: "quote", Subject: "{{.name", Fields: []Field{{Name: "a"}}}, "invalid subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.err)
+			}
+		})
+	}
+
+	if err := quoteSchema.Validate(); err != nil {
+		t.Errorf("valid schema rejected: %v", err)
+	}
+	if err := ContactSchema.Validate(); err != nil {
+		t.Errorf("the contact schema is invalid: %v", err)
+	}
+}
+
+func TestSchemaValidateSubmission(t *testing.T) {
+	valid := map[string]string{"company": "Acme", "email": "ada@example.com", "terms": "on"}
+
+	tests := []struct {
+		name   string
+		change map[string]string
+		err    string
+	}{
+		{"valid", nil, ""},
+		{"all types", map[string]string{"site_url": "https://acme.example", "phone": "+1 (555) 123-4567", "seats": "12", "budget": "large", "details": "Hi"}, ""},
+		{"missing", map[string]string{"company": " ", "terms": ""}, "Company is required; Terms is required"},
+		{"too long", map[string]string{"company": strings.Repeat("a", 21)}, "Company is too long (maximum 20 characters)"},
+		{"email", map[string]string{"email": "ada@"}, "Email format is invalid"},
+		{"url", map[string]string{"site_url": "javascript:alert(1)"}, "Website must be an http or https URL"},
+		{"tel", map[string]string{"phone": "call me"}, "Phone number must be a phone number"},
+		{"number", map[string]string{"seats": "twelve"}, "Seats must be a number"},
+		{"select", map[string]string{"budget": "huge"}, "Budget must be one of: small, large"},
+		{"checkbox", map[string]string{"terms": "maybe"}, "Terms must be checked or left out"},
+		{"default max length", map[string]string{"details": strings.Repeat("a", DefaultTextareaMaxLength+1)}, "Details is too long (maximum 5000 characters)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make(map[string]string)
+			for key, value := range valid {
+				data[key] = value
+			}
+			for key, value := range tt.change {
+				data[key] = value
+			}
+
+			result := quoteSchema.ValidateSubmission(data)
+			if got := strings.Join(result.Errors, "; "); got != tt.err {
+				t.Errorf("errors = %q, want %q", got, tt.err)
+			}
+			if result.Valid != (tt.err == "") {
+				t.Errorf("Valid = %v", result.Valid)
+			}
+		})
+	}
+}
+
+func TestSchemaSubject(t *testing.T) {
+	subject, err := quoteSchema.subject(map[string]string{"company": "Acme"})
+	if err != nil || subject != "Quote for Acme" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+
+	schema := Schema{Name: "job-application", Fields: []Field{{Name: "name"}}}
+	if subject, _ := schema.subject(nil); subject != "New Job Application submission" {
+		t.Errorf("default subject = %q", subject)
+	}
+}
+
+func TestSchemaHTML(t *testing.T) {
+	html, err := quoteSchema.HTML("http://localhost:4567/forms/quote-request")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`action="http://localhost:4567/forms/quote-request"`,
+		`<input type="text" name="company" maxlength="20" required`,
+		`<input type="email" name="email" maxlength="255" required`,
+		`<span class="block font-medium mb-1">Phone number</span>`,
+		`<option value="large">large</option>`,
+		`<input type="checkbox" name="terms" value="yes" required>`,
+		`<textarea name="details" rows="5" maxlength="5000"`,
+		`name="website" tabindex="-1"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("snippet is missing %s:\n%s", want, html)
+		}
+	}
+}
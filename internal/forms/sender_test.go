@@ -0,0 +1,358 @@
+package forms
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// smtpStandIn is a minimal SMTP server accepting messages over STARTTLS
+// with PLAIN authentication
+type smtpStandIn struct {
+	listener net.Listener
+	tls      *tls.Config // nil disables STARTTLS
+	username string
+	password string
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+type smtpMessage struct {
+	from, to string
+	tls      bool
+	authed   bool
+	data     string
+}
+
+// newSMTPStandIn starts a stand-in and returns it with the pool trusting
+// its certificate
+func newSMTPStandIn(t *testing.T, startTLS bool) (*smtpStandIn, *x509.CertPool) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStandIn{listener: listener, username: "forms", password: "secret"}
+	t.Cleanup(func() { listener.Close() })
+
+	// Borrow the certificate httptest issues for 127.0.0.1
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	pool := x509.NewCertPool()
+	pool.AddCert(https.Certificate())
+	if startTLS {
+		s.tls = &tls.Config{Certificates: https.TLS.Certificates}
+	}
+	https.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, pool
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+
+	var msg smtpMessage
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 stand-in ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-stand-in")
+			if s.tls != nil && !msg.tls {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, reader, msg.tls = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			fields := strings.Fields(line)
+			credentials, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			if string(credentials) != "\x00"+s.username+"\x00"+s.password {
+				reply("535 authentication failed")
+				continue
+			}
+			msg.authed = true
+			reply("235 authenticated")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 ok")
+		case "RCPT":
+			msg.to = strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")
+			reply("250 ok")
+		case "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *smtpStandIn) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+var testEmail = Email{
+	To:      "owner@example.com",
+	From:    "Example Forms <forms@example.com>",
+	Subject: "[Example] New submission from Zoë",
+	HTML:    "<p>Hello</p>",
+	Text:    "Hello\nfrom Zoë",
+	ReplyTo: "zoe@example.com",
+}
+
+func TestSMTPSender(t *testing.T) {
+	standIn, pool := newSMTPStandIn(t, true)
+
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: standIn.port(), Username: "forms", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender.tlsConfig.RootCAs = pool
+
+	id, err := sender.Send(testEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := standIn.received()
+	if len(messages) != 1 {
+		t.Fatalf("received %d messages, want 1", len(messages))
+	}
+	msg := messages[0]
+	if !msg.tls || !msg.authed || msg.from != "forms@example.com" || msg.to != "owner@example.com" {
+		t.Errorf("unexpected envelope %+v", msg)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Header.Get("Message-Id"); got != "<"+id+">" || !strings.HasSuffix(id, "@example.com") {
+		t.Errorf("Message-ID = %q, Send returned %q", got, id)
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); err != nil || subject != testEmail.Subject {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+	if parsed.Header.Get("Reply-To") != "zoe@example.com" {
+		t.Errorf("Reply-To = %q", parsed.Header.Get("Reply-To"))
+	}
+	if !strings.HasPrefix(parsed.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("Content-Type = %q", parsed.Header.Get("Content-Type"))
+	}
+}
+
+func TestSMTPSenderRequiresStartTLS(t *testing.T) {
+	standIn, _ := newSMTPStandIn(t, false)
+
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: standIn.port(), Username: "forms", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sender.Send(testEmail); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected a STARTTLS error, got %v", err)
+	}
+	if len(standIn.received()) != 0 {
+		t.Error("no message must be sent over an unencrypted connection")
+	}
+
+	// Local relays can opt out of TLS
+	sender, _ = NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: standIn.port(), Security: SMTPNoTLS})
+	if _, err := sender.Send(testEmail); err != nil {
+		t.Fatal(err)
+	}
+	if messages := standIn.received(); len(messages) != 1 || messages[0].tls || messages[0].authed {
+		t.Errorf("unexpected messages %+v", messages)
+	}
+}
+
+func TestSMTPSenderAuthFailure(t *testing.T) {
+	standIn, pool := newSMTPStandIn(t, true)
+
+	sender, _ := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: standIn.port(), Username: "forms", Password: "wrong"})
+	sender.tlsConfig.RootCAs = pool
+	if _, err := sender.Send(testEmail); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+}
+
+func TestNewSMTPSenderDefaults(t *testing.T) {
+	tests := []struct {
+		config   SMTPConfig
+		port     int
+		security string
+	}{
+		{SMTPConfig{Host: "mail"}, 587, SMTPStartTLS},
+		{SMTPConfig{Host: "mail", Port: 465}, 465, SMTPImplicitTLS},
+		{SMTPConfig{Host: "mail", Security: SMTPImplicitTLS}, 465, SMTPImplicitTLS},
+		{SMTPConfig{Host: "mail", Port: 25, Security: SMTPNoTLS}, 25, SMTPNoTLS},
+	}
+	for _, tt := range tests {
+		sender, err := NewSMTPSender(tt.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender.config.Port != tt.port || sender.config.Security != tt.security {
+			t.Errorf("%+v resolved to port %d, %s", tt.config, sender.config.Port, sender.config.Security)
+		}
+	}
+
+	if _, err := NewSMTPSender(SMTPConfig{Host: "mail", Security: "ssl"}); err == nil {
+		t.Error("expected an error for an unknown security mode")
+	}
+}
+
+func TestResendClient(t *testing.T) {
+	resend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer re_valid":
+			w.Write([]byte(`{"id": "email_123"}`))
+		case "Bearer re_limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer resend.Close()
+
+	tests := []struct {
+		key, id, err string
+	}{
+		{"re_valid", "email_123", ""},
+		{"re_limited", "", "rate limit exceeded"},
+		{"re_revoked", "", "unauthorized: invalid API key"},
+	}
+	for _, tt := range tests {
+		client, err := NewResendClient(tt.key, resend.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := client.Send(testEmail)
+		if id != tt.id || (err == nil) != (tt.err == "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("%s: Send = %q, %v; want %q, %q", tt.key, id, err, tt.id, tt.err)
+		}
+	}
+}
+
+func TestMailDirSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := NewMailDirSender(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, err := sender.Send(testEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(entries) != 0 {
+		t.Errorf("tmp must be empty after delivery, has %d entries", len(entries))
+	}
+
+	file, err := os.Open(filepath.Join(dir, "new", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	parsed, err := mail.ReadMessage(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header.Get("To") != "owner@example.com" {
+		t.Errorf("To = %q", parsed.Header.Get("To"))
+	}
+	if unix, _, _ := strings.Cut(name, "."); unix == "" || strings.ContainsAny(name, "/:") {
+		t.Errorf("unexpected Maildir file name %q", name)
+	} else if _, err := strconv.Atoi(unix); err != nil {
+		t.Errorf("Maildir file name %q must start with a timestamp", name)
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	tests := []struct {
+		config Config
+		name   string
+		err    string
+	}{
+		{Config{ResendAPIKey: "your_resend_api_key_here"}, "", "RESEND_API_KEY not configured"},
+		{Config{ResendAPIKey: "re_key"}, "", "FORM_FROM_EMAIL and FORM_TO_EMAIL are required"},
+		{Config{ResendAPIKey: "re_key", FromEmail: "a@example.com", ToEmail: "b@example.com"}, "Resend", ""},
+		{Config{EmailBackend: SMTPBackend, FromEmail: "a@example.com", ToEmail: "b@example.com"}, "", "SMTP host is required"},
+		{Config{EmailBackend: SMTPBackend, FromEmail: "a@example.com", ToEmail: "b@example.com", SMTP: SMTPConfig{Host: "mail"}}, "SMTP", ""},
+		{Config{EmailBackend: FileBackend, MailDir: filepath.Join(t.TempDir(), "mail")}, "Maildir", ""},
+		{Config{EmailBackend: "carrier-pigeon"}, "", `unknown email backend "carrier-pigeon" (use resend, smtp or file)`},
+	}
+	for _, tt := range tests {
+		sender, err := NewSender(tt.config)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%+v: error %v, want %q", tt.config, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || sender.Name() != tt.name {
+			t.Errorf("%+v: got %v, %v; want %s", tt.config, sender, err, tt.name)
+		}
+	}
+}
@@ -0,0 +1,721 @@
+// Package forms implements the contact form server started by
+// 'garp form-server'
+package forms
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogFile is where submissions are logged, relative to the project
+const LogFile = "form-submissions.log"
+
+// maxBodySize limits the size of a submission
+const maxBodySize = 1 << 20
+
+// Config configures the form server
+type Config struct {
+	Host        string
+	Port        int
+	ProjectName string // shown in the health check and notification emails
+	Environment string // development or production
+	Version     string
+
+	ToEmail       string
+	FromEmail     string
+	ReplyTo       string
+	SubjectPrefix string // defaults to "[<ProjectName> <form title> Form]"
+
+	EmailBackend string // resend (default), smtp or file
+	ResendAPIKey string
+	ResendURL    string // defaults to DefaultResendURL
+	SMTP         SMTPConfig
+	MailDir      string // defaults to DefaultMailDir
+
+	LogFile    string     // defaults to LogFile
+	StoreDir   string     // defaults to DefaultStoreDir
+	RateLimits RateLimits // defaults to DefaultRateLimits
+
+	// Webhooks receive every stored submission, if any URLs are set
+	Webhooks WebhookConfig
+
+	// Forms are the named forms served at /forms/<name>, checked with
+	// Schema.Validate. A form named contact replaces ContactSchema.
+	Forms []Schema
+}
+
+// Server handles contact form submissions
+type Server struct {
+	config   Config
+	limiter  *RateLimiter
+	sender   Sender
+	forms    map[string]*Schema
+	log      *submissionLog
+	store    *Store
+	webhooks *WebhookQueue
+	started  time.Time
+	now      func() time.Time
+
+	// EmailDisabledReason explains why submissions are not emailed
+	EmailDisabledReason string
+}
+
+// NewServer creates a form server and opens its submission log and store
+func NewServer(config Config) (*Server, error) {
+	if config.LogFile == "" {
+		config.LogFile = LogFile
+	}
+	if config.RateLimits == (RateLimits{}) {
+		config.RateLimits = DefaultRateLimits
+	}
+	if config.Environment == "" {
+		config.Environment = "development"
+	}
+
+	if err := config.Webhooks.Validate(); err != nil {
+		return nil, err
+	}
+
+	store, err := OpenStore(config.StoreDir)
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := openSubmissionLog(config.LogFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		config:  config,
+		limiter: NewRateLimiter(config.RateLimits),
+		forms:   map[string]*Schema{ContactFormName: &ContactSchema},
+		log:     log,
+		store:   store,
+		started: time.Now(),
+		now:     time.Now,
+	}
+
+	for i := range config.Forms {
+		s.forms[config.Forms[i].Name] = &config.Forms[i]
+	}
+
+	if len(config.Webhooks.URLs) > 0 {
+		if config.Webhooks.UserAgent == "" {
+			config.Webhooks.UserAgent = "garp-form-server/" + config.Version
+		}
+		s.webhooks = NewWebhookQueue(config.Webhooks)
+		s.webhooks.log = log
+	}
+
+	if s.sender, err = NewSender(config); err != nil {
+		s.sender = nil
+		s.EmailDisabledReason = err.Error()
+	}
+
+	return s, nil
+}
+
+// EmailEnabled reports whether submissions are emailed
+func (s *Server) EmailEnabled() bool {
+	return s.sender != nil
+}
+
+// EmailBackend returns the name of the email backend, if email is enabled
+func (s *Server) EmailBackend() string {
+	if s.sender == nil {
+		return ""
+	}
+	return s.sender.Name()
+}
+
+// FormNames returns the names of the served forms
+func (s *Server) FormNames() []string {
+	names := make([]string, 0, len(s.forms))
+	for name := range s.forms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Store returns the store submissions are saved to
+func (s *Server) Store() *Store {
+	return s.store
+}
+
+// Webhooks returns the webhook queue, or nil without webhook URLs
+func (s *Server) Webhooks() *WebhookQueue {
+	return s.webhooks
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+}
+
+// ListenAndServe serves form submissions and delivers webhooks until ctx is
+// cancelled
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if s.webhooks != nil {
+		go s.webhooks.Run(ctx)
+	}
+
+	server := &http.Server{
+		Addr:              s.Addr(),
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdown)
+	}
+}
+
+// Close closes the submission log
+func (s *Server) Close() error {
+	return s.log.Close()
+}
+
+// ServeHTTP routes requests to the health check, stats and form endpoints
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.log.Write("error", map[string]interface{}{
+				"error":   "Internal server error",
+				"message": fmt.Sprint(recovered),
+				"status":  "failed",
+			})
+			s.writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"status":  "error",
+				"message": "An unexpected error occurred",
+			})
+		}
+	}()
+
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", "*")
+	header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	header.Set("Access-Control-Allow-Headers", "Content-Type, Accept, X-Requested-With")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/" && r.Method == http.MethodGet:
+		s.handleHealth(w)
+	case r.URL.Path == "/stats" && r.Method == http.MethodGet:
+		s.handleStats(w)
+	case r.URL.Path == "/submit" && r.Method == http.MethodPost:
+		s.handleSubmit(w, r, s.forms[ContactFormName])
+	case strings.HasPrefix(r.URL.Path, "/forms/"):
+		s.handleForm(w, r, strings.TrimPrefix(r.URL.Path, "/forms/"))
+	case r.URL.Path == "/submit":
+		s.writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{
+			"status":          "error",
+			"message":         fmt.Sprintf("Method %s not allowed for /submit endpoint", r.Method),
+			"allowed_methods": []string{http.MethodPost},
+		})
+	default:
+		s.writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status":  "error",
+			"message": "Endpoint not found",
+			"available_endpoints": map[string]string{
+				"GET /":              "Health check and service information",
+				"POST /submit":       "Contact form submission endpoint",
+				"GET /forms/<name>":  "Form fields",
+				"POST /forms/<name>": "Form submission endpoint",
+			},
+		})
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter) {
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        "healthy",
+		"service":       s.config.ProjectName + " Form Server",
+		"version":       s.config.Version,
+		"email_enabled": s.EmailEnabled(),
+		"endpoints": map[string]string{
+			"submit": "/submit",
+			"forms":  "/forms/<name>",
+			"health": "/",
+			"stats":  "/stats",
+		},
+		"forms": s.FormNames(),
+		"validation": map[string]interface{}{
+			"required_fields": RequiredFields,
+			"max_lengths": map[string]int{
+				"name":    MaxNameLength,
+				"email":   MaxEmailLength,
+				"message": MaxMessageLength,
+				"subject": MaxSubjectLength,
+			},
+		},
+		"rate_limits": s.limiter.Limits(),
+	})
+}
+
+func (s *Server) handleStats(w http.ResponseWriter) {
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        "ok",
+		"rate_limiting": s.limiter.Stats(s.now()),
+		"validation": map[string]int{
+			"required_fields": len(RequiredFields),
+			"honeypot_fields": len(HoneypotFields),
+			"forms":           len(s.forms),
+		},
+		"server": map[string]interface{}{
+			"email_enabled": s.EmailEnabled(),
+			"environment":   s.config.Environment,
+			"uptime":        s.now().Sub(s.started).Seconds(),
+		},
+	})
+}
+
+// handleForm describes a form on GET and accepts its submissions on POST
+func (s *Server) handleForm(w http.ResponseWriter, r *http.Request, name string) {
+	schema, ok := s.forms[name]
+	if !ok {
+		s.writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status":          "error",
+			"message":         fmt.Sprintf("Form not found: %s", name),
+			"available_forms": s.FormNames(),
+		})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.handleSubmit(w, r, schema)
+	case http.MethodGet:
+		fields := make([]map[string]interface{}, 0, len(schema.Fields))
+		for _, field := range schema.Fields {
+			description := map[string]interface{}{
+				"name":       field.Name,
+				"label":      field.DisplayLabel(),
+				"type":       valueOr(field.Type, TextField),
+				"required":   field.Required,
+				"max_length": field.maxLength(),
+			}
+			if len(field.Options) > 0 {
+				description["options"] = field.Options
+			}
+			fields = append(fields, description)
+		}
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status": "ok",
+			"form":   schema.Name,
+			"title":  schema.DisplayTitle(),
+			"fields": fields,
+		})
+	default:
+		s.writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{
+			"status":          "error",
+			"message":         fmt.Sprintf("Method %s not allowed for %s", r.Method, r.URL.Path),
+			"allowed_methods": []string{http.MethodGet, http.MethodPost},
+		})
+	}
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request, schema *Schema) {
+	ip := clientIP(r)
+
+	data, err := readSubmission(r)
+	if err != nil {
+		s.log.Write("error", map[string]interface{}{
+			"error":   "JSON parse error",
+			"message": err.Error(),
+			"status":  "failed",
+		})
+		s.writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "Invalid JSON in request body",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Check rate limiting first
+	if check := s.limiter.Check(ip, s.now()); !check.Allowed {
+		violation := check.Violations[0]
+		s.log.Write("warn", map[string]interface{}{
+			"form":       schema.Name,
+			"ip":         ip,
+			"status":     "rate_limited",
+			"violation":  violation,
+			"user_agent": r.UserAgent(),
+		})
+		w.Header().Set("Retry-After", strconv.Itoa(violation.RetryAfter()))
+		s.writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+			"status":  "error",
+			"message": "Rate limit exceeded",
+			"error":   "Too many submissions per " + violation.Window,
+			"details": map[string]interface{}{
+				"limit":         violation.Limit,
+				"current_count": violation.Count,
+				"window":        violation.Window,
+			},
+			"retry_after": violation.RetryAfter(),
+		})
+		return
+	}
+
+	// Pretend to accept spam so bots do not learn about the honeypot
+	if field, trapped := CheckHoneypot(data); trapped {
+		s.log.Write("warn", map[string]interface{}{
+			"form":           schema.Name,
+			"ip":             ip,
+			"status":         "spam_detected",
+			"honeypot_field": field,
+			"user_agent":     r.UserAgent(),
+		})
+		s.respond(w, r, schema, map[string]interface{}{
+			"status":     "success",
+			"message":    "Form submission received",
+			"form":       schema.Name,
+			"id":         generateSubmissionID(s.now()),
+			"email_sent": false,
+		})
+		return
+	}
+
+	// Only the declared fields are validated, logged and emailed
+	data = schema.Values(data)
+	validation := schema.ValidateSubmission(data)
+	if !validation.Valid {
+		s.log.Write("info", map[string]interface{}{
+			"form":       schema.Name,
+			"ip":         ip,
+			"status":     "validation_failed",
+			"errors":     validation.Errors,
+			"warnings":   validation.Warnings,
+			"user_agent": r.UserAgent(),
+		})
+		s.writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"status":  "error",
+			"message": "Validation failed",
+			"errors":  validation.Errors,
+		})
+		return
+	}
+
+	s.limiter.Record(ip, s.now())
+	id := generateSubmissionID(s.now())
+
+	params := make(map[string]string, len(data))
+	for key, value := range data {
+		if !strings.Contains(key, "password") {
+			params[key] = value
+		}
+	}
+	s.log.Write("info", map[string]interface{}{
+		"submission_id": id,
+		"form":          schema.Name,
+		"ip":            ip,
+		"user_agent":    r.UserAgent(),
+		"method":        r.Method,
+		"path":          r.URL.Path,
+		"params":        params,
+		"status":        "received",
+	})
+
+	// Store the submission before emailing it, so it survives a failed or
+	// disabled email delivery
+	submission := Submission{
+		ID:         id,
+		Form:       schema.Name,
+		ReceivedAt: s.now(),
+		Fields:     data,
+		Warnings:   validation.Warnings,
+		IP:         ip,
+		UserAgent:  r.UserAgent(),
+		Status:     StatusPending,
+	}
+	s.saveSubmission(submission)
+	s.queueWebhooks(submission)
+
+	response := map[string]interface{}{
+		"status":     "success",
+		"message":    "Form submission received",
+		"form":       schema.Name,
+		"id":         id,
+		"email_sent": false,
+	}
+
+	if s.EmailEnabled() {
+		emailID, err := s.sendNotification(schema, data, id)
+		if err != nil {
+			s.log.Write("error", map[string]interface{}{
+				"submission_id": id,
+				"status":        "email_failed",
+				"error":         err.Error(),
+			})
+			response["email_error"] = err.Error()
+			submission.Status = StatusEmailFailed
+			submission.EmailError = err.Error()
+		} else {
+			s.log.Write("info", map[string]interface{}{
+				"submission_id": id,
+				"email_id":      emailID,
+				"status":        "email_sent",
+				"message":       "Email sent successfully via " + s.sender.Name(),
+			})
+			response["email_sent"] = true
+			if emailID != "" {
+				response["email_id"] = emailID
+			}
+			submission.Status = StatusEmailed
+			submission.EmailID = emailID
+		}
+	} else {
+		response["message"] = "Form submission received (email delivery disabled)"
+		submission.Status = StatusNotEmailed
+		submission.EmailError = s.EmailDisabledReason
+	}
+	s.saveSubmission(submission)
+
+	s.log.Write("info", map[string]interface{}{
+		"submission_id": id,
+		"status":        "processed",
+		"email_sent":    response["email_sent"],
+		"message":       "Form submission processed successfully",
+	})
+	s.respond(w, r, schema, response)
+}
+
+// saveSubmission stores the submission. Failures are logged rather than
+// failing the request, as the submission is still emailed.
+func (s *Server) saveSubmission(submission Submission) {
+	if err := s.store.Save(submission); err != nil {
+		s.log.Write("error", map[string]interface{}{
+			"submission_id": submission.ID,
+			"status":        "store_failed",
+			"error":         err.Error(),
+		})
+	}
+}
+
+// queueWebhooks queues the delivery of the submission to the webhooks
+func (s *Server) queueWebhooks(submission Submission) {
+	if s.webhooks == nil {
+		return
+	}
+	deliveries, err := s.webhooks.Enqueue(submission)
+	if err != nil {
+		s.log.Write("error", map[string]interface{}{
+			"submission_id": submission.ID,
+			"status":        "webhook_queue_failed",
+			"error":         err.Error(),
+		})
+		return
+	}
+	s.log.Write("info", map[string]interface{}{
+		"submission_id": submission.ID,
+		"status":        "webhook_queued",
+		"deliveries":    len(deliveries),
+	})
+}
+
+// sendNotification emails the submission to the recipient of the form
+func (s *Server) sendNotification(schema *Schema, data map[string]string, id string) (string, error) {
+	html, text, err := BuildSubmissionEmail(schema, data, id, s.config.ProjectName, s.now())
+	if err != nil {
+		return "", err
+	}
+
+	subject, err := schema.subject(data)
+	if err != nil {
+		return "", err
+	}
+	prefix := s.config.SubjectPrefix
+	if prefix == "" {
+		prefix = fmt.Sprintf("[%s %s Form]", s.config.ProjectName, schema.DisplayTitle())
+	}
+
+	return s.sender.Send(Email{
+		To:      valueOr(schema.Recipient, s.config.ToEmail),
+		From:    s.config.FromEmail,
+		Subject: prefix + " " + subject,
+		HTML:    html,
+		Text:    text,
+		ReplyTo: s.config.ReplyTo,
+	})
+}
+
+// respond answers a successful submission. Browsers posting a form with a
+// redirect are sent on to it; everything else gets the JSON response.
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, schema *Schema, response map[string]interface{}) {
+	if schema.Redirect == "" || !formPost(r) {
+		s.writeJSON(w, http.StatusOK, response)
+		return
+	}
+	http.Redirect(w, r, redirectURL(schema.Redirect, r), http.StatusSeeOther)
+}
+
+// formPost reports whether the request is a plain HTML form post rather
+// than a script expecting JSON
+func formPost(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" && mediaType != "multipart/form-data" {
+		return false
+	}
+	return !strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// redirectURL resolves a path redirect against the page the form was posted
+// from, since the form server usually runs on another host than the site
+func redirectURL(target string, r *http.Request) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return target
+	}
+	referer, err := url.Parse(r.Header.Get("Referer"))
+	if err != nil || referer.Scheme == "" || referer.Host == "" {
+		return target
+	}
+	return referer.Scheme + "://" + referer.Host + target
+}
+
+// writeJSON writes a JSON response with the current timestamp added
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body map[string]interface{}) {
+	body["timestamp"] = s.now().Format(time.RFC3339)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// readSubmission decodes a JSON or form encoded submission and sanitizes
+// its values
+func readSubmission(r *http.Request) (map[string]string, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+	data := make(map[string]string)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		if err := r.ParseMultipartForm(maxBodySize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return nil, err
+		}
+		for key, values := range r.Form {
+			if len(values) > 0 {
+				data[key] = SanitizeInput(values[0])
+			}
+		}
+		return data, nil
+	}
+
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			data[key] = SanitizeInput(v)
+		default:
+			data[key] = SanitizeInput(fmt.Sprint(v))
+		}
+	}
+	return data, nil
+}
+
+// clientIP returns the address of the client. Requests from loopback and
+// private addresses are taken to come through a reverse proxy, whose
+// X-Forwarded-For header then names the client.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !trustedProxy(host) {
+		return host
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if addr == "" {
+			continue
+		}
+		if !trustedProxy(addr) || i == 0 {
+			return addr
+		}
+	}
+	return host
+}
+
+func trustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate())
+}
+
+// generateSubmissionID returns an ID such as sub_1722350000_9f86d081. The
+// random part keeps IDs in the store unique within a second.
+func generateSubmissionID(now time.Time) string {
+	random := make([]byte, 4)
+	rand.Read(random)
+	return fmt.Sprintf("sub_%d_%s", now.Unix(), hex.EncodeToString(random))
+}
+
+// submissionLog appends JSON lines to the submission log
+type submissionLog struct {
+	mu   sync.Mutex
+	file *os.File
+	now  func() time.Time
+}
+
+func openSubmissionLog(path string) (*submissionLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	return &submissionLog{file: file, now: time.Now}, nil
+}
+
+// Write logs an entry with its level and timestamp
+func (l *submissionLog) Write(level string, entry map[string]interface{}) {
+	entry["level"] = level
+	entry["timestamp"] = l.now().Format(time.RFC3339)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file.Write(append(data, '\n'))
+}
+
+// Close closes the log file
+func (l *submissionLog) Close() error {
+	return l.file.Close()
+}
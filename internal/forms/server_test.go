@@ -0,0 +1,384 @@
+package forms
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer creates a form server for forms logging to a temporary
+// directory. When resend is set, notifications are sent to it.
+func newTestServer(t *testing.T, resend *httptest.Server, forms ...Schema) *Server {
+	t.Helper()
+
+	config := Config{
+		Host:        "127.0.0.1",
+		Port:        4567,
+		ProjectName: "Example",
+		Version:     "test",
+		ToEmail:     "owner@example.com",
+		FromEmail:   "forms@example.com",
+		LogFile:     filepath.Join(t.TempDir(), LogFile),
+		StoreDir:    t.TempDir(),
+		RateLimits:  RateLimits{PerMinute: 2, PerHour: 20, PerDay: 100},
+		Forms:       forms,
+	}
+	if resend != nil {
+		config.ResendAPIKey = "re_test"
+		config.ResendURL = resend.URL
+	}
+
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func request(t *testing.T, server *Server, method, path, contentType, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var reply map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("%s %s returned invalid JSON %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec, reply
+}
+
+const validSubmission = `{"name": "Ada", "email": "ada@example.com", "message": "Hello <b>there</b>"}`
+
+func TestSubmitSendsEmail(t *testing.T) {
+	var sent map[string]interface{}
+	resend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer re_test" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &sent)
+		w.Write([]byte(`{"id": "email_123"}`))
+	}))
+	defer resend.Close()
+	server := newTestServer(t, resend)
+
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/json", validSubmission)
+	if rec.Code != http.StatusOK || reply["email_sent"] != true || reply["email_id"] != "email_123" {
+		t.Fatalf("unexpected response %d: %v", rec.Code, reply)
+	}
+	id, _ := reply["id"].(string)
+	if !strings.HasPrefix(id, "sub_") {
+		t.Errorf("unexpected submission ID %q", id)
+	}
+
+	stored, err := server.Store().Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != StatusEmailed || stored.EmailID != "email_123" || stored.Form != ContactFormName || stored.Fields["name"] != "Ada" {
+		t.Errorf("unexpected stored submission %+v", stored)
+	}
+
+	if sent["subject"] != "[Example Contact Form] New submission from Ada" {
+		t.Errorf("unexpected subject %v", sent["subject"])
+	}
+	if html, _ := sent["html"].(string); !strings.Contains(html, "Hello &lt;b&gt;there&lt;/b&gt;") {
+		t.Errorf("the message must be escaped in the HTML email: %s", html)
+	}
+
+	log, err := os.ReadFile(server.config.LogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{`"status":"received"`, `"status":"email_sent"`, `"status":"processed"`} {
+		if !strings.Contains(string(log), status) {
+			t.Errorf("log is missing %s:\n%s", status, log)
+		}
+	}
+}
+
+func TestSubmitReportsEmailFailure(t *testing.T) {
+	resend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "from address not verified"}`))
+	}))
+	defer resend.Close()
+	server := newTestServer(t, resend)
+
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/json", validSubmission)
+	if rec.Code != http.StatusOK || reply["email_sent"] != false {
+		t.Fatalf("unexpected response %d: %v", rec.Code, reply)
+	}
+	if reply["email_error"] != "validation error: from address not verified" {
+		t.Errorf("unexpected email error %v", reply["email_error"])
+	}
+
+	// The submission is kept for 'garp forms list' although it was not emailed
+	stored, err := server.Store().Query(SubmissionFilter{Status: StatusEmailFailed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].EmailError != "validation error: from address not verified" {
+		t.Errorf("unexpected stored submissions %+v", stored)
+	}
+}
+
+func TestSubmitAcceptsFormPosts(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	form := url.Values{"name": {"Ada"}, "email": {"ada@example.com"}, "message": {"Hello"}}
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/x-www-form-urlencoded", form.Encode())
+	if rec.Code != http.StatusOK || reply["message"] != "Form submission received (email delivery disabled)" {
+		t.Fatalf("unexpected response %d: %v", rec.Code, reply)
+	}
+
+	stored, err := server.Store().Get(reply["id"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != StatusNotEmailed || stored.Fields["message"] != "Hello" {
+		t.Errorf("unexpected stored submission %+v", stored)
+	}
+}
+
+func TestSubmitRejectsInvalidSubmissions(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/json", `{"name": "Ada"`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed JSON returned %d: %v", rec.Code, reply)
+	}
+
+	rec, reply = request(t, server, http.MethodPost, "/submit", "application/json", `{"name": "Ada", "email": "nope"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid submission returned %d: %v", rec.Code, reply)
+	}
+	if errors, _ := reply["errors"].([]interface{}); len(errors) != 2 {
+		t.Errorf("unexpected validation errors %v", reply["errors"])
+	}
+}
+
+func TestSubmitHoneypot(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/json",
+		`{"name": "Bot", "email": "bot@example.com", "message": "Hi", "website": "http://spam.example"}`)
+	if rec.Code != http.StatusOK || reply["status"] != "success" || reply["email_sent"] != false {
+		t.Fatalf("spam must look accepted, got %d: %v", rec.Code, reply)
+	}
+
+	log, _ := os.ReadFile(server.config.LogFile)
+	if !strings.Contains(string(log), `"status":"spam_detected"`) || strings.Contains(string(log), `"status":"received"`) {
+		t.Errorf("unexpected log for spam:\n%s", log)
+	}
+	if stored, _ := server.Store().Query(SubmissionFilter{}); len(stored) != 0 {
+		t.Errorf("spam must not be stored, got %+v", stored)
+	}
+}
+
+func TestSubmitRateLimit(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	for i := 0; i < 2; i++ {
+		if rec, reply := request(t, server, http.MethodPost, "/submit", "application/json", validSubmission); rec.Code != http.StatusOK {
+			t.Fatalf("submission %d returned %d: %v", i+1, rec.Code, reply)
+		}
+	}
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/json", validSubmission)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected a rate limited response, got %d: %v", rec.Code, reply)
+	}
+}
+
+func TestSubmitQueuesWebhooks(t *testing.T) {
+	receiver := newWebhookReceiver(t, func(request int) bool { return request == 1 })
+
+	if _, err := NewServer(Config{LogFile: filepath.Join(t.TempDir(), LogFile), StoreDir: t.TempDir(),
+		Webhooks: WebhookConfig{URLs: []string{receiver.URL}}}); err == nil {
+		t.Fatal("expected an error for webhooks without a secret")
+	}
+
+	server, err := NewServer(Config{
+		ProjectName: "Example",
+		Version:     "test",
+		LogFile:     filepath.Join(t.TempDir(), LogFile),
+		StoreDir:    t.TempDir(),
+		Webhooks:    WebhookConfig{URLs: []string{receiver.URL}, Secret: testWebhookSecret, Dir: t.TempDir()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// Webhooks are delivered although email delivery is disabled
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/json", validSubmission)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d: %v", rec.Code, reply)
+	}
+	pending, _ := server.Webhooks().Pending()
+	if len(pending) != 1 || pending[0].SubmissionID != reply["id"] {
+		t.Fatalf("unexpected webhook queue %+v", pending)
+	}
+
+	ctx := context.Background()
+	if result := server.Webhooks().ProcessDue(ctx); result.Retrying != 1 {
+		t.Fatalf("first attempt = %+v", result)
+	}
+	server.Webhooks().now = func() time.Time { return time.Now().Add(time.Hour) }
+	if result := server.Webhooks().ProcessDue(ctx); result.Delivered != 1 {
+		t.Fatalf("retry = %+v", result)
+	}
+	if payload := receiver.payloads[0]; payload.ID != reply["id"] || payload.Fields["message"] != "Hello <b>there</b>" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	log, _ := os.ReadFile(server.config.LogFile)
+	for _, status := range []string{`"status":"webhook_queued"`, `"status":"webhook_failed"`, `"status":"webhook_delivered"`} {
+		if !strings.Contains(string(log), status) {
+			t.Errorf("log is missing %s:\n%s", status, log)
+		}
+	}
+}
+
+func TestServerRoutes(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	rec, reply := request(t, server, http.MethodGet, "/", "", "")
+	if rec.Code != http.StatusOK || reply["status"] != "healthy" || reply["service"] != "Example Form Server" || reply["email_enabled"] != false {
+		t.Errorf("unexpected health check %d: %v", rec.Code, reply)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("missing CORS headers")
+	}
+
+	rec, reply = request(t, server, http.MethodGet, "/stats", "", "")
+	if rec.Code != http.StatusOK || reply["rate_limiting"] == nil {
+		t.Errorf("unexpected stats %d: %v", rec.Code, reply)
+	}
+
+	rec, _ = request(t, server, http.MethodGet, "/submit", "", "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /submit returned %d", rec.Code)
+	}
+
+	rec, _ = request(t, server, http.MethodGet, "/missing", "", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown endpoint returned %d", rec.Code)
+	}
+}
+
+func TestNamedForm(t *testing.T) {
+	var sent map[string]interface{}
+	resend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &sent)
+		w.Write([]byte(`{"id": "email_123"}`))
+	}))
+	defer resend.Close()
+	server := newTestServer(t, resend, quoteSchema)
+
+	rec, reply := request(t, server, http.MethodGet, "/forms/quote-request", "", "")
+	if rec.Code != http.StatusOK || reply["title"] != "Quote Request" {
+		t.Fatalf("unexpected form description %d: %v", rec.Code, reply)
+	}
+	if fields, _ := reply["fields"].([]interface{}); len(fields) != len(quoteSchema.Fields) {
+		t.Errorf("unexpected fields %v", reply["fields"])
+	}
+
+	rec, reply = request(t, server, http.MethodPost, "/forms/quote-request", "application/json",
+		`{"company": "Acme", "email": "ada@example.com", "terms": true, "budget": "large", "unknown": "dropped"}`)
+	if rec.Code != http.StatusOK || reply["form"] != "quote-request" || reply["email_sent"] != true {
+		t.Fatalf("unexpected response %d: %v", rec.Code, reply)
+	}
+	if to, _ := sent["to"].([]interface{}); len(to) != 1 || to[0] != "sales@example.com" {
+		t.Errorf("email sent to %v, want the form recipient", sent["to"])
+	}
+	if sent["subject"] != "[Example Quote Request Form] Quote for Acme" {
+		t.Errorf("unexpected subject %v", sent["subject"])
+	}
+	if text, _ := sent["text"].(string); !strings.Contains(text, "Budget: large") || strings.Contains(text, "dropped") {
+		t.Errorf("unexpected email text:\n%s", text)
+	}
+
+	rec, reply = request(t, server, http.MethodPost, "/forms/quote-request", "application/json", `{"company": "Acme"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid submission returned %d: %v", rec.Code, reply)
+	}
+
+	rec, reply = request(t, server, http.MethodGet, "/forms/missing", "", "")
+	if forms, _ := reply["available_forms"].([]interface{}); rec.Code != http.StatusNotFound || len(forms) != 2 {
+		t.Errorf("unknown form returned %d: %v", rec.Code, reply)
+	}
+
+	rec, _ = request(t, server, http.MethodPut, "/forms/quote-request", "", "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT returned %d", rec.Code)
+	}
+}
+
+func TestNamedFormRedirectsBrowsers(t *testing.T) {
+	server := newTestServer(t, nil, quoteSchema)
+
+	form := url.Values{"company": {"Acme"}, "email": {"ada@example.com"}, "terms": {"yes"}}
+	req := httptest.NewRequest(http.MethodPost, "/forms/quote-request", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", "https://example.com/quote/")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "https://example.com/thanks/" {
+		t.Fatalf("expected a redirect to the thanks page, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Scripts asking for JSON get JSON
+	rec, reply := request(t, server, http.MethodPost, "/forms/quote-request", "application/json",
+		`{"company": "Acme", "email": "ada@example.com", "terms": "yes"}`)
+	if rec.Code != http.StatusOK || reply["status"] != "success" {
+		t.Errorf("unexpected response %d: %v", rec.Code, reply)
+	}
+}
+
+func TestProjectContactFormReplacesBuiltIn(t *testing.T) {
+	contact := Schema{Name: ContactFormName, Fields: []Field{{Name: "email", Type: EmailField, Required: true}}}
+	server := newTestServer(t, nil, contact)
+
+	rec, reply := request(t, server, http.MethodPost, "/submit", "application/json", `{"email": "ada@example.com"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("submission to the project contact form returned %d: %v", rec.Code, reply)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		remote, forwarded, want string
+	}{
+		{"203.0.113.5:1234", "198.51.100.7", "203.0.113.5"},
+		{"127.0.0.1:1234", "198.51.100.7", "198.51.100.7"},
+		{"127.0.0.1:1234", "198.51.100.7, 10.0.0.2", "198.51.100.7"},
+		{"127.0.0.1:1234", "", "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+		req.RemoteAddr = tt.remote
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if got := clientIP(req); got != tt.want {
+			t.Errorf("clientIP(%s, %q) = %s, want %s", tt.remote, tt.forwarded, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package forms
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP connection security modes
+const (
+	SMTPStartTLS    = "starttls" // upgrade a plain connection, required
+	SMTPImplicitTLS = "tls"      // connect over TLS, usually port 465
+	SMTPNoTLS       = "none"     // never encrypt, only for local relays
+)
+
+// SMTPConfig configures delivery through an SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     int    // defaults to 465 for implicit TLS, 587 otherwise
+	Username string // authenticates with PLAIN when set
+	Password string
+	Security string // defaults to tls on port 465, starttls otherwise
+}
+
+// smtpTimeout bounds a complete SMTP conversation
+const smtpTimeout = time.Minute
+
+// SMTPSender sends email through an SMTP server
+type SMTPSender struct {
+	config    SMTPConfig
+	tlsConfig *tls.Config
+}
+
+// NewSMTPSender creates an SMTP sender, filling in the default port and
+// security mode
+func NewSMTPSender(config SMTPConfig) (*SMTPSender, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	if config.Security == "" {
+		config.Security = SMTPStartTLS
+		if config.Port == 465 {
+			config.Security = SMTPImplicitTLS
+		}
+	}
+	switch config.Security {
+	case SMTPStartTLS, SMTPNoTLS:
+		if config.Port == 0 {
+			config.Port = 587
+		}
+	case SMTPImplicitTLS:
+		if config.Port == 0 {
+			config.Port = 465
+		}
+	default:
+		return nil, fmt.Errorf("unknown SMTP security %q (use %s, %s or %s)", config.Security, SMTPStartTLS, SMTPImplicitTLS, SMTPNoTLS)
+	}
+
+	return &SMTPSender{
+		config:    config,
+		tlsConfig: &tls.Config{ServerName: config.Host},
+	}, nil
+}
+
+// Name returns the name of the backend
+func (s *SMTPSender) Name() string {
+	return "SMTP"
+}
+
+// Send delivers the email and returns its Message-ID
+func (s *SMTPSender) Send(email Email) (string, error) {
+	from, err := mail.ParseAddress(email.From)
+	if err != nil {
+		return "", fmt.Errorf("invalid from address %q: %v", email.From, err)
+	}
+	to, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return "", fmt.Errorf("invalid to address %q: %v", email.To, err)
+	}
+
+	message, id, err := buildMessage(email, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	client, err := s.dial()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	if s.config.Security == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return "", errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return "", fmt.Errorf("STARTTLS failed: %v", err)
+		}
+	}
+
+	if s.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return "", errors.New("SMTP server does not support authentication")
+		}
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return "", fmt.Errorf("SMTP authentication failed: %v", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return "", fmt.Errorf("SMTP server rejected sender: %v", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return "", fmt.Errorf("SMTP server rejected recipient: %v", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(message); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("SMTP server rejected message: %v", err)
+	}
+
+	client.Quit()
+	return id, nil
+}
+
+// dial connects to the SMTP server, over TLS in implicit TLS mode
+func (s *SMTPSender) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if s.config.Security == SMTPImplicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, s.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to SMTP server %s: %v", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP handshake with %s failed: %v", addr, err)
+	}
+	return client, nil
+}
+
+// buildMessage renders the email as a MIME message and returns it with its
+// Message-ID
+func buildMessage(email Email, now time.Time) ([]byte, string, error) {
+	id, err := messageID(email.From)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+		}
+	}
+	header("Date", now.Format(time.RFC1123Z))
+	header("From", email.From)
+	header("To", email.To)
+	header("Reply-To", email.ReplyTo)
+	header("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header("Message-ID", "<"+id+">")
+	header("MIME-Version", "1.0")
+
+	switch {
+	case email.HTML != "" && email.Text != "":
+		parts := multipart.NewWriter(&buf)
+		header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+		buf.WriteString("\r\n")
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", email.Text},
+			{"text/html", email.HTML},
+		} {
+			w, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType + "; charset=utf-8"},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			if err := writeQuotedPrintable(w, part.body); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := parts.Close(); err != nil {
+			return nil, "", err
+		}
+	case email.HTML != "":
+		header("Content-Type", "text/html; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, email.HTML); err != nil {
+			return nil, "", err
+		}
+	default:
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, email.Text); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return buf.Bytes(), id, nil
+}
+
+// writeQuotedPrintable encodes body with CRLF line endings
+func writeQuotedPrintable(w io.Writer, body string) error {
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the domain of the from address
+func messageID(from string) (string, error) {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random) + "@" + domain, nil
+}
@@ -0,0 +1,73 @@
+package forms
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// snippetTemplate is a text/template so the comments survive; values are
+// escaped with html
+var snippetTemplate = template.Must(template.New("snippet").Parse(`<!-- {{html .Title}} form: submissions go to the form server ('garp form-server'); update the action for production -->
+<form action="{{html .Action}}" method="post" class="space-y-4 not-prose max-w-lg">
+{{- range .Fields}}
+    <label class="block">
+{{- if eq .Type "checkbox"}}
+        <input type="checkbox" name="{{html .Name}}" value="yes"{{if .Required}} required{{end}}>
+        <span>{{html .Label}}</span>
+{{- else}}
+        <span class="block font-medium mb-1">{{html .Label}}</span>
+{{- if eq .Type "textarea"}}
+        <textarea name="{{html .Name}}" rows="5" maxlength="{{.MaxLength}}"{{if .Required}} required{{end}} class="w-full border border-gray-300 rounded px-3 py-2"></textarea>
+{{- else if eq .Type "select"}}
+        <select name="{{html .Name}}"{{if .Required}} required{{end}} class="w-full border border-gray-300 rounded px-3 py-2">
+{{- range .Options}}
+            <option value="{{html .}}">{{html .}}</option>
+{{- end}}
+        </select>
+{{- else}}
+        <input type="{{.Type}}" name="{{html .Name}}" maxlength="{{.MaxLength}}"{{if .Required}} required{{end}} class="w-full border border-gray-300 rounded px-3 py-2">
+{{- end}}
+{{- end}}
+    </label>
+{{- end}}
+    <!-- Leave empty: bots that fill in this field are treated as spam -->
+    <input type="text" name="website" tabindex="-1" autocomplete="off" aria-hidden="true" style="display:none">
+    <button type="submit" class="bg-blue-600 text-white px-4 py-2 rounded hover:bg-blue-700">Send</button>
+</form>
+`))
+
+// snippetField is a field as rendered into the HTML snippet
+type snippetField struct {
+	Name      string
+	Label     string
+	Type      string
+	Required  bool
+	MaxLength int
+	Options   []string
+}
+
+// HTML renders a form posting to action with an input for each field
+func (s *Schema) HTML(action string) (string, error) {
+	data := struct {
+		Title  string
+		Action string
+		Fields []snippetField
+	}{Title: s.DisplayTitle(), Action: action}
+
+	for _, field := range s.Fields {
+		data.Fields = append(data.Fields, snippetField{
+			Name:      field.Name,
+			Label:     field.DisplayLabel(),
+			Type:      valueOr(field.Type, TextField),
+			Required:  field.Required,
+			MaxLength: field.maxLength(),
+			Options:   field.Options,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := snippetTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,219 @@
+package forms
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultStoreDir is where submissions are stored, relative to the project
+const DefaultStoreDir = ".garp/submissions"
+
+// storeFile holds the submissions inside the store directory
+const storeFile = "submissions.jsonl"
+
+// Email delivery states of a stored submission
+const (
+	StatusPending     = "pending"      // stored, email not attempted yet
+	StatusEmailed     = "emailed"      // email delivered to the backend
+	StatusEmailFailed = "email_failed" // the backend rejected the email
+	StatusNotEmailed  = "not_emailed"  // email delivery is disabled
+)
+
+// Submission is a stored form submission
+type Submission struct {
+	ID         string            `json:"id"`
+	Form       string            `json:"form"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Fields     map[string]string `json:"fields"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	IP         string            `json:"ip,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	Status     string            `json:"status"`
+	EmailID    string            `json:"email_id,omitempty"`
+	EmailError string            `json:"email_error,omitempty"`
+}
+
+// Summary describes the sender of the submission on one line
+func (s Submission) Summary() string {
+	name, email := s.Fields["name"], s.Fields["email"]
+	switch {
+	case name != "" && email != "":
+		return fmt.Sprintf("%s <%s>", name, email)
+	case email != "":
+		return email
+	case name != "":
+		return name
+	}
+
+	keys := make([]string, 0, len(s.Fields))
+	for key := range s.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := s.Fields[key]; value != "" {
+			return truncate(value, 40)
+		}
+	}
+	return ""
+}
+
+// SubmissionFilter selects stored submissions
+type SubmissionFilter struct {
+	Form   string
+	Status string
+	Since  time.Time
+	Limit  int // 0 returns every match
+}
+
+// Store persists submissions as JSON lines. Every change appends the whole
+// submission, so the last line with an ID is its current state and a crash
+// never loses an earlier line.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore returns the store in dir without touching the file system, for
+// reading submissions. An empty dir uses DefaultStoreDir.
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = DefaultStoreDir
+	}
+	return &Store{path: filepath.Join(dir, storeFile)}
+}
+
+// OpenStore returns the store in dir, creating the directory if needed
+func OpenStore(dir string) (*Store, error) {
+	store := NewStore(dir)
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create submission store: %v", err)
+	}
+	return store, nil
+}
+
+// Path returns the file holding the submissions
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Save stores the current state of a submission
+func (s *Store) Save(submission Submission) error {
+	data, err := json.Marshal(submission)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Submissions hold personal data, so only the owner may read them
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open submission store: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to store submission: %v", err)
+	}
+	return file.Sync()
+}
+
+// Get returns the submission with the given ID
+func (s *Store) Get(id string) (*Submission, error) {
+	submissions, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range submissions {
+		if submissions[i].ID == id {
+			return &submissions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("submission not found: %s", id)
+}
+
+// Query returns the matching submissions, newest first
+func (s *Store) Query(filter SubmissionFilter) ([]Submission, error) {
+	submissions, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Submission
+	for _, submission := range submissions {
+		if filter.Form != "" && submission.Form != filter.Form {
+			continue
+		}
+		if filter.Status != "" && submission.Status != filter.Status {
+			continue
+		}
+		if !filter.Since.IsZero() && submission.ReceivedAt.Before(filter.Since) {
+			continue
+		}
+		matches = append(matches, submission)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].ReceivedAt.After(matches[j].ReceivedAt)
+	})
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+	return matches, nil
+}
+
+// load reads the current state of every submission in the order they were
+// first stored
+func (s *Store) load() ([]Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open submission store: %v", err)
+	}
+	defer file.Close()
+
+	var submissions []Submission
+	index := make(map[string]int)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*maxBodySize)
+	for scanner.Scan() {
+		var submission Submission
+		// A line cut short by a crash is skipped rather than failing the
+		// whole store
+		if err := json.Unmarshal(scanner.Bytes(), &submission); err != nil || submission.ID == "" {
+			continue
+		}
+		if i, ok := index[submission.ID]; ok {
+			submissions[i] = submission
+			continue
+		}
+		index[submission.ID] = len(submissions)
+		submissions = append(submissions, submission)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read submission store: %v", err)
+	}
+	return submissions, nil
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,149 @@
+package forms
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "submissions")
+
+	// Reading a store that does not exist yet leaves the project untouched
+	if stored, err := NewStore(dir).Query(SubmissionFilter{}); err != nil || len(stored) != 0 {
+		t.Fatalf("missing store returned %v, %v", stored, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("reading the store created %s", dir)
+	}
+
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	submissions := []Submission{
+		{ID: "sub_1", Form: "contact", ReceivedAt: base, Fields: map[string]string{"name": "Ada"}, Status: StatusPending},
+		{ID: "sub_2", Form: "quote", ReceivedAt: base.Add(time.Hour), Fields: map[string]string{"company": "Acme"}, Status: StatusEmailed},
+		{ID: "sub_3", Form: "contact", ReceivedAt: base.Add(2 * time.Hour), Fields: map[string]string{"name": "Grace"}, Status: StatusNotEmailed},
+	}
+	for _, submission := range submissions {
+		if err := store.Save(submission); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Saving again updates the submission
+	submissions[0].Status = StatusEmailFailed
+	submissions[0].EmailError = "boom"
+	if err := store.Save(submissions[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line cut short by a crash is skipped
+	file, err := os.OpenFile(store.Path(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"id": "sub_4", "form": "con`)
+	file.Close()
+
+	if info, err := os.Stat(store.Path()); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("store must only be readable by its owner: %v, %v", info.Mode(), err)
+	}
+
+	stored, err := store.Get("sub_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != StatusEmailFailed || stored.EmailError != "boom" || stored.Fields["name"] != "Ada" {
+		t.Errorf("unexpected submission %+v", stored)
+	}
+	if _, err := store.Get("sub_4"); err == nil {
+		t.Error("expected an error for an unknown submission")
+	}
+
+	tests := []struct {
+		name   string
+		filter SubmissionFilter
+		want   []string
+	}{
+		{"all newest first", SubmissionFilter{}, []string{"sub_3", "sub_2", "sub_1"}},
+		{"form", SubmissionFilter{Form: "contact"}, []string{"sub_3", "sub_1"}},
+		{"status", SubmissionFilter{Status: StatusEmailFailed}, []string{"sub_1"}},
+		{"since", SubmissionFilter{Since: base.Add(30 * time.Minute)}, []string{"sub_3", "sub_2"}},
+		{"limit", SubmissionFilter{Limit: 1}, []string{"sub_3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := store.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, submission := range stored {
+				ids = append(ids, submission.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("Query() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("Query() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmissionSummary(t *testing.T) {
+	tests := []struct {
+		fields map[string]string
+		want   string
+	}{
+		{map[string]string{"name": "Ada", "email": "ada@example.com"}, "Ada <ada@example.com>"},
+		{map[string]string{"email": "ada@example.com"}, "ada@example.com"},
+		{map[string]string{"company": "", "budget": "large"}, "large"},
+		{map[string]string{"details": "An unusually long description of the request"}, "An unusually long description of the re…"},
+	}
+	for _, tt := range tests {
+		if got := (Submission{Fields: tt.fields}).Summary(); got != tt.want {
+			t.Errorf("Summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	received := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	submissions := []Submission{
+		{ID: "sub_2", Form: "quote", ReceivedAt: received, Fields: map[string]string{"company": "Acme, Inc.", "email": "ada@example.com"}, Status: StatusEmailFailed, EmailError: "boom"},
+		{ID: "sub_1", Form: "contact", ReceivedAt: received, Fields: map[string]string{"name": "=HYPERLINK(\"http://evil.example\")", "email": "grace@example.com"}, Status: StatusEmailed, IP: "127.0.0.1"},
+	}
+
+	var csv strings.Builder
+	if err := Export(&csv, CSVFormat, submissions); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,form,received_at,status,email_error,ip,company,email,name\n" +
+		"sub_2,quote,2026-03-01T12:00:00Z,email_failed,boom,,\"Acme, Inc.\",ada@example.com,\n" +
+		"sub_1,contact,2026-03-01T12:00:00Z,emailed,,127.0.0.1,,grace@example.com,\"'=HYPERLINK(\"\"http://evil.example\"\")\"\n"
+	if csv.String() != want {
+		t.Errorf("CSV export =\n%s\nwant\n%s", csv.String(), want)
+	}
+
+	var out strings.Builder
+	if err := Export(&out, JSONFormat, nil); err != nil || out.String() != "[]\n" {
+		t.Errorf("empty JSON export = %q, %v", out.String(), err)
+	}
+	out.Reset()
+	if err := Export(&out, JSONFormat, submissions); err != nil || !strings.Contains(out.String(), `"company": "Acme, Inc."`) {
+		t.Errorf("JSON export = %s, %v", out.String(), err)
+	}
+
+	if err := Export(&out, "xml", submissions); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
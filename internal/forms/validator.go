@@ -0,0 +1,104 @@
+package forms
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Field length limits
+const (
+	MaxNameLength    = 100
+	MaxEmailLength   = 255
+	MaxMessageLength = 5000
+	MaxSubjectLength = 200
+)
+
+// RequiredFields must be present in every submission
+var RequiredFields = []string{"name", "email", "message"}
+
+// HoneypotFields are hidden from people; bots that fill them in are spam
+var HoneypotFields = []string{"website", "url", "homepage", "hp_field", "bot_field", "spam_check"}
+
+// SpamPhrases mark a message as suspicious
+var SpamPhrases = []string{
+	"click here", "limited time", "act now", "free money",
+	"make money fast", "get rich quick", "viagra", "casino",
+}
+
+var (
+	emailPattern   = regexp.MustCompile(`(?i)^[\w+\-.]+@[a-z\d\-]+(\.[a-z\d\-]+)*\.[a-z]+$`)
+	controlPattern = regexp.MustCompile("[\x00-\x08\x0B\x0C\x0E-\x1F\x7F]")
+	linkPattern    = regexp.MustCompile(`https?://`)
+)
+
+// ValidationResult is the outcome of validating a submission. Warnings flag
+// suspicious content but do not reject the submission.
+type ValidationResult struct {
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+// ValidateSubmission checks the required fields, the email format and the
+// field lengths of a sanitized contact form submission
+func ValidateSubmission(data map[string]string) ValidationResult {
+	return ContactSchema.ValidateSubmission(data)
+}
+
+// SanitizeInput trims the input and removes null bytes and control
+// characters other than newlines and tabs
+func SanitizeInput(input string) string {
+	return controlPattern.ReplaceAllString(strings.TrimSpace(input), "")
+}
+
+// CheckHoneypot returns the first honeypot field that was filled in
+func CheckHoneypot(data map[string]string) (string, bool) {
+	for _, field := range HoneypotFields {
+		if strings.TrimSpace(data[field]) != "" {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+func validEmail(email string) bool {
+	email = SanitizeInput(email)
+	if email == "" || !emailPattern.MatchString(email) {
+		return false
+	}
+	if strings.Contains(email, "..") || strings.HasPrefix(email, ".") || strings.HasSuffix(email, ".") {
+		return false
+	}
+	return strings.Count(email, "@") == 1
+}
+
+func suspiciousContent(message string) []string {
+	warnings := []string{}
+
+	if links := len(linkPattern.FindAllString(message, -1)); links > 3 {
+		warnings = append(warnings, fmt.Sprintf("Message contains many links (%d)", links))
+	}
+
+	if utf8.RuneCountInString(message) > 50 && strings.ToUpper(message) == message {
+		warnings = append(warnings, "Message is mostly uppercase")
+	}
+
+	lower := strings.ToLower(message)
+	for _, phrase := range SpamPhrases {
+		if strings.Contains(lower, phrase) {
+			warnings = append(warnings, "Message contains potentially suspicious content")
+			break
+		}
+	}
+
+	return warnings
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
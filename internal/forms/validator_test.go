@@ -0,0 +1,99 @@
+package forms
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSubmission(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     map[string]string
+		errors   []string
+		warnings int
+	}{
+		{
+			name: "valid",
+			data: map[string]string{"name": "Ada", "email": "ada@example.com", "message": "Hello"},
+		},
+		{
+			name:   "missing fields",
+			data:   map[string]string{"name": " ", "message": "Hello"},
+			errors: []string{"Name is required", "Email is required"},
+		},
+		{
+			name:   "invalid email",
+			data:   map[string]string{"name": "Ada", "email": "ada..lovelace@example", "message": "Hello"},
+			errors: []string{"Email format is invalid"},
+		},
+		{
+			name:   "too long",
+			data:   map[string]string{"name": strings.Repeat("a", MaxNameLength+1), "email": "ada@example.com", "message": "Hello"},
+			errors: []string{"Name is too long (maximum 100 characters)"},
+		},
+		{
+			name:     "suspicious",
+			data:     map[string]string{"name": "Ada", "email": "ada@example.com", "message": "Click here for free money"},
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateSubmission(tt.data)
+			if result.Valid != (len(tt.errors) == 0) {
+				t.Errorf("Valid = %v, errors %v", result.Valid, result.Errors)
+			}
+			if strings.Join(result.Errors, "; ") != strings.Join(tt.errors, "; ") {
+				t.Errorf("errors = %v, want %v", result.Errors, tt.errors)
+			}
+			if len(result.Warnings) != tt.warnings {
+				t.Errorf("warnings = %v, want %d", result.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestSanitizeInput(t *testing.T) {
+	if got := SanitizeInput("  Hi\x00 there\x07\n\tfriend  "); got != "Hi there\n\tfriend" {
+		t.Errorf("SanitizeInput = %q", got)
+	}
+}
+
+func TestCheckHoneypot(t *testing.T) {
+	if _, trapped := CheckHoneypot(map[string]string{"name": "Ada", "website": " "}); trapped {
+		t.Error("a blank honeypot field must not be treated as spam")
+	}
+	if field, trapped := CheckHoneypot(map[string]string{"bot_field": "x"}); !trapped || field != "bot_field" {
+		t.Errorf("CheckHoneypot = %q, %v", field, trapped)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{PerMinute: 2, PerHour: 3, PerDay: 10})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	limiter.Record("10.0.0.1", now)
+	limiter.Record("10.0.0.1", now.Add(10*time.Second))
+	check := limiter.Check("10.0.0.1", now.Add(20*time.Second))
+	if check.Allowed || check.Violations[0].Window != "minute" || check.Violations[0].RetryAfter() != 60 {
+		t.Fatalf("expected a per-minute violation, got %+v", check)
+	}
+	if !limiter.Check("10.0.0.2", now).Allowed {
+		t.Error("limits must apply per client")
+	}
+
+	limiter.Record("10.0.0.1", now.Add(2*time.Minute))
+	check = limiter.Check("10.0.0.1", now.Add(5*time.Minute))
+	if check.Allowed || check.Violations[0].Window != "hour" {
+		t.Fatalf("expected a per-hour violation, got %+v", check)
+	}
+
+	if check := limiter.Check("10.0.0.1", now.Add(25*time.Hour)); !check.Allowed || check.Day != 0 {
+		t.Errorf("submissions older than a day must expire, got %+v", check)
+	}
+	if stats := limiter.Stats(now.Add(25 * time.Hour)); stats.TotalIPs != 0 || stats.TotalSubmissions != 0 {
+		t.Errorf("unexpected stats after expiry: %+v", stats)
+	}
+}
@@ -0,0 +1,484 @@
+package forms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWebhookDir holds the webhook queue, relative to the project
+const DefaultWebhookDir = ".garp/webhooks"
+
+// Webhook delivery defaults
+const (
+	DefaultWebhookAttempts = 8
+	DefaultWebhookBackoff  = 30 * time.Second
+	maxWebhookBackoff      = time.Hour
+	webhookTimeout         = 10 * time.Second
+	webhookPollInterval    = time.Minute
+)
+
+// Headers of a webhook request. The signature is "sha256=" followed by the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+const (
+	SignatureHeader = "X-Garp-Signature"
+	TimestampHeader = "X-Garp-Timestamp"
+	EventHeader     = "X-Garp-Event"
+	DeliveryHeader  = "X-Garp-Delivery"
+)
+
+// SubmissionEvent is the event of a form submission webhook
+const SubmissionEvent = "form.submission"
+
+// Subdirectories of the webhook directory
+const (
+	webhookQueueDir = "queue"
+	webhookDeadDir  = "dead"
+)
+
+// WebhookConfig configures webhook delivery
+type WebhookConfig struct {
+	URLs        []string
+	Secret      string        // signs every request
+	Dir         string        // defaults to DefaultWebhookDir
+	MaxAttempts int           // defaults to DefaultWebhookAttempts
+	Backoff     time.Duration // first retry delay, doubled per attempt; defaults to DefaultWebhookBackoff
+	Timeout     time.Duration // per request, defaults to 10s
+	UserAgent   string
+}
+
+// Validate checks that every URL is an absolute http(s) URL and that a
+// secret is set to sign the requests
+func (c WebhookConfig) Validate() error {
+	for _, raw := range c.URLs {
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q: must be an http or https URL", raw)
+		}
+	}
+	if len(c.URLs) > 0 && c.Secret == "" {
+		return fmt.Errorf("a webhook secret is required to sign webhooks")
+	}
+	return nil
+}
+
+// WebhookPayload is the JSON body of a submission webhook
+type WebhookPayload struct {
+	Event      string            `json:"event"`
+	ID         string            `json:"id"`
+	Form       string            `json:"form"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Fields     map[string]string `json:"fields"`
+	Warnings   []string          `json:"warnings,omitempty"`
+}
+
+// Delivery is a webhook request waiting in the queue or given up on
+type Delivery struct {
+	ID           string          `json:"id"`
+	URL          string          `json:"url"`
+	SubmissionID string          `json:"submission_id"`
+	Form         string          `json:"form"`
+	Payload      json.RawMessage `json:"payload"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Attempts     int             `json:"attempts"`
+	NextAttempt  time.Time       `json:"next_attempt"`
+	LastAttempt  time.Time       `json:"last_attempt"`
+	LastStatus   int             `json:"last_status,omitempty"`
+	LastError    string          `json:"last_error,omitempty"`
+}
+
+// WebhookResult counts the outcome of the attempted deliveries
+type WebhookResult struct {
+	Delivered int
+	Retrying  int
+	Dead      int
+}
+
+// WebhookQueue delivers submissions to webhooks. Every delivery is a JSON
+// file in the queue directory until it succeeds; deliveries that fail
+// MaxAttempts times are moved to the dead letter directory.
+type WebhookQueue struct {
+	config WebhookConfig
+	client *http.Client
+	now    func() time.Time
+	log    *submissionLog // optional, set by the server
+
+	mu   sync.Mutex // serializes delivery attempts
+	wake chan struct{}
+}
+
+// NewWebhookQueue returns the queue in config.Dir. Directories are created
+// on the first write, so reading the queue leaves the project untouched.
+func NewWebhookQueue(config WebhookConfig) *WebhookQueue {
+	if config.Dir == "" {
+		config.Dir = DefaultWebhookDir
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultWebhookAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultWebhookBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = webhookTimeout
+	}
+	return &WebhookQueue{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Dir returns the directory holding the queue and the dead letters
+func (q *WebhookQueue) Dir() string {
+	return q.config.Dir
+}
+
+// Enqueue queues a delivery of the submission to every webhook URL
+func (q *WebhookQueue) Enqueue(submission Submission) ([]Delivery, error) {
+	payload, err := json.Marshal(WebhookPayload{
+		Event:      SubmissionEvent,
+		ID:         submission.ID,
+		Form:       submission.Form,
+		ReceivedAt: submission.ReceivedAt,
+		Fields:     submission.Fields,
+		Warnings:   submission.Warnings,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := q.now()
+	var deliveries []Delivery
+	for _, target := range q.config.URLs {
+		delivery := Delivery{
+			ID:           generateDeliveryID(now),
+			URL:          target,
+			SubmissionID: submission.ID,
+			Form:         submission.Form,
+			Payload:      payload,
+			CreatedAt:    now,
+			NextAttempt:  now,
+		}
+		if err := q.save(webhookQueueDir, delivery); err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return deliveries, nil
+}
+
+// Run delivers queued webhooks until ctx is cancelled, including deliveries
+// left in the queue by an earlier run
+func (q *WebhookQueue) Run(ctx context.Context) {
+	for {
+		q.ProcessDue(ctx)
+
+		wait := webhookPollInterval
+		if pending, err := q.Pending(); err == nil {
+			for _, delivery := range pending {
+				if until := delivery.NextAttempt.Sub(q.now()); until < wait {
+					wait = until
+				}
+			}
+		}
+		if wait < 0 {
+			wait = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// ProcessDue attempts every queued delivery that is due
+func (q *WebhookQueue) ProcessDue(ctx context.Context) WebhookResult {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result WebhookResult
+	pending, err := q.Pending()
+	if err != nil {
+		q.logEntry("error", map[string]interface{}{
+			"status": "webhook_queue_failed",
+			"error":  err.Error(),
+		})
+		return result
+	}
+
+	for _, delivery := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		if delivery.NextAttempt.After(q.now()) {
+			continue
+		}
+
+		status, err := q.deliver(ctx, delivery)
+		if err != nil && ctx.Err() != nil {
+			// Shutting down, the attempt does not count
+			break
+		}
+		delivery.Attempts++
+		delivery.LastAttempt = q.now()
+		delivery.LastStatus = status
+
+		if err == nil {
+			result.Delivered++
+			q.logEntry("info", map[string]interface{}{
+				"submission_id": delivery.SubmissionID,
+				"delivery_id":   delivery.ID,
+				"url":           delivery.URL,
+				"attempts":      delivery.Attempts,
+				"status":        "webhook_delivered",
+			})
+			os.Remove(q.path(webhookQueueDir, delivery.ID))
+			continue
+		}
+
+		delivery.LastError = err.Error()
+		entry := map[string]interface{}{
+			"submission_id": delivery.SubmissionID,
+			"delivery_id":   delivery.ID,
+			"url":           delivery.URL,
+			"attempts":      delivery.Attempts,
+			"error":         err.Error(),
+		}
+
+		if delivery.Attempts >= q.config.MaxAttempts {
+			result.Dead++
+			entry["status"] = "webhook_dead"
+			err = q.save(webhookQueueDir, delivery)
+			if err == nil {
+				err = q.move(delivery.ID, webhookQueueDir, webhookDeadDir)
+			}
+		} else {
+			result.Retrying++
+			delivery.NextAttempt = delivery.LastAttempt.Add(q.backoff(delivery.Attempts))
+			entry["status"] = "webhook_failed"
+			entry["next_attempt"] = delivery.NextAttempt.Format(time.RFC3339)
+			err = q.save(webhookQueueDir, delivery)
+		}
+		if err != nil {
+			entry["queue_error"] = err.Error()
+		}
+		q.logEntry("warn", entry)
+	}
+	return result
+}
+
+// Pending returns the queued deliveries, oldest first
+func (q *WebhookQueue) Pending() ([]Delivery, error) {
+	return q.list(webhookQueueDir)
+}
+
+// Dead returns the deliveries that were given up on, oldest first
+func (q *WebhookQueue) Dead() ([]Delivery, error) {
+	return q.list(webhookDeadDir)
+}
+
+// Retry moves the given dead letters, or all of them, back into the queue
+// to be delivered right away with a fresh set of attempts
+func (q *WebhookQueue) Retry(ids ...string) (int, error) {
+	dead, err := q.Dead()
+	if err != nil {
+		return 0, err
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	retried := 0
+	for _, delivery := range dead {
+		if len(ids) > 0 && !wanted[delivery.ID] {
+			continue
+		}
+		delete(wanted, delivery.ID)
+
+		delivery.Attempts = 0
+		delivery.NextAttempt = q.now()
+		if err := q.save(webhookDeadDir, delivery); err != nil {
+			return retried, err
+		}
+		if err := q.move(delivery.ID, webhookDeadDir, webhookQueueDir); err != nil {
+			return retried, err
+		}
+		retried++
+	}
+
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for id := range wanted {
+			missing = append(missing, id)
+		}
+		sort.Strings(missing)
+		return retried, fmt.Errorf("no dead letter with ID %s", strings.Join(missing, ", "))
+	}
+	return retried, nil
+}
+
+// deliver posts the payload of a delivery, returning the response status
+func (q *WebhookQueue) deliver(ctx context.Context, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := q.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, SubmissionEvent)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(q.config.Secret, timestamp, delivery.Payload))
+	if q.config.UserAgent != "" {
+		req.Header.Set("User-Agent", q.config.UserAgent)
+	}
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return 0, fmt.Errorf("timed out after %v", q.config.Timeout)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		return resp.StatusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if message := strings.Join(strings.Fields(string(body)), " "); message != "" {
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, message)
+	}
+	return resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (q *WebhookQueue) backoff(attempts int) time.Duration {
+	delay := q.config.Backoff
+	for i := 1; i < attempts && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxWebhookBackoff {
+		delay = maxWebhookBackoff
+	}
+	return delay
+}
+
+func (q *WebhookQueue) path(dir, id string) string {
+	return filepath.Join(q.config.Dir, dir, id+".json")
+}
+
+// save writes a delivery through a temporary file, so a crash never leaves
+// a partial delivery behind
+func (q *WebhookQueue) save(dir string, delivery Delivery) error {
+	data, err := json.MarshalIndent(delivery, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := q.path(dir, delivery.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create webhook queue: %v", err)
+	}
+	tmp := filepath.Join(filepath.Dir(path), "."+delivery.ID+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write webhook delivery: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// move moves a delivery between the queue and dead letter directories
+func (q *WebhookQueue) move(id, from, to string) error {
+	if err := os.MkdirAll(filepath.Join(q.config.Dir, to), 0700); err != nil {
+		return fmt.Errorf("failed to create webhook queue: %v", err)
+	}
+	return os.Rename(q.path(from, id), q.path(to, id))
+}
+
+func (q *WebhookQueue) list(dir string) ([]Delivery, error) {
+	entries, err := os.ReadDir(filepath.Join(q.config.Dir, dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook queue: %v", err)
+	}
+
+	var deliveries []Delivery
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(q.config.Dir, dir, entry.Name()))
+		if err != nil {
+			// Delivered and removed since the directory was read
+			continue
+		}
+		var delivery Delivery
+		if err := json.Unmarshal(data, &delivery); err != nil || delivery.ID == "" {
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	return deliveries, nil
+}
+
+func (q *WebhookQueue) logEntry(level string, entry map[string]interface{}) {
+	if q.log != nil {
+		q.log.Write(level, entry)
+	}
+}
+
+// Sign returns the signature header of a webhook body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateDeliveryID returns an ID such as whd_1722350000_9f86d081
+func generateDeliveryID(now time.Time) string {
+	random := make([]byte, 4)
+	rand.Read(random)
+	return fmt.Sprintf("whd_%d_%s", now.Unix(), hex.EncodeToString(random))
+}
@@ -0,0 +1,287 @@
+package forms
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// webhookReceiver is an httptest receiver that checks signatures and fails
+// the requests for which fail returns true
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  int
+	delivered map[string]int // successful requests per delivery ID
+	payloads  []WebhookPayload
+}
+
+func newWebhookReceiver(t *testing.T, fail func(request int) bool) *webhookReceiver {
+	t.Helper()
+
+	receiver := &webhookReceiver{delivered: make(map[string]int)}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if err != nil || r.Header.Get(SignatureHeader) != Sign(testWebhookSecret, timestamp, body) {
+			t.Errorf("invalid signature %q for timestamp %q", r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != SubmissionEvent || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.requests++
+		if fail(receiver.requests) {
+			http.Error(w, "receiver unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		var payload WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid payload %s: %v", body, err)
+		}
+		receiver.delivered[r.Header.Get(DeliveryHeader)]++
+		receiver.payloads = append(receiver.payloads, payload)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) count() (requests, delivered int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests, len(r.delivered)
+}
+
+// newTestWebhookQueue returns a queue in a temporary directory with a clock
+// that only moves when the returned function is called
+func newTestWebhookQueue(t *testing.T, maxAttempts int, urls ...string) (*WebhookQueue, func(time.Duration)) {
+	t.Helper()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	queue := NewWebhookQueue(WebhookConfig{
+		URLs:        urls,
+		Secret:      testWebhookSecret,
+		Dir:         t.TempDir(),
+		MaxAttempts: maxAttempts,
+		Backoff:     time.Minute,
+	})
+	queue.now = func() time.Time { return now }
+	return queue, func(d time.Duration) { now = now.Add(d) }
+}
+
+var testSubmission = Submission{
+	ID:         "sub_1772366400_0a1b2c3d",
+	Form:       ContactFormName,
+	ReceivedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	Fields:     map[string]string{"name": "Ada", "email": "ada@example.com", "message": "Hello"},
+	Status:     StatusPending,
+}
+
+func TestSign(t *testing.T) {
+	want := "sha256=37a23aaf6ac1187ade1d4ebedd3b9848659f9fd92b14158aabefe40ab4ea8beb"
+	if got := Sign(testWebhookSecret, 1700000000, []byte(`{"id":"sub_1"}`)); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestWebhookConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config WebhookConfig
+		err    string
+	}{
+		{"no webhooks", WebhookConfig{}, ""},
+		{"valid", WebhookConfig{URLs: []string{"https://hooks.example.com/garp"}, Secret: "s"}, ""},
+		{"relative URL", WebhookConfig{URLs: []string{"/hooks"}, Secret: "s"}, `invalid webhook URL "/hooks"`},
+		{"other scheme", WebhookConfig{URLs: []string{"ftp://hooks.example.com"}, Secret: "s"}, "must be an http or https URL"},
+		{"no secret", WebhookConfig{URLs: []string{"https://hooks.example.com"}}, "webhook secret is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (tt.err == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("Validate() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWebhookQueueRetriesWithBackoff(t *testing.T) {
+	// The first two requests fail
+	receiver := newWebhookReceiver(t, func(request int) bool { return request <= 2 })
+	queue, advance := newTestWebhookQueue(t, 5, receiver.URL)
+
+	deliveries, err := queue.Enqueue(testSubmission)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("Enqueue() = %v, %v", deliveries, err)
+	}
+
+	ctx := context.Background()
+	if result := queue.ProcessDue(ctx); result != (WebhookResult{Retrying: 1}) {
+		t.Fatalf("first attempt = %+v", result)
+	}
+	pending, _ := queue.Pending()
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastStatus != http.StatusServiceUnavailable ||
+		pending[0].LastError != "HTTP 503: receiver unavailable" {
+		t.Fatalf("unexpected queue after a failure %+v", pending)
+	}
+	if !pending[0].NextAttempt.Equal(pending[0].LastAttempt.Add(time.Minute)) {
+		t.Errorf("first retry at %v, want a minute after %v", pending[0].NextAttempt, pending[0].LastAttempt)
+	}
+
+	// Nothing is attempted before the retry is due
+	advance(59 * time.Second)
+	if result := queue.ProcessDue(ctx); result != (WebhookResult{}) {
+		t.Fatalf("attempted a delivery that was not due: %+v", result)
+	}
+
+	advance(time.Second)
+	if result := queue.ProcessDue(ctx); result != (WebhookResult{Retrying: 1}) {
+		t.Fatalf("second attempt = %+v", result)
+	}
+	pending, _ = queue.Pending()
+	if !pending[0].NextAttempt.Equal(pending[0].LastAttempt.Add(2 * time.Minute)) {
+		t.Errorf("second retry at %v, want two minutes after %v", pending[0].NextAttempt, pending[0].LastAttempt)
+	}
+
+	// A new queue in the same directory picks up the pending delivery
+	restarted := NewWebhookQueue(queue.config)
+	restarted.now = queue.now
+	advance(2 * time.Minute)
+	if result := restarted.ProcessDue(ctx); result != (WebhookResult{Delivered: 1}) {
+		t.Fatalf("third attempt = %+v", result)
+	}
+
+	if requests, delivered := receiver.count(); requests != 3 || delivered != 1 {
+		t.Errorf("receiver got %d requests and %d deliveries, want 3 and 1", requests, delivered)
+	}
+	if payload := receiver.payloads[0]; payload.ID != testSubmission.ID || payload.Event != SubmissionEvent || payload.Fields["name"] != "Ada" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+	if pending, _ := queue.Pending(); len(pending) != 0 {
+		t.Errorf("delivered webhook is still queued: %+v", pending)
+	}
+}
+
+func TestWebhookQueueDeadLetters(t *testing.T) {
+	down := true
+	receiver := newWebhookReceiver(t, func(int) bool { return down })
+	queue, advance := newTestWebhookQueue(t, 3, receiver.URL)
+
+	if _, err := queue.Enqueue(testSubmission); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var result WebhookResult
+	for i := 0; i < 3; i++ {
+		result = queue.ProcessDue(ctx)
+		advance(time.Hour)
+	}
+	if result != (WebhookResult{Dead: 1}) {
+		t.Fatalf("last attempt = %+v", result)
+	}
+
+	pending, _ := queue.Pending()
+	dead, err := queue.Dead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 || len(dead) != 1 || dead[0].Attempts != 3 || dead[0].SubmissionID != testSubmission.ID {
+		t.Fatalf("unexpected queue %+v and dead letters %+v", pending, dead)
+	}
+
+	// Dead letters are not attempted again until they are retried
+	if result := queue.ProcessDue(ctx); result != (WebhookResult{}) {
+		t.Errorf("attempted a dead letter: %+v", result)
+	}
+	if _, err := queue.Retry("whd_missing"); err == nil {
+		t.Error("expected an error for an unknown dead letter")
+	}
+
+	down = false
+	if retried, err := queue.Retry(); err != nil || retried != 1 {
+		t.Fatalf("Retry() = %d, %v", retried, err)
+	}
+	if result := queue.ProcessDue(ctx); result != (WebhookResult{Delivered: 1}) {
+		t.Fatalf("retried delivery = %+v", result)
+	}
+	if dead, _ := queue.Dead(); len(dead) != 0 {
+		t.Errorf("retried dead letter is still dead: %+v", dead)
+	}
+}
+
+func TestWebhookQueueRun(t *testing.T) {
+	// Every other request fails
+	flaky := newWebhookReceiver(t, func(request int) bool { return request%2 == 1 })
+	steady := newWebhookReceiver(t, func(int) bool { return false })
+
+	queue := NewWebhookQueue(WebhookConfig{
+		URLs:    []string{flaky.URL, steady.URL},
+		Secret:  testWebhookSecret,
+		Dir:     t.TempDir(),
+		Backoff: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	const submissions = 5
+	for i := 0; i < submissions; i++ {
+		submission := testSubmission
+		submission.ID = "sub_" + strconv.Itoa(i)
+		if _, err := queue.Enqueue(submission); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, flakyDelivered := flaky.count()
+		_, steadyDelivered := steady.count()
+		pending, _ := queue.Pending()
+		if flakyDelivered == submissions && steadyDelivered == submissions && len(pending) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivered %d and %d of %d webhooks, %d still queued", flakyDelivered, steadyDelivered, submissions, len(pending))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, receiver := range []*webhookReceiver{flaky, steady} {
+		receiver.mu.Lock()
+		for id, count := range receiver.delivered {
+			if count != 1 {
+				t.Errorf("delivery %s was received %d times", id, count)
+			}
+		}
+		receiver.mu.Unlock()
+	}
+	if dead, _ := queue.Dead(); len(dead) != 0 {
+		t.Errorf("unexpected dead letters %+v", dead)
+	}
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectLayout describes where a project's sources and build outputs live.
+// Every command (build, serve, deploy, init) resolves paths through it so
+// they all agree on the project structure.
+type ProjectLayout struct {
+	SourceDir    string // Authored content and assets
+	OutputDir    string // Directory served in production and shipped by deployers
+	CSSInput     string // Tailwind CSS source file
+	CSSOutput    string // Compiled stylesheet
+	SearchOutput string // Pagefind search index directory
+	StaticDir    string // Rendered site written by 'garp build --static'
+}
+
+// DefaultProjectLayout returns the layout created by 'garp init'
+func DefaultProjectLayout() ProjectLayout {
+	return ProjectLayout{
+		SourceDir:    "public",
+		OutputDir:    "public",
+		CSSInput:     filepath.Join("public", "css", "input.css"),
+		CSSOutput:    filepath.Join("public", "css", "style.css"),
+		SearchOutput: filepath.Join("public", "_pagefind"),
+		StaticDir:    "dist",
+	}
+}
+
+// Active project layout, replaced when project configuration is loaded
+var projectLayout = DefaultProjectLayout()
+
+// GetProjectLayout returns the layout of the current project
+func GetProjectLayout() ProjectLayout {
+	return projectLayout
+}
+
+// SetProjectLayout replaces the layout of the current project
+func SetProjectLayout(layout ProjectLayout) {
+	projectLayout = layout
+}
+
+// Env returns the layout as environment variables for the bin/ build scripts
+func (l ProjectLayout) Env() []string {
+	return []string{
+		"GARP_SOURCE_DIR=" + l.SourceDir,
+		"GARP_OUTPUT_DIR=" + l.OutputDir,
+		"GARP_CSS_INPUT=" + l.CSSInput,
+		"GARP_CSS_OUTPUT=" + l.CSSOutput,
+		"GARP_SEARCH_OUTPUT=" + l.SearchOutput,
+	}
+}
+
+// OutputPath returns a path relative to the output directory, e.g.
+// "public/css/style.css" becomes "css/style.css". Paths in the source
+// directory are accepted too since the output mirrors the source tree.
+func (l ProjectLayout) OutputPath(path string) (string, error) {
+	if rel, ok := relativeTo(l.OutputDir, path); ok {
+		return rel, nil
+	}
+	if rel, ok := relativeTo(l.SourceDir, path); ok {
+		return rel, nil
+	}
+	return "", NewConfigurationError(fmt.Sprintf("%s is outside the output directory %s", path, l.OutputDir))
+}
+
+// Static returns the layout used when the site is rendered to plain HTML:
+// the static directory becomes the output directory and the search index
+// is built inside it
+func (l ProjectLayout) Static() ProjectLayout {
+	static := l
+	static.OutputDir = l.StaticDir
+	if rel, ok := relativeTo(l.SourceDir, l.SearchOutput); ok {
+		static.SearchOutput = filepath.Join(l.StaticDir, rel)
+	}
+	return static
+}
+
+// IsStatic reports whether the layout renders the source into a separate
+// output directory
+func (l ProjectLayout) IsStatic() bool {
+	return filepath.Clean(l.OutputDir) != filepath.Clean(l.SourceDir)
+}
+
+// relativeTo returns path relative to root when path is inside root
+func relativeTo(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// Within returns a copy of the layout rooted at the given directory, used
+// when creating a project in a subdirectory
+func (l ProjectLayout) Within(root string) ProjectLayout {
+	return ProjectLayout{
+		SourceDir:    filepath.Join(root, l.SourceDir),
+		OutputDir:    filepath.Join(root, l.OutputDir),
+		CSSInput:     filepath.Join(root, l.CSSInput),
+		CSSOutput:    filepath.Join(root, l.CSSOutput),
+		SearchOutput: filepath.Join(root, l.SearchOutput),
+		StaticDir:    filepath.Join(root, l.StaticDir),
+	}
+}
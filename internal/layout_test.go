@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectLayoutOutputPath(t *testing.T) {
+	layout := DefaultProjectLayout()
+
+	rel, err := layout.OutputPath(layout.CSSOutput)
+	if err != nil {
+		t.Fatalf("OutputPath() error = %v", err)
+	}
+	if rel != filepath.Join("css", "style.css") {
+		t.Errorf("OutputPath() = %s, want css/style.css", rel)
+	}
+
+	if _, err := layout.OutputPath("bin/build-css"); err == nil {
+		t.Error("OutputPath() should reject paths outside the output directory")
+	}
+}
+
+func TestProjectLayoutWithin(t *testing.T) {
+	layout := DefaultProjectLayout().Within("my-site")
+
+	if layout.SourceDir != filepath.Join("my-site", "public") {
+		t.Errorf("SourceDir = %s, want my-site/public", layout.SourceDir)
+	}
+	if layout.CSSInput != filepath.Join("my-site", "public", "css", "input.css") {
+		t.Errorf("CSSInput = %s, want my-site/public/css/input.css", layout.CSSInput)
+	}
+}
+
+func TestProjectLayoutEnv(t *testing.T) {
+	env := DefaultProjectLayout().Env()
+
+	expected := map[string]bool{
+		"GARP_SOURCE_DIR=public": true,
+		"GARP_CSS_OUTPUT=" + filepath.Join("public", "css", "style.css"): true,
+	}
+	found := 0
+	for _, entry := range env {
+		if expected[entry] {
+			found++
+		}
+	}
+	if found != len(expected) {
+		t.Errorf("Env() = %v, missing expected entries", env)
+	}
+}
+
+func TestProjectLayoutStatic(t *testing.T) {
+	layout := DefaultProjectLayout().Static()
+
+	if layout.OutputDir != "dist" || !layout.IsStatic() {
+		t.Errorf("OutputDir = %s, want dist", layout.OutputDir)
+	}
+	if layout.SearchOutput != filepath.Join("dist", "_pagefind") {
+		t.Errorf("SearchOutput = %s, want dist/_pagefind", layout.SearchOutput)
+	}
+
+	// The compiled stylesheet lives in the source tree and is copied over
+	rel, err := layout.OutputPath(layout.CSSOutput)
+	if err != nil || rel != filepath.Join("css", "style.css") {
+		t.Errorf("OutputPath(CSSOutput) = %s, %v, want css/style.css", rel, err)
+	}
+
+	if DefaultProjectLayout().IsStatic() {
+		t.Error("default layout should not be static")
+	}
+}
@@ -0,0 +1,230 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// ArchetypeDir holds project archetypes that override the built-in ones
+const ArchetypeDir = "archetypes"
+
+// DefaultPostDir is where posts are created inside the source directory
+const DefaultPostDir = "blog"
+
+// archetypeRoot holds the built-in archetype for each content kind
+const archetypeRoot = templateRoot + "/archetypes"
+
+// ContentKinds returns the content kinds that have a built-in archetype
+func ContentKinds() []string {
+	entries, _ := templateFS.ReadDir(archetypeRoot)
+	var kinds []string
+	for _, entry := range entries {
+		kinds = append(kinds, strings.TrimSuffix(entry.Name(), ".md"))
+	}
+	return kinds
+}
+
+// NewContent describes a page, post or section to create from an archetype
+type NewContent struct {
+	Kind   string    // page, post or section
+	Name   string    // title or path, e.g. "about", "My Title" or "docs/Getting Started"
+	Dir    string    // directory inside the source directory, defaults by kind
+	Author string    // author written to the front matter
+	Draft  bool      // mark the new content as a draft
+	Date   time.Time // publication date, defaults to now
+}
+
+// CreatedContent describes the file written by Create
+type CreatedContent struct {
+	Path      string // path of the new file
+	RelPath   string // path relative to the source directory
+	Archetype string // archetype file used, or "built-in"
+}
+
+// Create writes the new content file into the source directory of layout
+func (nc NewContent) Create(layout internal.ProjectLayout) (*CreatedContent, error) {
+	archetype, archetypeSource, err := LoadArchetype(nc.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, title, err := nc.target()
+	if err != nil {
+		return nil, err
+	}
+
+	date := nc.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	data := TemplateData{
+		Title:  title,
+		Slug:   Slugify(title),
+		Date:   date.Format(time.RFC3339),
+		Author: nc.Author,
+		Draft:  nc.Draft,
+		Year:   date.Year(),
+	}
+	text, err := renderTemplate(archetypeSource, archetype, data)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(layout.SourceDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, internal.NewFileSystemError(
+			fmt.Sprintf("failed to create directory: %s", filepath.Dir(path)),
+			err,
+		)
+	}
+	if err := createFile(path, text); err != nil {
+		return nil, err
+	}
+
+	return &CreatedContent{Path: path, RelPath: rel, Archetype: archetypeSource}, nil
+}
+
+// target returns the file path relative to the source directory and the title
+func (nc NewContent) target() (string, string, error) {
+	name := strings.Trim(filepath.ToSlash(strings.TrimSpace(nc.Name)), "/")
+	name = strings.TrimSuffix(name, ".md")
+	if name == "" {
+		return "", "", internal.NewValidationError("a name is required, e.g. 'garp new page about'")
+	}
+
+	dir, base := "", name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		dir, base = name[:i], name[i+1:]
+	}
+	if dir == "" {
+		dir = nc.Dir
+		if dir == "" && nc.Kind == "post" {
+			dir = DefaultPostDir
+		}
+	}
+	for _, part := range strings.Split(filepath.ToSlash(dir), "/") {
+		if part == ".." {
+			return "", "", internal.NewValidationError(fmt.Sprintf("content must stay inside the source directory: %s", nc.Name))
+		}
+	}
+
+	slug := Slugify(base)
+	if slug == "" {
+		return "", "", internal.NewValidationError(fmt.Sprintf("cannot create a file name from %q", base))
+	}
+
+	title := base
+	if base == slug {
+		title = titleFromSlug(slug)
+	}
+
+	rel := filepath.Join(filepath.FromSlash(dir), slug+".md")
+	if nc.Kind == "section" {
+		rel = filepath.Join(filepath.FromSlash(dir), slug, "index.md")
+	}
+	return rel, title, nil
+}
+
+// LoadArchetype returns the archetype for a content kind and where it came
+// from. A file in the project's archetypes directory takes precedence over
+// the built-in archetype.
+func LoadArchetype(kind string) (string, string, error) {
+	path := filepath.Join(ArchetypeDir, kind+".md")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return string(data), path, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", "", internal.NewFileSystemError(fmt.Sprintf("failed to read archetype: %s", path), err)
+	}
+
+	archetype, err := templateFS.ReadFile(archetypeRoot + "/" + kind + ".md")
+	if err != nil {
+		return "", "", internal.NewValidationErrorWithSuggestions(
+			fmt.Sprintf("unknown content kind: %s", kind),
+			[]string{
+				fmt.Sprintf("Use one of: %s", strings.Join(ContentKinds(), ", ")),
+				fmt.Sprintf("Or add %s to the project", path),
+			},
+		)
+	}
+	return string(archetype), "built-in", nil
+}
+
+// CreateArchetypeFiles writes the built-in archetypes into the new project so
+// they can be customized
+func (ps *ProjectStructure) CreateArchetypeFiles() error {
+	dir := filepath.Join(ps.ProjectName, ArchetypeDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("failed to create directory: %s", dir), err)
+	}
+
+	for _, kind := range ContentKinds() {
+		path := filepath.Join(dir, kind+".md")
+		if ps.skipExisting(path) {
+			continue
+		}
+		archetype, err := templateFS.ReadFile(archetypeRoot + "/" + kind + ".md")
+		if err != nil {
+			return internal.NewFileSystemError(fmt.Sprintf("missing embedded archetype: %s", kind), err)
+		}
+		if err := createFile(path, string(archetype)); err != nil {
+			return err
+		}
+		fmt.Printf("Created file: %s\n", path)
+	}
+	return nil
+}
+
+// createFile writes content to a new file, refusing to overwrite
+func createFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return internal.NewValidationError(fmt.Sprintf("file already exists: %s", path))
+		}
+		return internal.NewFileSystemError(fmt.Sprintf("failed to create file: %s", path), err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("failed to write file: %s", path), err)
+	}
+	return nil
+}
+
+// Slugify turns a title into a lowercase, hyphen-separated file name
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// titleFromSlug turns "getting-started" into "Getting Started"
+func titleFromSlug(slug string) string {
+	words := strings.Split(slug, "-")
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
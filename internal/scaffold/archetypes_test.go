@@ -0,0 +1,131 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/content"
+)
+
+// chdir switches into dir for the duration of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"My Title":              "my-title",
+		"  Hello, World!  ":     "hello-world",
+		"Go 1.24 release notes": "go-1-24-release-notes",
+		"Café au lait":          "café-au-lait",
+		"already-a-slug":        "already-a-slug",
+		"!!!":                   "",
+	}
+	for input, expected := range tests {
+		if got := Slugify(input); got != expected {
+			t.Errorf("Slugify(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestNewContentCreate(t *testing.T) {
+	chdir(t, t.TempDir())
+	layout := internal.DefaultProjectLayout()
+	date := time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		request NewContent
+		path    string
+		title   string
+	}{
+		{NewContent{Kind: "page", Name: "about", Draft: true}, "about.md", "About"},
+		{NewContent{Kind: "page", Name: "docs/getting-started"}, filepath.Join("docs", "getting-started.md"), "Getting Started"},
+		{NewContent{Kind: "post", Name: `My "Big" Title`, Author: "Jane", Date: date, Draft: true}, filepath.Join("blog", "my-big-title.md"), `My "Big" Title`},
+		{NewContent{Kind: "post", Name: "Launch", Dir: "news", Date: date}, filepath.Join("news", "launch.md"), "Launch"},
+		{NewContent{Kind: "section", Name: "Case Studies"}, filepath.Join("case-studies", "index.md"), "Case Studies"},
+	}
+
+	for _, test := range tests {
+		created, err := test.request.Create(layout)
+		if err != nil {
+			t.Fatalf("Create(%+v) returned error: %v", test.request, err)
+		}
+		if created.RelPath != test.path || created.Archetype != "built-in" {
+			t.Errorf("Create(%s) = %+v, want %s from the built-in archetype", test.request.Name, created, test.path)
+		}
+
+		page, err := content.LoadPage(layout.SourceDir, created.Path)
+		if err != nil {
+			t.Fatalf("%s: generated front matter is invalid: %v", test.path, err)
+		}
+		if page.Title != test.title {
+			t.Errorf("%s: title = %q, want %q", test.path, page.Title, test.title)
+		}
+		if page.Draft != test.request.Draft {
+			t.Errorf("%s: draft = %v, want %v", test.path, page.Draft, test.request.Draft)
+		}
+		if test.request.Kind == "post" {
+			if !page.Date.Equal(date) || page.Author != test.request.Author {
+				t.Errorf("%s: unexpected date/author %v/%q", test.path, page.Date, page.Author)
+			}
+		}
+	}
+
+	if _, err := (NewContent{Kind: "page", Name: "about"}).Create(layout); err == nil {
+		t.Error("Expected an error when the file already exists")
+	}
+}
+
+func TestNewContentProjectArchetype(t *testing.T) {
+	chdir(t, t.TempDir())
+	if err := os.MkdirAll(ArchetypeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	archetype := "+++\ntitle = \"{{.Title}}\"\nslug = \"{{.Slug}}\"\n+++\ncustom\n"
+	if err := os.WriteFile(filepath.Join(ArchetypeDir, "page.md"), []byte(archetype), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := NewContent{Kind: "page", Name: "Team {{.Slug}}"}.Create(internal.DefaultProjectLayout())
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if created.Archetype != filepath.Join(ArchetypeDir, "page.md") {
+		t.Errorf("Expected project archetype to be used, got %s", created.Archetype)
+	}
+
+	data, err := os.ReadFile(created.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "slug = \"team-slug\"") || !strings.HasSuffix(string(data), "custom\n") {
+		t.Errorf("Unexpected content:\n%s", data)
+	}
+}
+
+func TestNewContentErrors(t *testing.T) {
+	chdir(t, t.TempDir())
+	requests := map[string]NewContent{
+		"unknown kind":    {Kind: "recipe", Name: "soup"},
+		"empty name":      {Kind: "page", Name: "  "},
+		"no slug":         {Kind: "page", Name: "???"},
+		"outside sources": {Kind: "page", Name: "../escape"},
+	}
+	for name, request := range requests {
+		if _, err := request.Create(internal.DefaultProjectLayout()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -14,21 +14,15 @@ type ProjectStructure struct {
 	BasePath       string
 	EnableForms    bool
 	EnableSearch   bool
+	Layout         internal.ProjectLayout
+	Starter        string
+	Author         string
+	KeepExisting   bool // fill in missing files only, e.g. after an external starter
 }
 
 // CreateDirectories creates the complete directory structure for a new Garp project
 func (ps *ProjectStructure) CreateDirectories() error {
-	directories := []string{
-		ps.ProjectName,
-		filepath.Join(ps.ProjectName, "public"),
-		filepath.Join(ps.ProjectName, "public", "css"),
-		filepath.Join(ps.ProjectName, "public", "js"),
-		filepath.Join(ps.ProjectName, "public", "images"),
-		filepath.Join(ps.ProjectName, "public", "assets"),
-		filepath.Join(ps.ProjectName, "bin"),
-	}
-
-	for _, dir := range directories {
+	for _, dir := range ps.directories() {
 		if err := ps.createDirectory(dir); err != nil {
 			return err
 		}
@@ -86,15 +80,44 @@ func (ps *ProjectStructure) ValidateProjectPath() error {
 
 // GetProjectStructure returns the expected structure after creation
 func (ps *ProjectStructure) GetProjectStructure() []string {
-	return []string{
-		ps.ProjectName + "/",
-		ps.ProjectName + "/public/",
-		ps.ProjectName + "/public/css/",
-		ps.ProjectName + "/public/js/",
-		ps.ProjectName + "/public/images/",
-		ps.ProjectName + "/public/assets/",
-		ps.ProjectName + "/bin/",
+	var structure []string
+	for _, dir := range ps.directories() {
+		structure = append(structure, filepath.ToSlash(dir)+"/")
+	}
+	return structure
+}
+
+// directories lists the project directories in creation order, derived
+// from the project layout
+func (ps *ProjectStructure) directories() []string {
+	layout := ps.projectLayout()
+	candidates := []string{
+		ps.ProjectName,
+		layout.SourceDir,
+		filepath.Dir(layout.CSSInput),
+		filepath.Dir(layout.CSSOutput),
+		filepath.Join(layout.SourceDir, "js"),
+		filepath.Join(layout.SourceDir, "images"),
+		filepath.Join(layout.SourceDir, "assets"),
+		filepath.Join(ps.ProjectName, "bin"),
+		filepath.Join(ps.ProjectName, ArchetypeDir),
 	}
+
+	// Layout paths may coincide (e.g. CSS input and output share a folder)
+	seen := make(map[string]bool)
+	var directories []string
+	for _, dir := range candidates {
+		if !seen[dir] {
+			seen[dir] = true
+			directories = append(directories, dir)
+		}
+	}
+	return directories
+}
+
+// projectLayout returns the project layout rooted at the project directory
+func (ps *ProjectStructure) projectLayout() internal.ProjectLayout {
+	return ps.Layout.Within(ps.ProjectName)
 }
 
 // NewProjectStructure creates a new ProjectStructure instance
@@ -104,5 +127,7 @@ func NewProjectStructure(projectName string) *ProjectStructure {
 		BasePath:       ".",
 		EnableForms:    false,
 		EnableSearch:   true,
+		Layout:         internal.DefaultProjectLayout(),
+		Starter:        DefaultStarter,
 	}
 }
@@ -0,0 +1,103 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/forms"
+)
+
+// FormSnippetDir holds the HTML snippets of named forms inside the source
+// directory. The "_" prefix keeps them out of the rendered site.
+const FormSnippetDir = "_forms"
+
+// NewForm describes a named form to declare in garp.toml
+type NewForm struct {
+	Name   string // form name, e.g. "newsletter" or "Quote Request"
+	Server string // base URL of the form server, e.g. http://localhost:4567
+}
+
+// CreatedForm describes the files written by Create
+type CreatedForm struct {
+	Name        string // slug the form is served under
+	SnippetPath string // HTML snippet posting to the form
+}
+
+// Create writes an HTML snippet for the form into the source directory of
+// layout and appends a starter declaration of the form to configPath
+func (nf NewForm) Create(layout internal.ProjectLayout, configPath string) (*CreatedForm, error) {
+	schema := forms.Schema{
+		Name: Slugify(nf.Name),
+		Fields: []forms.Field{
+			{Name: "name", Type: forms.TextField, Required: true, MaxLength: forms.MaxNameLength},
+			{Name: "email", Type: forms.EmailField, Required: true, MaxLength: forms.MaxEmailLength},
+			{Name: "message", Type: forms.TextareaField, Required: true, MaxLength: forms.MaxMessageLength},
+		},
+	}
+	if schema.Name == "" {
+		return nil, internal.NewValidationError("a name is required, e.g. 'garp new form newsletter'")
+	}
+	schema.Title = schema.DisplayTitle()
+	schema.Subject = fmt.Sprintf("New %s submission from {{.name}}", strings.ToLower(schema.Title))
+	if err := schema.Validate(); err != nil {
+		return nil, internal.NewValidationError(err.Error())
+	}
+
+	snippet, err := schema.HTML(strings.TrimSuffix(nf.Server, "/") + "/forms/" + schema.Name)
+	if err != nil {
+		return nil, internal.NewValidationError(fmt.Sprintf("failed to render form snippet: %v", err))
+	}
+
+	dir := filepath.Join(layout.SourceDir, FormSnippetDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to create directory: %s", dir), err)
+	}
+	path := filepath.Join(dir, schema.Name+".html")
+	if err := createFile(path, snippet); err != nil {
+		return nil, err
+	}
+
+	if err := appendFile(configPath, formSchemaTOML(schema)); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	return &CreatedForm{Name: schema.Name, SnippetPath: path}, nil
+}
+
+// formSchemaTOML renders the declaration of a form for garp.toml
+func formSchemaTOML(schema forms.Schema) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n[forms.schemas.%s]\n", schema.Name)
+	fmt.Fprintf(&b, "title = %s\n", quoteString(schema.Title))
+	fmt.Fprintf(&b, "subject = %s\n", quoteString(schema.Subject))
+	b.WriteString("# recipient = \"\"     # defaults to forms.to_email\n")
+	b.WriteString("# redirect = \"\"      # where browsers go after submitting, e.g. \"/thanks/\"\n")
+	fmt.Fprintf(&b, "# Field types: %s; select fields also need options = [...]\n", strings.Join(forms.FieldTypes, ", "))
+
+	for _, field := range schema.Fields {
+		fmt.Fprintf(&b, "\n[[forms.schemas.%s.fields]]\n", schema.Name)
+		fmt.Fprintf(&b, "name = %s\n", quoteString(field.Name))
+		fmt.Fprintf(&b, "type = %s\n", quoteString(field.Type))
+		fmt.Fprintf(&b, "required = %t\n", field.Required)
+		fmt.Fprintf(&b, "max_length = %d\n", field.MaxLength)
+	}
+	return b.String()
+}
+
+// appendFile appends content to the file at path
+func appendFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("failed to open file: %s", path), err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("failed to write file: %s", path), err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/config"
+)
+
+func TestNewFormCreate(t *testing.T) {
+	chdir(t, t.TempDir())
+	layout := internal.DefaultProjectLayout()
+	original := "[forms]\nenabled = true\nport = 4567\n\n[deploy]\ntarget = \"git\"\n"
+	if err := os.WriteFile(config.FileName, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	request := NewForm{Name: "Quote Request", Server: "http://localhost:4567/"}
+	created, err := request.Create(layout, config.FileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "quote-request" {
+		t.Errorf("form name = %q, want quote-request", created.Name)
+	}
+
+	snippet, err := os.ReadFile(filepath.Join(layout.SourceDir, FormSnippetDir, "quote-request.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(snippet), `action="http://localhost:4567/forms/quote-request"`) {
+		t.Errorf("snippet does not post to the form:\n%s", snippet)
+	}
+
+	cfg, err := config.LoadFile(".")
+	if err != nil {
+		t.Fatalf("garp.toml no longer loads: %v", err)
+	}
+	schema, ok := cfg.Forms.Schemas["quote-request"]
+	if !ok {
+		t.Fatalf("form not declared, have %v", cfg.FormNames())
+	}
+	if schema.Title != "Quote Request" || schema.Subject != "New quote request submission from {{.name}}" {
+		t.Errorf("unexpected declaration %+v", schema)
+	}
+	if len(schema.Fields) != 3 || schema.Fields[1].Name != "email" || schema.Fields[1].Type != "email" || !schema.Fields[1].Required {
+		t.Errorf("unexpected fields %+v", schema.Fields)
+	}
+	if !cfg.Forms.Enabled || cfg.Deploy.Target != "git" {
+		t.Error("existing settings were lost")
+	}
+
+	// Creating the form again fails without declaring it twice
+	before, _ := os.ReadFile(config.FileName)
+	if _, err := request.Create(layout, config.FileName); err == nil {
+		t.Error("expected an error for an existing snippet")
+	}
+	if after, _ := os.ReadFile(config.FileName); string(after) != string(before) {
+		t.Error("garp.toml changed although the form was not created")
+	}
+
+	if _, err := (NewForm{Name: "!!!"}).Create(layout, config.FileName); err == nil {
+		t.Error("expected an error for a name without letters or digits")
+	}
+}
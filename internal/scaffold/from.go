@@ -0,0 +1,407 @@
+package scaffold
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mattsafaii/garp/internal"
+)
+
+// StarterManifestFile declares the variables and features of an external
+// starter. It is read from the starter root and never copied.
+const StarterManifestFile = "garp-starter.toml"
+
+// TemplateSuffix marks starter files that are rendered with text/template;
+// the suffix is removed from the generated file name
+const TemplateSuffix = ".tmpl"
+
+// StarterManifest is the content of garp-starter.toml
+type StarterManifest struct {
+	Name        string                    `toml:"name"`
+	Description string                    `toml:"description"`
+	Render      []string                  `toml:"render"`  // extra files rendered as templates
+	Exclude     []string                  `toml:"exclude"` // files that are never copied
+	Features    map[string]StarterFeature `toml:"features"`
+	Variables   []StarterVariable         `toml:"variables"`
+}
+
+// StarterFeature is a feature toggle, e.g. [features.forms]
+type StarterFeature struct {
+	Default *bool    `toml:"default"` // value used when no flag is given
+	Files   []string `toml:"files"`   // files only copied when the feature is enabled
+}
+
+// StarterVariable is a value asked for when the project is created and
+// available to templates as {{.Vars.<name>}}
+type StarterVariable struct {
+	Name     string `toml:"name"`
+	Prompt   string `toml:"prompt"`
+	Default  string `toml:"default"`
+	Required bool   `toml:"required"`
+}
+
+// starterClient downloads starter archives; the timeout keeps a stalled
+// server from hanging 'garp init --from'
+var starterClient = &http.Client{Timeout: 2 * time.Minute}
+
+// starterFeatures are the toggles a manifest may declare
+var starterFeatures = map[string]bool{"forms": true, "search": true}
+
+// StarterSource is an external starter unpacked on disk
+type StarterSource struct {
+	Dir      string
+	Manifest StarterManifest
+	tempDir  string
+}
+
+// OpenStarter resolves --from to a starter directory. from may be a
+// directory, a .tar.gz/.tgz archive or an http(s) URL of an archive.
+func OpenStarter(from string) (*StarterSource, error) {
+	source := &StarterSource{}
+
+	switch {
+	case strings.HasPrefix(from, "http://") || strings.HasPrefix(from, "https://"):
+		if err := source.download(from); err != nil {
+			source.Close()
+			return nil, err
+		}
+	default:
+		info, err := os.Stat(from)
+		if err != nil {
+			return nil, internal.NewFileSystemErrorWithContext("starter not found", from, err)
+		}
+		if info.IsDir() {
+			source.Dir = from
+		} else if err := source.extract(from); err != nil {
+			source.Close()
+			return nil, err
+		}
+	}
+
+	if err := source.loadManifest(); err != nil {
+		source.Close()
+		return nil, err
+	}
+	return source, nil
+}
+
+// Close removes any temporary files created for the starter
+func (s *StarterSource) Close() error {
+	if s.tempDir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.tempDir)
+}
+
+// download fetches a starter archive and extracts it
+func (s *StarterSource) download(url string) error {
+	resp, err := starterClient.Get(url)
+	if err != nil {
+		return internal.NewExternalError(fmt.Sprintf("failed to download starter: %s", url), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return internal.NewExternalError(
+			fmt.Sprintf("failed to download starter: %s", url),
+			fmt.Errorf("server responded with %s", resp.Status),
+		)
+	}
+	return s.extractReader(resp.Body, url)
+}
+
+// extract unpacks a .tar.gz archive into a temporary directory
+func (s *StarterSource) extract(archive string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("failed to open starter archive: %s", archive), err)
+	}
+	defer file.Close()
+	return s.extractReader(file, archive)
+}
+
+// extractReader unpacks a gzipped tarball. Archives with a single top-level
+// directory (as produced by most hosting services) are unwrapped.
+func (s *StarterSource) extractReader(r io.Reader, name string) error {
+	tempDir, err := os.MkdirTemp("", "garp-starter-")
+	if err != nil {
+		return internal.NewFileSystemError("failed to create temporary directory", err)
+	}
+	s.tempDir = tempDir
+	s.Dir = tempDir
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return internal.NewValidationError(fmt.Sprintf("starter is not a .tar.gz archive or directory: %s", name))
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return internal.NewValidationError(fmt.Sprintf("invalid starter archive %s: %v", name, err))
+		}
+
+		rel := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if rel == "." {
+			continue
+		}
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return internal.NewValidationError(fmt.Sprintf("starter archive contains an unsafe path: %s", header.Name))
+		}
+		target := filepath.Join(tempDir, filepath.FromSlash(rel))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return internal.NewFileSystemError(fmt.Sprintf("failed to create directory: %s", target), err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return internal.NewFileSystemError(fmt.Sprintf("failed to create directory: %s", filepath.Dir(target)), err)
+			}
+			if err := writeStarterFile(target, reader, os.FileMode(header.Mode).Perm()); err != nil {
+				return err
+			}
+		default:
+			internal.LogDebug("Skipping unsupported archive entry", "name", header.Name)
+		}
+	}
+
+	// Unwrap a single top-level directory
+	if _, err := os.Stat(filepath.Join(tempDir, StarterManifestFile)); os.IsNotExist(err) {
+		entries, err := os.ReadDir(tempDir)
+		if err == nil && len(entries) == 1 && entries[0].IsDir() {
+			s.Dir = filepath.Join(tempDir, entries[0].Name())
+		}
+	}
+	return nil
+}
+
+// loadManifest reads garp-starter.toml, if the starter has one
+func (s *StarterSource) loadManifest() error {
+	manifestPath := filepath.Join(s.Dir, StarterManifestFile)
+	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	metadata, err := toml.DecodeFile(manifestPath, &s.Manifest)
+	if err != nil {
+		return internal.NewConfigurationErrorWithSuggestions(
+			fmt.Sprintf("invalid %s: %v", StarterManifestFile, err),
+			[]string{"Check the TOML syntax of the starter manifest"},
+		)
+	}
+	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return internal.NewConfigurationError(fmt.Sprintf("unknown keys in %s: %s", StarterManifestFile, strings.Join(keys, ", ")))
+	}
+
+	for name := range s.Manifest.Features {
+		if !starterFeatures[name] {
+			return internal.NewConfigurationError(fmt.Sprintf("unknown feature in %s: %s (use forms or search)", StarterManifestFile, name))
+		}
+	}
+	for _, variable := range s.Manifest.Variables {
+		if variable.Name == "" {
+			return internal.NewConfigurationError(fmt.Sprintf("a variable in %s has no name", StarterManifestFile))
+		}
+	}
+	return nil
+}
+
+// FeatureDefault returns the manifest default for a feature toggle
+func (s *StarterSource) FeatureDefault(name string, fallback bool) bool {
+	if feature, ok := s.Manifest.Features[name]; ok && feature.Default != nil {
+		return *feature.Default
+	}
+	return fallback
+}
+
+// ResolveVariables combines values given on the command line with the
+// manifest defaults, asking for anything missing when prompt is not nil
+func (s *StarterSource) ResolveVariables(values map[string]string, prompt io.Reader, out io.Writer) (map[string]string, error) {
+	declared := make(map[string]bool)
+	for _, variable := range s.Manifest.Variables {
+		declared[variable.Name] = true
+	}
+	for name := range values {
+		if !declared[name] {
+			return nil, internal.NewValidationError(fmt.Sprintf("starter does not declare a variable named %q", name))
+		}
+	}
+
+	var scanner *bufio.Scanner
+	if prompt != nil {
+		scanner = bufio.NewScanner(prompt)
+	}
+
+	resolved := make(map[string]string)
+	for _, variable := range s.Manifest.Variables {
+		value, ok := values[variable.Name]
+		if !ok && scanner != nil {
+			label := variable.Prompt
+			if label == "" {
+				label = variable.Name
+			}
+			if variable.Default != "" {
+				fmt.Fprintf(out, "%s [%s]: ", label, variable.Default)
+			} else {
+				fmt.Fprintf(out, "%s: ", label)
+			}
+			if scanner.Scan() {
+				value = strings.TrimSpace(scanner.Text())
+			} else {
+				fmt.Fprintln(out)
+			}
+		}
+		if value == "" {
+			value = variable.Default
+		}
+		if value == "" && variable.Required {
+			return nil, internal.NewValidationErrorWithSuggestions(
+				fmt.Sprintf("starter variable %q is required", variable.Name),
+				[]string{fmt.Sprintf("Pass it with --var %s=<value>", variable.Name)},
+			)
+		}
+		resolved[variable.Name] = value
+	}
+	return resolved, nil
+}
+
+// CreateFromStarter copies an external starter into the project directory.
+// Files ending in .tmpl, and files matching the manifest's render patterns,
+// are rendered with text/template; everything else is copied as-is.
+func (ps *ProjectStructure) CreateFromStarter(source *StarterSource, vars map[string]string) error {
+	data, err := ps.templateData()
+	if err != nil {
+		return err
+	}
+	data.Starter = source.Manifest.Name
+	data.Vars = vars
+
+	// Files of disabled features are left out
+	skip := append([]string{}, source.Manifest.Exclude...)
+	for name, feature := range source.Manifest.Features {
+		if (name == "forms" && !ps.EnableForms) || (name == "search" && !ps.EnableSearch) {
+			skip = append(skip, feature.Files...)
+		}
+	}
+
+	if err := os.MkdirAll(ps.ProjectName, 0755); err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("failed to create directory: %s", ps.ProjectName), err)
+	}
+
+	return filepath.Walk(source.Dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source.Dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		slashRel := filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if info.Name() == ".git" || matchesAny(slashRel, skip) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if slashRel == StarterManifestFile || !info.Mode().IsRegular() || matchesAny(slashRel, skip) {
+			return nil
+		}
+
+		target := filepath.Join(ps.ProjectName, rel)
+		render := matchesAny(slashRel, source.Manifest.Render)
+		if strings.HasSuffix(rel, TemplateSuffix) {
+			target = strings.TrimSuffix(target, TemplateSuffix)
+			render = true
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return internal.NewFileSystemError(fmt.Sprintf("failed to create directory: %s", filepath.Dir(target)), err)
+		}
+
+		if !render {
+			in, err := os.Open(file)
+			if err != nil {
+				return internal.NewFileSystemError(fmt.Sprintf("failed to read starter file: %s", file), err)
+			}
+			defer in.Close()
+			if err := writeStarterFile(target, in, info.Mode().Perm()); err != nil {
+				return err
+			}
+		} else {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return internal.NewFileSystemError(fmt.Sprintf("failed to read starter file: %s", file), err)
+			}
+			text, err := renderTemplate(slashRel, string(raw), data)
+			if err != nil {
+				return err
+			}
+			if err := writeStarterFile(target, strings.NewReader(text), info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Created file: %s\n", target)
+		return nil
+	})
+}
+
+// writeStarterFile writes r to a new file with the given permissions
+func writeStarterFile(target string, r io.Reader, perm os.FileMode) error {
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm|0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return internal.NewValidationError(fmt.Sprintf("file already exists: %s", target))
+		}
+		return internal.NewFileSystemError(fmt.Sprintf("failed to create file: %s", target), err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("failed to write file: %s", target), err)
+	}
+	return nil
+}
+
+// matchesAny reports whether a slash-separated path matches one of the
+// patterns. Patterns without a slash also match the base name, and a
+// pattern naming a directory matches everything below it.
+func matchesAny(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+		}
+		if strings.HasPrefix(rel, pattern+"/") {
+			return true
+		}
+	}
+	return false
+}
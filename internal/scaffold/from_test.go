@@ -0,0 +1,264 @@
+package scaffold
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testManifest = `name = "house"
+render = ["public/*.html"]
+exclude = ["NOTES.md"]
+
+[features.forms]
+default = true
+files = ["public/contact.html"]
+
+[[variables]]
+name = "company"
+prompt = "Company"
+required = true
+
+[[variables]]
+name = "tagline"
+default = "We build things"
+`
+
+// testStarterFiles is a starter exercising rendering, exclusion and features
+var testStarterFiles = map[string]string{
+	StarterManifestFile:    testManifest,
+	"public/index.html":    "<h1>{{.Vars.company}}</h1><p>{{.Vars.tagline}}</p>[[.Meta.title]]",
+	"public/contact.html":  "<form></form>",
+	"public/js/app.js":     "const t = `{{x}}`",
+	"public/about.md.tmpl": "---\ntitle: {{.Vars.company | quote}}\n---\n",
+	"NOTES.md":             "internal notes",
+	".git/HEAD":            "ref: refs/heads/main",
+	"public/css/input.css": `@import "tailwindcss";`,
+}
+
+// writeStarter creates a starter directory from files
+func writeStarter(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// tarball returns a gzipped tar archive of files below prefix
+func tarball(t *testing.T, prefix string, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		header := &tar.Header{Name: prefix + name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestCreateFromStarter(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	writeStarter(t, "starter", testStarterFiles)
+
+	source, err := OpenStarter("starter")
+	if err != nil {
+		t.Fatalf("OpenStarter returned error: %v", err)
+	}
+	defer source.Close()
+
+	if source.Manifest.Name != "house" || !source.FeatureDefault("forms", false) || !source.FeatureDefault("search", true) {
+		t.Errorf("Unexpected manifest: %+v", source.Manifest)
+	}
+
+	vars, err := source.ResolveVariables(map[string]string{"company": `Acme "Inc"`}, nil, nil)
+	if err != nil {
+		t.Fatalf("ResolveVariables returned error: %v", err)
+	}
+
+	ps := NewProjectStructure("site")
+	ps.EnableForms = false
+	if err := ps.CreateFromStarter(source, vars); err != nil {
+		t.Fatalf("CreateFromStarter returned error: %v", err)
+	}
+
+	expected := map[string]string{
+		"public/index.html": `<h1>Acme "Inc"</h1><p>We build things</p>[[.Meta.title]]`,
+		"public/js/app.js":  "const t = `{{x}}`",
+		"public/about.md":   "---\ntitle: \"Acme \\\"Inc\\\"\"\n---\n",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join("site", filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+
+	for _, name := range []string{StarterManifestFile, "NOTES.md", ".git/HEAD", "public/contact.html", "public/about.md.tmpl"} {
+		if _, err := os.Stat(filepath.Join("site", filepath.FromSlash(name))); err == nil {
+			t.Errorf("Expected %s to be left out", name)
+		}
+	}
+
+	// Missing project files are generated without touching the starter's
+	ps.KeepExisting = true
+	if err := ps.CreateConfigurationFiles(); err != nil {
+		t.Fatalf("CreateConfigurationFiles returned error: %v", err)
+	}
+	if err := ps.CreateStylesheet(); err != nil {
+		t.Fatalf("CreateStylesheet returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("site", "bin", "build-css")); err != nil {
+		t.Errorf("Expected build script to be generated: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join("site", "public", "css", "input.css")); string(data) != `@import "tailwindcss";` {
+		t.Errorf("Expected starter stylesheet to be kept, got %q", data)
+	}
+}
+
+func TestOpenStarterArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := tarball(t, "house-main/", testStarterFiles)
+	path := filepath.Join(dir, "starter.tar.gz")
+	if err := os.WriteFile(path, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := OpenStarter(path)
+	if err != nil {
+		t.Fatalf("OpenStarter returned error: %v", err)
+	}
+	if source.Manifest.Name != "house" {
+		t.Errorf("Expected the top-level directory to be unwrapped, manifest: %+v", source.Manifest)
+	}
+	tempDir := source.tempDir
+	source.Close()
+	if _, err := os.Stat(tempDir); !os.IsNotExist(err) {
+		t.Error("Expected Close to remove the extracted files")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/starter.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	remote, err := OpenStarter(server.URL + "/starter.tar.gz")
+	if err != nil {
+		t.Fatalf("OpenStarter(url) returned error: %v", err)
+	}
+	remote.Close()
+	if _, err := OpenStarter(server.URL + "/missing.tar.gz"); err == nil {
+		t.Error("Expected an error for a missing archive")
+	}
+}
+
+func TestOpenStarterDownloadTimeout(t *testing.T) {
+	client := starterClient
+	starterClient = &http.Client{Timeout: 100 * time.Millisecond}
+	t.Cleanup(func() { starterClient = client })
+
+	// The server starts the response and then stalls
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0x1f, 0x8b})
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		source, err := OpenStarter(server.URL + "/starter.tar.gz")
+		if err == nil {
+			source.Close()
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected an error for a stalled download")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OpenStarter hung on a stalled download")
+	}
+}
+
+func TestOpenStarterErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	unsafe := filepath.Join(dir, "unsafe.tar.gz")
+	if err := os.WriteFile(unsafe, tarball(t, "../", map[string]string{"evil": "x"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notArchive := filepath.Join(dir, "starter.txt")
+	if err := os.WriteFile(notArchive, []byte("plain"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	badManifest := filepath.Join(dir, "bad")
+	writeStarter(t, badManifest, map[string]string{StarterManifestFile: "[features.blog]\ndefault = true\n"})
+	unknownKey := filepath.Join(dir, "unknown")
+	writeStarter(t, unknownKey, map[string]string{StarterManifestFile: "colour = \"red\"\n"})
+
+	for _, from := range []string{filepath.Join(dir, "missing"), unsafe, notArchive, badManifest, unknownKey} {
+		if source, err := OpenStarter(from); err == nil {
+			source.Close()
+			t.Errorf("OpenStarter(%s): expected an error", from)
+		}
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	source := &StarterSource{Manifest: StarterManifest{Variables: []StarterVariable{
+		{Name: "company", Prompt: "Company", Required: true},
+		{Name: "tagline", Default: "We build things"},
+	}}}
+
+	var out bytes.Buffer
+	vars, err := source.ResolveVariables(nil, strings.NewReader("Acme\n\n"), &out)
+	if err != nil {
+		t.Fatalf("ResolveVariables returned error: %v", err)
+	}
+	if vars["company"] != "Acme" || vars["tagline"] != "We build things" {
+		t.Errorf("Unexpected variables: %v", vars)
+	}
+	if !strings.Contains(out.String(), "Company: ") || !strings.Contains(out.String(), "tagline [We build things]: ") {
+		t.Errorf("Unexpected prompts: %q", out.String())
+	}
+
+	if _, err := source.ResolveVariables(nil, nil, nil); err == nil {
+		t.Error("Expected an error for a missing required variable")
+	}
+	if _, err := source.ResolveVariables(map[string]string{"company": "x", "colour": "red"}, nil, nil); err == nil {
+		t.Error("Expected an error for an undeclared variable")
+	}
+}
@@ -0,0 +1,104 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// DefaultStarter is used when no starter is selected
+const DefaultStarter = "default"
+
+// NavItem is a link in a generated navigation menu
+type NavItem struct {
+	Title string
+	URL   string
+}
+
+// Starter describes a built-in project template
+type Starter struct {
+	Name        string
+	Description string
+	Nav         []NavItem // links in the site header
+	Sidebar     []NavItem // links in the sidebar, if the starter has one
+}
+
+// Starters lists the built-in starters available to 'garp init --template'
+var Starters = []Starter{
+	{
+		Name:        DefaultStarter,
+		Description: "A single welcome page that explains how Garp works",
+		Nav: []NavItem{
+			{Title: "Home", URL: "/"},
+			{Title: "About", URL: "/about/"},
+			{Title: "Contact", URL: "/contact/"},
+		},
+	},
+	{
+		Name:        "blog",
+		Description: "A blog with a post listing, a first post and an about page",
+		Nav: []NavItem{
+			{Title: "Home", URL: "/"},
+			{Title: "Blog", URL: "/blog/"},
+			{Title: "About", URL: "/about/"},
+		},
+	},
+	{
+		Name:        "docs",
+		Description: "Documentation with a sidebar",
+		Nav: []NavItem{
+			{Title: "Home", URL: "/"},
+			{Title: "Docs", URL: "/docs/"},
+		},
+		Sidebar: []NavItem{
+			{Title: "Overview", URL: "/docs/"},
+			{Title: "Getting Started", URL: "/docs/getting-started/"},
+			{Title: "Configuration", URL: "/docs/configuration/"},
+		},
+	},
+	{
+		Name:        "portfolio",
+		Description: "A personal portfolio with project pages",
+		Nav: []NavItem{
+			{Title: "Work", URL: "/"},
+			{Title: "About", URL: "/about/"},
+			{Title: "Contact", URL: "/contact/"},
+		},
+	},
+	{
+		Name:        "business",
+		Description: "A business site with services, about and contact pages",
+		Nav: []NavItem{
+			{Title: "Home", URL: "/"},
+			{Title: "Services", URL: "/services/"},
+			{Title: "About", URL: "/about/"},
+			{Title: "Contact", URL: "/contact/"},
+		},
+	},
+}
+
+// StarterNames returns the names of the built-in starters
+func StarterNames() []string {
+	names := make([]string, len(Starters))
+	for i, starter := range Starters {
+		names[i] = starter.Name
+	}
+	return names
+}
+
+// FindStarter returns the built-in starter with the given name
+func FindStarter(name string) (Starter, error) {
+	if name == "" {
+		name = DefaultStarter
+	}
+	for _, starter := range Starters {
+		if starter.Name == name {
+			return starter, nil
+		}
+	}
+	return Starter{}, internal.NewValidationErrorWithSuggestions(
+		fmt.Sprintf("unknown starter template: %s", name),
+		[]string{fmt.Sprintf("Use one of: %s", strings.Join(StarterNames(), ", "))},
+	)
+}
@@ -0,0 +1,104 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattsafaii/garp/internal/content"
+)
+
+func TestCreateProjectFromStarters(t *testing.T) {
+	chdir(t, t.TempDir())
+	year := fmt.Sprint(time.Now().Year())
+
+	for _, starter := range StarterNames() {
+		t.Run(starter, func(t *testing.T) {
+			ps := NewProjectStructure("site-" + starter)
+			ps.Starter = starter
+			ps.Author = `Jane "JD" Doe`
+			ps.EnableForms = true
+
+			steps := []func() error{ps.CreateDirectories, ps.CreateTemplateFiles, ps.CreateConfigurationFiles, ps.CreateArchetypeFiles, ps.CreateFormServerFiles}
+			for _, step := range steps {
+				if err := step(); err != nil {
+					t.Fatalf("Failed to create project: %v", err)
+				}
+			}
+
+			layout := ps.projectLayout()
+			err := filepath.Walk(ps.ProjectName, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				// Archetypes are copied as templates; everything else is rendered
+				if filepath.Base(filepath.Dir(path)) != ArchetypeDir && strings.Contains(string(data), "{{") {
+					t.Errorf("%s contains unrendered template actions", path)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := content.LoadPages(layout.SourceDir); err != nil {
+				t.Errorf("Starter pages have invalid front matter: %v", err)
+			}
+
+			template, err := os.ReadFile(filepath.Join(layout.SourceDir, "_template.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(template), "&copy; "+year+" Jane &#34;JD&#34; Doe") {
+				t.Errorf("Expected footer with the current year and author")
+			}
+			if !strings.Contains(string(template), "[[.Body | markdown]]") {
+				t.Errorf("Expected Caddy template actions to be left alone")
+			}
+
+			config, err := os.ReadFile(filepath.Join(ps.ProjectName, "garp.toml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(config), `author = "Jane \"JD\" Doe"`) {
+				t.Errorf("Expected quoted author in garp.toml")
+			}
+		})
+	}
+}
+
+func TestCreateProjectSearchDisabled(t *testing.T) {
+	chdir(t, t.TempDir())
+	ps := NewProjectStructure("site")
+	ps.EnableSearch = false
+	if err := ps.CreateDirectories(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.CreateTemplateFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	template, err := os.ReadFile(filepath.Join("site", "public", "_template.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(template), "pagefind") {
+		t.Error("Expected search script to be left out when search is disabled")
+	}
+}
+
+func TestFindStarter(t *testing.T) {
+	if starter, err := FindStarter(""); err != nil || starter.Name != DefaultStarter {
+		t.Errorf("Expected the default starter, got %+v (%v)", starter, err)
+	}
+	if _, err := FindStarter("unknown"); err == nil {
+		t.Error("Expected an error for an unknown starter")
+	}
+}
@@ -2,9 +2,14 @@ package server
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -13,18 +18,31 @@ import (
 
 // CaddyServer manages the Caddy server instance
 type CaddyServer struct {
-	Host       string
-	Port       int
-	ConfigFile string
-	Process    *exec.Cmd
+	Host        string
+	Port        int
+	ProjectFile string
+	ConfigFile  string
+	Process     *exec.Cmd
+
+	// LiveReload runs a development proxy in front of Caddy that injects a
+	// reload script into HTML pages and notifies browsers of file changes
+	LiveReload bool
+	WatchDir   string
+
+	upstreamPort int
+	devServer    *http.Server
+	stopWatcher  chan struct{}
 }
 
 // NewCaddyServer creates a new CaddyServer instance
 func NewCaddyServer(host string, port int) *CaddyServer {
 	return &CaddyServer{
-		Host:       host,
-		Port:       port,
-		ConfigFile: ".garp-caddyfile-temp",
+		Host:        host,
+		Port:        port,
+		ProjectFile: "Caddyfile",
+		ConfigFile:  ".garp-caddyfile-temp",
+		LiveReload:  true,
+		WatchDir:    internal.GetProjectLayout().SourceDir,
 	}
 }
 
@@ -35,7 +53,16 @@ func (cs *CaddyServer) Start() error {
 		return err
 	}
 
-	// Generate dynamic Caddyfile
+	// With live reload enabled Caddy listens on a private port behind the proxy
+	if cs.LiveReload {
+		upstreamPort, err := freePort()
+		if err != nil {
+			return internal.NewExternalError("failed to reserve a port for Caddy", err)
+		}
+		cs.upstreamPort = upstreamPort
+	}
+
+	// Generate Caddyfile from the project configuration
 	if err := cs.generateCaddyfile(); err != nil {
 		return err
 	}
@@ -49,7 +76,7 @@ func (cs *CaddyServer) Start() error {
 	defer cs.cleanup()
 
 	// Start Caddy server
-	fmt.Printf("Starting Caddy server on %s:%d\n", cs.Host, cs.Port)
+	fmt.Printf("Starting Caddy server on %s\n", cs.Address())
 	fmt.Printf("Using configuration: %s\n", cs.ConfigFile)
 
 	cs.Process = exec.Command("caddy", "run", "--adapter", "caddyfile", "--config", cs.ConfigFile)
@@ -60,9 +87,18 @@ func (cs *CaddyServer) Start() error {
 		return internal.NewExternalError("failed to start Caddy server", err)
 	}
 
+	if cs.LiveReload {
+		if err := cs.startDevServer(); err != nil {
+			cs.Process.Process.Kill()
+			return err
+		}
+	}
+
 	fmt.Printf("✓ Server started successfully!\n")
 	fmt.Printf("📖 Visit: http://%s:%d\n", cs.Host, cs.Port)
-	fmt.Printf("📁 Documentation: http://%s:%d/docs/\n", cs.Host, cs.Port)
+	if cs.LiveReload {
+		fmt.Printf("🔄 Live reload: watching %s/\n", cs.WatchDir)
+	}
 	fmt.Printf("\nPress Ctrl+C to stop the server...\n\n")
 
 	// Set up signal handling for graceful shutdown
@@ -72,6 +108,38 @@ func (cs *CaddyServer) Start() error {
 	return cs.Process.Wait()
 }
 
+// startDevServer starts the live reload proxy and the file watcher
+func (cs *CaddyServer) startDevServer() error {
+	upstream, err := url.Parse(cs.Address())
+	if err != nil {
+		return internal.NewConfigurationError(fmt.Sprintf("invalid upstream address: %s", cs.Address()))
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(cs.Host, strconv.Itoa(cs.Port)))
+	if err != nil {
+		return internal.NewExternalError(fmt.Sprintf("failed to listen on %s:%d", cs.Host, cs.Port), err)
+	}
+
+	reload := NewLiveReload()
+	cs.devServer = &http.Server{Handler: NewDevProxy(upstream, reload, cs.WatchDir)}
+	go cs.devServer.Serve(listener)
+
+	cs.stopWatcher = make(chan struct{})
+	watcher := NewWatcher(cs.WatchDir)
+	watcher.Ignore = append(watcher.Ignore, filepath.Base(internal.GetProjectLayout().SearchOutput))
+	go func() {
+		err := watcher.Run(cs.stopWatcher, func(changed []string) {
+			internal.LogDebug("Files changed", "count", strconv.Itoa(len(changed)))
+			reload.Notify(changed)
+		})
+		if err != nil {
+			internal.LogErrorWithError("File watcher stopped", err)
+		}
+	}()
+
+	return nil
+}
+
 // Stop stops the Caddy server gracefully
 func (cs *CaddyServer) Stop() error {
 	if cs.Process == nil {
@@ -80,6 +148,14 @@ func (cs *CaddyServer) Stop() error {
 
 	fmt.Println("\n🛑 Stopping server...")
 
+	if cs.stopWatcher != nil {
+		close(cs.stopWatcher)
+		cs.stopWatcher = nil
+	}
+	if cs.devServer != nil {
+		cs.devServer.Close()
+	}
+
 	// Try graceful shutdown first
 	if err := cs.Process.Process.Signal(syscall.SIGTERM); err != nil {
 		// If graceful shutdown fails, force kill
@@ -136,6 +212,7 @@ func (cs *CaddyServer) setupSignalHandling() {
 	go func() {
 		<-sigChan
 		cs.Stop()
+		cs.cleanup()
 		os.Exit(0)
 	}()
 }
@@ -156,76 +233,18 @@ func (cs *CaddyServer) ValidateConfiguration() error {
 	return nil
 }
 
-// generateCaddyfile creates a dynamic Caddyfile for the current host/port
+// generateCaddyfile writes a copy of the project Caddyfile with the site
+// address overridden by the requested host and port
 func (cs *CaddyServer) generateCaddyfile() error {
-	caddyfileContent := fmt.Sprintf(`{
-	auto_https off
-	admin off
-}
-
-http://%s:%d {
-	root * .
-	
-	redir / /docs/
-	
-	handle /docs/* {
-		uri strip_prefix /docs
-		rewrite * /site/docs{path}.html
-		try_files {path} /site/docs/index.html
-		
-		# Apply templates for HTML files
-		templates {
-			mime text/html
-		}
-		file_server
+	projectCaddyfile, err := LoadProjectCaddyfile(cs.ProjectFile)
+	if err != nil {
+		return err
 	}
-	
-	handle /docs {
-		rewrite * /site/docs/index.html
-		templates {
-			mime text/html
-		}
-		file_server
-	}
-	
-	# Serve raw markdown files for now (temporary)
-	handle /docs/markdown/* {
-		file_server
-	}
-	
-	handle /style.css {
-		rewrite * /site/style.css
-		file_server
-	}
-	
-	handle /_pagefind/* {
-		rewrite * /site{path}
-		file_server
-	}
-	
-	file_server
-	
-	encode gzip
-	
-	log {
-		output stdout
-		format console
-		level INFO
-	}
-	
-	header {
-		Access-Control-Allow-Origin "*"
-		Access-Control-Allow-Methods "GET, POST, OPTIONS"
-		Access-Control-Allow-Headers "*"
-	}
-	
-	handle_errors {
-		@404 expression {http.error.status_code} == 404
-		handle @404 {
-			respond "Page not found. Try visiting /docs/ for documentation." 404
-		}
+
+	caddyfileContent, err := RewriteSiteAddress(projectCaddyfile, cs.Address())
+	if err != nil {
+		return err
 	}
-}`, cs.Host, cs.Port)
 
 	// Write the dynamic Caddyfile
 	file, err := os.Create(cs.ConfigFile)
@@ -238,13 +257,31 @@ http://%s:%d {
 		return internal.NewFileSystemError("failed to write dynamic Caddyfile", err)
 	}
 
-	fmt.Printf("✓ Generated dynamic Caddyfile for %s:%d\n", cs.Host, cs.Port)
+	fmt.Printf("✓ Generated Caddyfile from %s for %s\n", cs.ProjectFile, cs.Address())
 	return nil
 }
 
+// Address returns the site address Caddy should listen on
+func (cs *CaddyServer) Address() string {
+	if cs.upstreamPort != 0 {
+		return fmt.Sprintf("http://127.0.0.1:%d", cs.upstreamPort)
+	}
+	return fmt.Sprintf("http://%s:%d", cs.Host, cs.Port)
+}
+
+// freePort asks the kernel for an unused local TCP port
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
 // cleanup removes temporary files
 func (cs *CaddyServer) cleanup() {
-	if cs.ConfigFile != "" && cs.ConfigFile != "site/Caddyfile" {
+	if cs.ConfigFile != "" && cs.ConfigFile != cs.ProjectFile {
 		os.Remove(cs.ConfigFile)
 	}
 }
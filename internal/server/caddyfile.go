@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattsafaii/garp/internal"
+)
+
+// LoadProjectCaddyfile reads the project's Caddyfile from disk
+func LoadProjectCaddyfile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", internal.NewConfigurationErrorWithSuggestions(
+				fmt.Sprintf("%s not found", path),
+				[]string{
+					"Run 'garp init' to create a new project with a Caddyfile",
+					"Make sure you're in the project root directory",
+				},
+			)
+		}
+		return "", internal.NewFileSystemError(fmt.Sprintf("cannot read %s", path), err)
+	}
+	return string(content), nil
+}
+
+// RewriteSiteAddress replaces the address of the first site block in a
+// Caddyfile with the given address, leaving every directive untouched.
+// Global options blocks, snippets and named routes are skipped.
+func RewriteSiteAddress(caddyfile, address string) (string, error) {
+	lines := strings.Split(caddyfile, "\n")
+	depth := 0
+
+	for i, line := range lines {
+		code := stripComment(line)
+		trimmed := strings.TrimSpace(code)
+
+		if depth == 0 && trimmed != "" && isSiteAddressLine(trimmed) {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			if strings.HasSuffix(trimmed, "{") {
+				lines[i] = indent + address + " {"
+			} else {
+				lines[i] = indent + address
+			}
+			return strings.Join(lines, "\n"), nil
+		}
+
+		depth += braceDelta(code)
+		if depth < 0 {
+			return "", internal.NewConfigurationError("Caddyfile has unbalanced braces")
+		}
+	}
+
+	return "", internal.NewConfigurationErrorWithSuggestions(
+		"no site block found in Caddyfile",
+		[]string{
+			"Add a site block such as 'localhost:8080 { ... }' to your Caddyfile",
+			"Run 'garp init' to see the default project Caddyfile",
+		},
+	)
+}
+
+// isSiteAddressLine reports whether a top-level line opens a site block
+func isSiteAddressLine(trimmed string) bool {
+	// A bare "{" at the top level opens the global options block
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "}") {
+		return false
+	}
+	// Snippets "(name)" and named routes "&(name)" are not sites
+	if strings.HasPrefix(trimmed, "(") || strings.HasPrefix(trimmed, "&(") {
+		return false
+	}
+	return true
+}
+
+// stripComment removes a trailing '#' comment that is not inside quotes
+func stripComment(line string) string {
+	inQuote := false
+	for i, r := range line {
+		switch r {
+		case '"':
+			inQuote = !inQuote
+		case '#':
+			if !inQuote && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// braceDelta returns the change in block depth for a line of Caddyfile code
+func braceDelta(code string) int {
+	delta := 0
+	inQuote := false
+	for _, r := range code {
+		switch r {
+		case '"':
+			inQuote = !inQuote
+		case '{':
+			if !inQuote {
+				delta++
+			}
+		case '}':
+			if !inQuote {
+				delta--
+			}
+		}
+	}
+	return delta
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteSiteAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "global options and site block",
+			input: `{
+	auto_https off
+}
+
+localhost:8080 {
+	root * public
+	try_files {path} {path}.md
+}`,
+			expected: `{
+	auto_https off
+}
+
+http://0.0.0.0:3000 {
+	root * public
+	try_files {path} {path}.md
+}`,
+		},
+		{
+			name: "brace on its own line",
+			input: `# comment with { brace
+example.com
+{
+	root * public
+}`,
+			expected: `# comment with { brace
+http://0.0.0.0:3000
+{
+	root * public
+}`,
+		},
+		{
+			name: "snippets are skipped",
+			input: `(common) {
+	encode gzip
+}
+
+:8080 {
+	import common
+}`,
+			expected: `(common) {
+	encode gzip
+}
+
+http://0.0.0.0:3000 {
+	import common
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RewriteSiteAddress(tt.input, "http://0.0.0.0:3000")
+			if err != nil {
+				t.Fatalf("RewriteSiteAddress() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("RewriteSiteAddress() =\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRewriteSiteAddressOnlyFirstSite(t *testing.T) {
+	input := "localhost:8080 {\n\tfile_server\n}\n\nwww.example.com {\n\tredir https://example.com\n}"
+
+	got, err := RewriteSiteAddress(input, "http://localhost:9000")
+	if err != nil {
+		t.Fatalf("RewriteSiteAddress() error = %v", err)
+	}
+
+	if !strings.HasPrefix(got, "http://localhost:9000 {") {
+		t.Errorf("first site block was not rewritten: %s", got)
+	}
+	if !strings.Contains(got, "www.example.com {") {
+		t.Errorf("second site block should be left untouched: %s", got)
+	}
+}
+
+func TestRewriteSiteAddressErrors(t *testing.T) {
+	if _, err := RewriteSiteAddress("{\n\tadmin off\n}", "http://localhost:8080"); err == nil {
+		t.Error("expected error for Caddyfile without a site block")
+	}
+
+	if _, err := RewriteSiteAddress("}\nlocalhost {\n}", "http://localhost:8080"); err == nil {
+		t.Error("expected error for unbalanced braces")
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/content"
+)
+
+// draftBannerStyle keeps the banner visible above any page layout
+const draftBannerStyle = "position:fixed;top:0;left:0;right:0;z-index:2147483647;" +
+	"background:#b91c1c;color:#fff;font:600 13px/1.8 system-ui,sans-serif;" +
+	"text-align:center;letter-spacing:.05em;pointer-events:none"
+
+// DraftBanner returns the banner shown on unpublished pages, or nil when
+// the page is published
+func DraftBanner(page *content.Page, now time.Time) []byte {
+	var label string
+	switch {
+	case page.Draft:
+		label = "DRAFT — this page is not published"
+	case page.IsFuture(now):
+		label = fmt.Sprintf("SCHEDULED — this page is published on %s", page.Date.Format("January 2, 2006"))
+	default:
+		return nil
+	}
+	return []byte(`<div id="garp-draft-banner" style="` + draftBannerStyle + `">` + html.EscapeString(label) + `</div>`)
+}
+
+// injectDraftBanner marks the page served at urlPath when it is a draft or
+// scheduled for later
+func injectDraftBanner(body []byte, sourceDir, urlPath string) []byte {
+	page, err := content.FindPage(sourceDir, urlPath)
+	if err != nil {
+		internal.LogDebug("Could not load page for draft check", "path", urlPath, "error", err.Error())
+		return body
+	}
+	if page == nil {
+		return body
+	}
+
+	banner := DraftBanner(page, time.Now())
+	if banner == nil {
+		return body
+	}
+	return injectBeforeBodyEnd(body, banner)
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// LiveReloadPath is the Server-Sent Events endpoint browsers subscribe to
+	LiveReloadPath = "/_garp/livereload"
+
+	// LiveReloadScriptPath serves the client script injected into HTML pages
+	LiveReloadScriptPath = "/_garp/livereload.js"
+)
+
+// Reload event names sent to browsers
+const (
+	EventReload = "reload"
+	EventCSS    = "css"
+)
+
+// liveReloadScript reloads the page on "reload" events and swaps same-origin
+// stylesheets in place on "css" events
+const liveReloadScript = `(function () {
+  if (!window.EventSource) { return; }
+  var source = new EventSource("` + LiveReloadPath + `");
+  source.addEventListener("reload", function () { window.location.reload(); });
+  source.addEventListener("css", function () {
+    var links = document.querySelectorAll('link[rel="stylesheet"]');
+    Array.prototype.forEach.call(links, function (link) {
+      var href = new URL(link.href, window.location.href);
+      if (href.origin !== window.location.origin) { return; }
+      href.searchParams.set("garp-reload", Date.now());
+      var next = link.cloneNode();
+      next.href = href.toString();
+      next.onload = function () { link.remove(); };
+      link.after(next);
+    });
+  });
+})();
+`
+
+// LiveReload broadcasts change events to connected browsers over SSE
+type LiveReload struct {
+	mu      sync.Mutex
+	clients map[chan string]struct{}
+}
+
+// NewLiveReload creates a new live reload hub
+func NewLiveReload() *LiveReload {
+	return &LiveReload{
+		clients: make(map[chan string]struct{}),
+	}
+}
+
+// Broadcast sends an event to every connected browser
+func (lr *LiveReload) Broadcast(event string) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	for client := range lr.clients {
+		select {
+		case client <- event:
+		default:
+			// Drop the event for slow clients rather than blocking the watcher
+		}
+	}
+}
+
+// Notify picks the right event for a batch of changed files
+func (lr *LiveReload) Notify(changed []string) {
+	if onlyStylesheets(changed) {
+		lr.Broadcast(EventCSS)
+		return
+	}
+	lr.Broadcast(EventReload)
+}
+
+// ClientCount returns the number of connected browsers
+func (lr *LiveReload) ClientCount() int {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return len(lr.clients)
+}
+
+// ServeHTTP implements the SSE endpoint
+func (lr *LiveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client := make(chan string, 4)
+	lr.mu.Lock()
+	lr.clients[client] = struct{}{}
+	lr.mu.Unlock()
+
+	defer func() {
+		lr.mu.Lock()
+		delete(lr.clients, client)
+		lr.mu.Unlock()
+	}()
+
+	fmt.Fprint(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-client:
+			fmt.Fprintf(w, "event: %s\ndata: {}\n\n", event)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// serveScript serves the live reload client script
+func serveScript(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	io.WriteString(w, liveReloadScript)
+}
+
+// InjectScript inserts the live reload script tag before </body>, or at the
+// end of the document when no closing body tag exists
+func InjectScript(html []byte) []byte {
+	return injectBeforeBodyEnd(html, []byte(`<script src="`+LiveReloadScriptPath+`"></script>`))
+}
+
+// injectBeforeBodyEnd inserts tag before the last </body>
+func injectBeforeBodyEnd(html, tag []byte) []byte {
+	index := bytes.LastIndex(bytes.ToLower(html), []byte("</body>"))
+	if index == -1 {
+		return append(html, tag...)
+	}
+
+	result := make([]byte, 0, len(html)+len(tag))
+	result = append(result, html[:index]...)
+	result = append(result, tag...)
+	result = append(result, html[index:]...)
+	return result
+}
+
+// NewDevProxy returns a handler that proxies requests to the upstream Caddy
+// server, serves the live reload endpoints and injects the client script
+// into HTML responses. Pages in sourceDir that are drafts or scheduled for
+// later also get a visible banner.
+func NewDevProxy(upstream *url.URL, reload *LiveReload, sourceDir string) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+
+	// Send the upstream's own host so Caddy's site matcher accepts the
+	// request, keeping the browser's in X-Forwarded-Host. Drop the browser's
+	// Accept-Encoding so the transport negotiates gzip itself and hands back
+	// decoded bodies that can be rewritten.
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		if r.Header.Get("X-Forwarded-Host") == "" {
+			r.Header.Set("X-Forwarded-Host", r.Host)
+		}
+		r.Host = upstream.Host
+		r.Header.Del("Accept-Encoding")
+	}
+
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		contentType := resp.Header.Get("Content-Type")
+		if !strings.HasPrefix(contentType, "text/html") || resp.Header.Get("Content-Encoding") != "" {
+			return nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		body = InjectScript(body)
+		if sourceDir != "" {
+			body = injectDraftBanner(body, sourceDir, resp.Request.URL.Path)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(LiveReloadPath, reload)
+	mux.HandleFunc(LiveReloadScriptPath, serveScript)
+	mux.Handle("/", proxy)
+	return mux
+}
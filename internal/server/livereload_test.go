@@ -0,0 +1,221 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInjectScript(t *testing.T) {
+	html := []byte("<html><body><p>Hello</p></BODY></html>")
+	got := string(InjectScript(html))
+
+	expected := `<p>Hello</p><script src="/_garp/livereload.js"></script></BODY>`
+	if !strings.Contains(got, expected) {
+		t.Errorf("InjectScript() = %s, want script before closing body tag", got)
+	}
+
+	fragment := string(InjectScript([]byte("<p>fragment</p>")))
+	if !strings.HasSuffix(fragment, `<script src="/_garp/livereload.js"></script>`) {
+		t.Errorf("InjectScript() should append script when no body tag: %s", fragment)
+	}
+}
+
+func TestDevProxyInjectsHTMLOnly(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "br") {
+			t.Errorf("browser Accept-Encoding should not reach upstream, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		if r.URL.Path == "/css/style.css" {
+			w.Header().Set("Content-Type", "text/css")
+			io.WriteString(w, "body{}")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><body>page</body></html>")
+	}))
+	defer upstream.Close()
+
+	upstreamURL, _ := url.Parse(upstream.URL)
+	proxy := httptest.NewServer(NewDevProxy(upstreamURL, NewLiveReload(), ""))
+	defer proxy.Close()
+
+	req, _ := http.NewRequest("GET", proxy.URL+"/", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	body := string(raw)
+	if !strings.Contains(body, LiveReloadScriptPath) {
+		t.Errorf("HTML response missing live reload script: %s", body)
+	}
+
+	css := get(t, proxy.URL+"/css/style.css")
+	if css != "body{}" {
+		t.Errorf("CSS response should be untouched, got %q", css)
+	}
+
+	script := get(t, proxy.URL+LiveReloadScriptPath)
+	if !strings.Contains(script, "EventSource") {
+		t.Errorf("live reload script not served: %s", script)
+	}
+}
+
+func TestDevProxySendsUpstreamHost(t *testing.T) {
+	var upstreamURL *url.URL
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Caddy only serves the rewritten site address for its own host
+		if r.Host != upstreamURL.Host {
+			t.Errorf("upstream got Host %q, want %q", r.Host, upstreamURL.Host)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "localhost:8080" {
+			t.Errorf("X-Forwarded-Host = %q, want the browser's host", forwarded)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><body>page</body></html>")
+	}))
+	defer upstream.Close()
+	upstreamURL, _ = url.Parse(upstream.URL)
+
+	proxy := httptest.NewServer(NewDevProxy(upstreamURL, NewLiveReload(), ""))
+	defer proxy.Close()
+
+	req, _ := http.NewRequest("GET", proxy.URL+"/", nil)
+	req.Host = "localhost:8080"
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "page") {
+		t.Errorf("GET / = %d %q, want the upstream page", resp.StatusCode, body)
+	}
+}
+
+func TestLiveReloadBroadcast(t *testing.T) {
+	reload := NewLiveReload()
+	server := httptest.NewServer(reload)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to connect to SSE endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for reload.ClientCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reload.Notify([]string{"public/css/style.css"})
+
+	buf := make([]byte, 256)
+	var received strings.Builder
+	for !strings.Contains(received.String(), "event:") && time.Now().Before(deadline) {
+		n, err := resp.Body.Read(buf)
+		received.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+
+	if !strings.Contains(received.String(), "event: css") {
+		t.Errorf("expected css event for stylesheet-only change, got %q", received.String())
+	}
+}
+
+func TestWatcherDetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "index.md")
+	if err := os.WriteFile(page, []byte("# Hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "_pagefind"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := NewWatcher(dir)
+	if changed, err := watcher.Scan(); err != nil || len(changed) != 0 {
+		t.Fatalf("initial scan should report nothing, got %v (err %v)", changed, err)
+	}
+
+	os.WriteFile(page, []byte("# Hello, world"), 0644)
+	os.WriteFile(filepath.Join(dir, "_pagefind", "index.js"), []byte("x"), 0644)
+	style := filepath.Join(dir, "style.css")
+	os.WriteFile(style, []byte("body{}"), 0644)
+
+	changed, err := watcher.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(changed) != 2 || changed[0] != page || changed[1] != style {
+		t.Errorf("Scan() = %v, want [%s %s]", changed, page, style)
+	}
+
+	os.Remove(page)
+	changed, _ = watcher.Scan()
+	if len(changed) != 1 || changed[0] != page {
+		t.Errorf("Scan() after removal = %v, want [%s]", changed, page)
+	}
+	if onlyStylesheets(changed) {
+		t.Error("markdown change should not be treated as stylesheet-only")
+	}
+}
+
+func get(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestDevProxyMarksDrafts(t *testing.T) {
+	source := t.TempDir()
+	pages := map[string]string{
+		"index.md": "---\ntitle: Home\n---\n",
+		"draft.md": "---\ntitle: Draft\ndraft: true\n---\n",
+		"later.md": "---\ntitle: Later\ndate: 2999-01-01\n---\n",
+	}
+	for name, data := range pages {
+		if err := os.WriteFile(filepath.Join(source, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html><body>page</body></html>")
+	}))
+	defer upstream.Close()
+
+	upstreamURL, _ := url.Parse(upstream.URL)
+	proxy := httptest.NewServer(NewDevProxy(upstreamURL, NewLiveReload(), source))
+	defer proxy.Close()
+
+	if body := get(t, proxy.URL+"/"); strings.Contains(body, "garp-draft-banner") {
+		t.Errorf("Published page should not have a banner: %s", body)
+	}
+	if body := get(t, proxy.URL+"/draft/"); !strings.Contains(body, "DRAFT") {
+		t.Errorf("Draft page missing banner: %s", body)
+	}
+	if body := get(t, proxy.URL+"/later"); !strings.Contains(body, "SCHEDULED") || !strings.Contains(body, "January 1, 2999") {
+		t.Errorf("Scheduled page missing banner: %s", body)
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileState captures the attributes used to detect file changes
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher polls a directory tree and reports files that were added,
+// modified or removed between scans
+type Watcher struct {
+	Root     string
+	Interval time.Duration
+	Ignore   []string // directory or file names to skip (e.g. "_pagefind")
+
+	snapshot map[string]fileState
+}
+
+// NewWatcher creates a new polling watcher for the given directory
+func NewWatcher(root string) *Watcher {
+	return &Watcher{
+		Root:     root,
+		Interval: 500 * time.Millisecond,
+		Ignore:   []string{"_pagefind", ".DS_Store"},
+	}
+}
+
+// Scan walks the watched tree and returns the paths that changed since the
+// previous scan. The first scan only records the initial state.
+func (w *Watcher) Scan() ([]string, error) {
+	current := make(map[string]fileState)
+
+	err := filepath.Walk(w.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Files can disappear between listing and stat; skip them
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if w.ignored(info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !info.IsDir() {
+			current[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if w.snapshot == nil {
+		w.snapshot = current
+		return nil, nil
+	}
+
+	var changed []string
+	for path, state := range current {
+		previous, exists := w.snapshot[path]
+		if !exists || !previous.modTime.Equal(state.modTime) || previous.size != state.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.snapshot {
+		if _, exists := current[path]; !exists {
+			changed = append(changed, path)
+		}
+	}
+
+	w.snapshot = current
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// Run polls until stop is closed, calling onChange for every batch of changes
+func (w *Watcher) Run(stop <-chan struct{}, onChange func([]string)) error {
+	if _, err := w.Scan(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			changed, err := w.Scan()
+			if err != nil {
+				continue
+			}
+			if len(changed) > 0 {
+				onChange(changed)
+			}
+		}
+	}
+}
+
+// ignored reports whether a file or directory name should be skipped
+func (w *Watcher) ignored(name string) bool {
+	for _, ignore := range w.Ignore {
+		if name == ignore {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyStylesheets reports whether every changed path is a CSS file
+func onlyStylesheets(paths []string) bool {
+	if len(paths) == 0 {
+		return false
+	}
+	for _, path := range paths {
+		if !strings.EqualFold(filepath.Ext(path), ".css") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package static
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/content"
+)
+
+// Build compiles CSS into the source tree, renders the site into the static
+// output directory and builds the search index over the rendered HTML
+func Build(options internal.BuildOptions) (*internal.BuildResult, error) {
+	result := &internal.BuildResult{}
+	start := time.Now()
+
+	fail := func(err error) (*internal.BuildResult, error) {
+		result.Errors = append(result.Errors, err.Error())
+		result.Success = false
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	if options.Watch {
+		return fail(internal.NewValidationError("watch mode is not supported for static builds; use 'garp serve' while editing"))
+	}
+
+	layout := internal.GetProjectLayout()
+	if !layout.IsStatic() {
+		return fail(internal.NewConfigurationError("static build requires a static output directory (build.static_dir)"))
+	}
+
+	if options.Verbose {
+		fmt.Printf("🚀 Starting static build into %s...\n", layout.OutputDir)
+	}
+
+	if err := internal.ValidateGarpProject(); err != nil {
+		return fail(err)
+	}
+
+	// CSS is compiled into the source tree and copied with the other assets
+	if !options.SearchOnly {
+		cssResult, err := internal.BuildCSS(options)
+		result.CSSBuilt = cssResult.CSSBuilt
+		if err != nil {
+			result.Errors = append(result.Errors, cssResult.Errors...)
+			result.Success = false
+			result.Duration = time.Since(start)
+			return result, err
+		}
+	}
+
+	if !options.CSSOnly {
+		if options.Verbose {
+			fmt.Println("📝 Rendering pages...")
+		}
+
+		renderer := NewRenderer(layout)
+		renderer.Publish = content.PublishOptions{Drafts: options.Drafts, Future: options.Future}
+		renderer.BaseURL = options.BaseURL
+		renderer.Verbose = options.Verbose
+		renderResult, err := renderer.Render()
+		if err != nil {
+			return fail(err)
+		}
+
+		result.PagesRendered = renderResult.Pages
+		result.PagesUnpublished = renderResult.Unpublished
+		if options.Verbose {
+			fmt.Printf("✅ Rendered %d pages and copied %d assets\n", renderResult.Pages, renderResult.Assets)
+			if renderResult.Unpublished > 0 {
+				fmt.Printf("📝 Skipped %d drafts and scheduled pages (include them with --drafts/--future)\n", renderResult.Unpublished)
+			}
+			if renderResult.Sitemap {
+				fmt.Println("🗺️  Generated sitemap.xml")
+			} else if options.BaseURL == "" {
+				fmt.Println("💡 Set site.base_url in garp.toml to generate sitemap.xml")
+			}
+		}
+
+		if !options.SkipSearch {
+			searchResult, err := internal.BuildSearch(options)
+			result.SearchBuilt = searchResult.SearchBuilt
+			if err != nil {
+				result.Errors = append(result.Errors, searchResult.Errors...)
+				result.Success = false
+				result.Duration = time.Since(start)
+				return result, err
+			}
+		}
+	}
+
+	result.Success = true
+	result.Duration = time.Since(start)
+	return result, nil
+}
@@ -0,0 +1,188 @@
+package static
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mattsafaii/garp/internal/content"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// markdownRenderer mirrors the Goldmark configuration of Caddy's markdown
+// template function
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.Footnote),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// templateFuncs returns the functions available to page templates. Names
+// follow the Caddy templates and Sprig functions Garp templates already use.
+func (r *Renderer) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"markdown":   renderMarkdown,
+		"default":    defaultValue,
+		"time":       formatTime,
+		"date":       formatTime,
+		"now":        time.Now,
+		"stripHTML":  stripHTML,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"trim":       strings.TrimSpace,
+		"join":       join,
+		"include":    r.include,
+		"fileExists": r.fileExists,
+		"listFiles":  r.listFiles,
+		"env":        os.Getenv,
+	}
+}
+
+// renderMarkdown converts markdown to HTML
+func renderMarkdown(input interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(toString(input)), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// defaultValue returns fallback when value is empty, matching Sprig's
+// argument order so that `.Meta.title | default "Untitled"` works
+func defaultValue(fallback interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return fallback
+	}
+	return value[0]
+}
+
+// formatTime formats a time.Time or a date string with a Go layout
+func formatTime(layout string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(layout), nil
+	case string:
+		t, err := content.ParseDate(v)
+		if err != nil {
+			return "", err
+		}
+		return t.Format(layout), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("cannot format %T as a date", value)
+	}
+}
+
+// stripHTML removes HTML tags from a string
+func stripHTML(input interface{}) string {
+	return htmlTagPattern.ReplaceAllString(toString(input), "")
+}
+
+// join concatenates a list of values with a separator
+func join(separator string, values interface{}) string {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return toString(values)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = toString(v.Index(i).Interface())
+	}
+	return strings.Join(parts, separator)
+}
+
+// include returns the contents of a file in the source directory
+func (r *Renderer) include(path string) (string, error) {
+	full, err := r.sitePath(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// fileExists reports whether a file exists in the source directory
+func (r *Renderer) fileExists(path string) bool {
+	full, err := r.sitePath(path)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(full)
+	return err == nil
+}
+
+// listFiles lists the entries of a directory in the source directory
+func (r *Renderer) listFiles(path string) ([]string, error) {
+	full, err := r.sitePath(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// sitePath resolves a site-absolute path (e.g. "/partials/nav.html") inside
+// the source directory
+func (r *Renderer) sitePath(path string) (string, error) {
+	clean := filepath.Clean("/" + filepath.FromSlash(path))
+	full := filepath.Join(r.SourceDir, clean)
+	if rel, err := filepath.Rel(r.SourceDir, full); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("path %s is outside the site", path)
+	}
+	return full, nil
+}
+
+// isEmpty reports whether a template value is unset or zero
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// toString converts a template value to a string
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,347 @@
+package static
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mattsafaii/garp/internal"
+	"github.com/mattsafaii/garp/internal/content"
+)
+
+// TemplateFile is the layout markdown pages are rendered through. The nearest
+// one in the page's directory or its parents is used.
+const TemplateFile = "_template.html"
+
+// defaultTemplate is used when a project has no _template.html
+const defaultTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>[[.Meta.title | default "Untitled"]]</title>
+</head>
+<body>
+[[.Body | markdown]]
+</body>
+</html>
+`
+
+// PageData is passed to templates. Meta and Body match the values Caddy
+// templates expose through splitFrontMatter.
+type PageData struct {
+	Meta map[string]interface{}
+	Body string
+	Path string        // URL path of the rendered page, e.g. "/about/"
+	Page *content.Page // Typed page model
+}
+
+// Renderer renders a source tree into plain HTML: markdown pages go through
+// the nearest _template.html, HTML files are executed as templates and
+// everything else is copied
+type Renderer struct {
+	SourceDir string
+	OutputDir string
+	Skip      []string // source paths that are not copied (e.g. the Tailwind input)
+	Publish   content.PublishOptions
+	Now       time.Time // reference time for scheduled pages, defaults to time.Now()
+	BaseURL   string    // absolute site URL; a sitemap.xml is written when set
+	Verbose   bool
+
+	templates map[string]*template.Template
+	outputs   map[string]string
+	rendered  []*content.Page
+}
+
+// RenderResult summarises a static render
+type RenderResult struct {
+	Pages       int
+	Assets      int
+	Unpublished int  // drafts and scheduled pages left out
+	Sitemap     bool // whether sitemap.xml was generated
+}
+
+// NewRenderer creates a renderer for the given project layout
+func NewRenderer(layout internal.ProjectLayout) *Renderer {
+	return &Renderer{
+		SourceDir: layout.SourceDir,
+		OutputDir: layout.OutputDir,
+		Skip:      []string{layout.CSSInput},
+	}
+}
+
+// Render replaces the output directory with a freshly rendered site
+func (r *Renderer) Render() (*RenderResult, error) {
+	if err := r.validateDirs(); err != nil {
+		return nil, err
+	}
+
+	r.templates = make(map[string]*template.Template)
+	r.outputs = make(map[string]string)
+	r.rendered = nil
+	if r.Now.IsZero() {
+		r.Now = time.Now()
+	}
+
+	if err := os.RemoveAll(r.OutputDir); err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to clean output directory %s", r.OutputDir), err)
+	}
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to create output directory %s", r.OutputDir), err)
+	}
+
+	result := &RenderResult{}
+	err := filepath.Walk(r.SourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == r.SourceDir {
+			return nil
+		}
+
+		if r.skipped(path, info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(r.SourceDir, path)
+		if err != nil {
+			return err
+		}
+
+		if !content.IsPage(path) {
+			result.Assets++
+			return r.copyFile(path, rel, info.Mode())
+		}
+
+		page, err := content.LoadPage(r.SourceDir, path)
+		if err != nil {
+			return err
+		}
+		if !r.Publish.Includes(page, r.Now) {
+			result.Unpublished++
+			if r.Verbose {
+				fmt.Printf("  skipping unpublished %s\n", path)
+			}
+			return nil
+		}
+
+		result.Pages++
+		r.rendered = append(r.rendered, page)
+		if page.Kind == content.KindMarkdown {
+			return r.renderMarkdown(page)
+		}
+		return r.renderHTML(page)
+	})
+	if err != nil {
+		if _, ok := err.(*internal.AppError); ok {
+			return nil, err
+		}
+		return nil, internal.NewFileSystemError("static render failed", err)
+	}
+
+	if r.BaseURL != "" {
+		written, err := r.writeSitemap()
+		if err != nil {
+			return nil, internal.NewFileSystemError("failed to write sitemap.xml", err)
+		}
+		result.Sitemap = written
+	}
+
+	return result, nil
+}
+
+// validateDirs refuses to render into the source tree or over it
+func (r *Renderer) validateDirs() error {
+	source, err := filepath.Abs(r.SourceDir)
+	if err != nil {
+		return internal.NewFileSystemError("failed to resolve source directory", err)
+	}
+	output, err := filepath.Abs(r.OutputDir)
+	if err != nil {
+		return internal.NewFileSystemError("failed to resolve output directory", err)
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		return internal.NewFileSystemError(fmt.Sprintf("source directory not found: %s", r.SourceDir), err)
+	}
+
+	if source == output || isWithin(source, output) || isWithin(output, source) {
+		return internal.NewConfigurationErrorWithSuggestions(
+			fmt.Sprintf("static output directory %s overlaps the source directory %s", r.OutputDir, r.SourceDir),
+			[]string{"Set build.static_dir in garp.toml to a directory outside the source, e.g. \"dist\""},
+		)
+	}
+	return nil
+}
+
+// skipped reports whether a source file or directory is left out of the
+// rendered site: partials and layouts ("_" prefix), hidden files and
+// explicitly skipped paths
+func (r *Renderer) skipped(path, name string) bool {
+	if content.Hidden(name) {
+		return true
+	}
+	for _, skip := range r.Skip {
+		if filepath.Clean(skip) == filepath.Clean(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderMarkdown renders a markdown page through the nearest template
+func (r *Renderer) renderMarkdown(page *content.Page) error {
+	tmpl, err := r.templateFor(filepath.Dir(page.Path))
+	if err != nil {
+		return err
+	}
+
+	data := PageData{Meta: page.Meta, Body: page.Body, Path: page.URL, Page: page}
+	return r.execute(tmpl, data, page.Path, page.OutputPath())
+}
+
+// renderHTML executes HTML files that contain template actions and copies
+// the rest unchanged
+func (r *Renderer) renderHTML(page *content.Page) error {
+	if !strings.Contains(page.Body, "[[") {
+		return r.writeOutput(page.Path, page.RelPath, []byte(page.Body), 0644)
+	}
+
+	tmpl, err := r.parseTemplate(page.Path, page.Body)
+	if err != nil {
+		return err
+	}
+
+	data := PageData{Meta: page.Meta, Path: page.URL, Page: page}
+	return r.execute(tmpl, data, page.Path, page.RelPath)
+}
+
+// execute runs a template and writes the result to the output path
+func (r *Renderer) execute(tmpl *template.Template, data PageData, source, output string) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return internal.NewValidationErrorWithSuggestions(
+			fmt.Sprintf("failed to render %s: %v", source, err),
+			[]string{"Check the template actions in " + TemplateFile + " and the page frontmatter"},
+		)
+	}
+	return r.writeOutput(source, output, buf.Bytes(), 0644)
+}
+
+// templateFor returns the nearest _template.html for a directory
+func (r *Renderer) templateFor(dir string) (*template.Template, error) {
+	if tmpl, ok := r.templates[dir]; ok {
+		return tmpl, nil
+	}
+
+	var tmpl *template.Template
+	path := filepath.Join(dir, TemplateFile)
+	source, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		tmpl, err = r.parseTemplate(path, string(source))
+		if err != nil {
+			return nil, err
+		}
+	case !os.IsNotExist(err):
+		return nil, internal.NewFileSystemError(fmt.Sprintf("failed to read %s", path), err)
+	case filepath.Clean(dir) == filepath.Clean(r.SourceDir):
+		tmpl, err = r.parseTemplate("default template", defaultTemplate)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		tmpl, err = r.templateFor(filepath.Dir(dir))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.templates[dir] = tmpl
+	return tmpl, nil
+}
+
+// parseTemplate parses a template with Caddy's [[ ]] delimiters
+func (r *Renderer) parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Delims("[[", "]]").Funcs(r.templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, internal.NewValidationErrorWithSuggestions(
+			fmt.Sprintf("invalid template %s: %v", name, err),
+			[]string{"Templates use [[ ]] delimiters, e.g. [[.Meta.title]]"},
+		)
+	}
+	return tmpl, nil
+}
+
+// copyFile copies an asset into the output directory
+func (r *Renderer) copyFile(path, rel string, mode os.FileMode) error {
+	if err := r.claimOutput(path, rel); err != nil {
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	target := filepath.Join(r.OutputDir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// writeOutput writes rendered content into the output directory
+func (r *Renderer) writeOutput(source, rel string, data []byte, mode os.FileMode) error {
+	if err := r.claimOutput(source, rel); err != nil {
+		return err
+	}
+
+	target := filepath.Join(r.OutputDir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, mode); err != nil {
+		return err
+	}
+
+	if r.Verbose {
+		fmt.Printf("  %s -> %s\n", source, target)
+	}
+	return nil
+}
+
+// claimOutput records which source produced an output file so that two
+// sources rendering to the same URL are reported instead of overwritten
+func (r *Renderer) claimOutput(source, rel string) error {
+	if previous, exists := r.outputs[rel]; exists {
+		return internal.NewValidationError(fmt.Sprintf("%s and %s both render to %s", previous, source, filepath.Join(r.OutputDir, rel)))
+	}
+	r.outputs[rel] = source
+	return nil
+}
+
+// isWithin reports whether path is inside root
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,239 @@
+package static
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattsafaii/garp/internal/content"
+)
+
+// writeSite creates files relative to root
+func writeSite(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// readOutput returns the content of a rendered file
+func readOutput(t *testing.T, root, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatalf("Expected output %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestRenderSite(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "public")
+	output := filepath.Join(dir, "dist")
+
+	writeSite(t, source, map[string]string{
+		"_template.html": `<title>[[.Meta.title | default "Site"]]</title>` +
+			`[[if .Meta.date]]<time>[[.Meta.date | time "January 2, 2006"]]</time>[[end]]` +
+			`[[range .Meta.tags]]<span>[[. | html]]</span>[[end]]` +
+			`<main>[[.Body | markdown]]</main><p>[[.Path]]</p>`,
+		"index.md":            "---\ntitle: Home\n---\n# Welcome\n",
+		"about.md":            "+++\ntitle = \"About\"\ntags = [\"a&b\", \"c\"]\n+++\n\nAbout *us*\n",
+		"blog/_template.html": `<article>[[.Meta.title]]|[[.Body | markdown]]</article>`,
+		"blog/first.md":       "{\n  \"title\": \"First\"\n}\nHello",
+		"blog/dated.md":       "---\ntitle: Dated\ndate: 2024-03-05\n---\nbody",
+		"contact.html":        `<h1>[[ "Contact" | upper ]]</h1>`,
+		"plain.html":          `<p>{{ not a caddy template }}</p>`,
+		"css/input.css":       `@import "tailwindcss";`,
+		"css/style.css":       `body{}`,
+		"images/logo.svg":     `<svg></svg>`,
+		"_pagefind/index.js":  `stale`,
+		"_partials/nav.html":  `<nav></nav>`,
+	})
+
+	renderer := &Renderer{
+		SourceDir: source,
+		OutputDir: output,
+		Skip:      []string{filepath.Join(source, "css", "input.css")},
+	}
+	result, err := renderer.Render()
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if result.Pages != 6 {
+		t.Errorf("Expected 6 pages, got %d", result.Pages)
+	}
+
+	home := readOutput(t, output, "index.html")
+	if !strings.Contains(home, "<title>Home</title>") || !strings.Contains(home, `<h1 id="welcome">Welcome</h1>`) {
+		t.Errorf("Unexpected home page:\n%s", home)
+	}
+	if !strings.Contains(home, "<p>/</p>") {
+		t.Errorf("Expected home page path /, got:\n%s", home)
+	}
+
+	about := readOutput(t, output, "about/index.html")
+	if !strings.Contains(about, "<em>us</em>") || !strings.Contains(about, "<span>a&amp;b</span>") {
+		t.Errorf("Unexpected about page:\n%s", about)
+	}
+	if !strings.Contains(about, "<p>/about/</p>") {
+		t.Errorf("Expected about page path /about/, got:\n%s", about)
+	}
+
+	first := readOutput(t, output, "blog/first/index.html")
+	if !strings.HasPrefix(first, "<article>First|<p>Hello</p>") {
+		t.Errorf("Expected nearest template to be used, got:\n%s", first)
+	}
+
+	dated := readOutput(t, output, "blog/dated/index.html")
+	if !strings.Contains(dated, "<article>Dated|") {
+		t.Errorf("Unexpected dated page:\n%s", dated)
+	}
+
+	if contact := readOutput(t, output, "contact.html"); contact != "<h1>CONTACT</h1>" {
+		t.Errorf("Expected HTML template to be executed, got %q", contact)
+	}
+	if plain := readOutput(t, output, "plain.html"); plain != `<p>{{ not a caddy template }}</p>` {
+		t.Errorf("Expected plain HTML to be copied, got %q", plain)
+	}
+	readOutput(t, output, "css/style.css")
+	readOutput(t, output, "images/logo.svg")
+
+	for _, name := range []string{"_template.html", "css/input.css", "_pagefind/index.js", "_partials/nav.html", "about.md"} {
+		if _, err := os.Stat(filepath.Join(output, name)); err == nil {
+			t.Errorf("Expected %s to be left out of the output", name)
+		}
+	}
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "public")
+	writeSite(t, source, map[string]string{
+		"index.md": "---\ntitle: Hi\n---\ntext",
+	})
+
+	renderer := &Renderer{SourceDir: source, OutputDir: filepath.Join(dir, "dist")}
+	if _, err := renderer.Render(); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	home := readOutput(t, renderer.OutputDir, "index.html")
+	if !strings.Contains(home, "<title>Hi</title>") || !strings.Contains(home, "<p>text</p>") {
+		t.Errorf("Unexpected default template output:\n%s", home)
+	}
+}
+
+func TestRenderRemovesStaleOutput(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "public")
+	output := filepath.Join(dir, "dist")
+	writeSite(t, source, map[string]string{"index.md": "hi"})
+	writeSite(t, output, map[string]string{"old.html": "stale"})
+
+	renderer := &Renderer{SourceDir: source, OutputDir: output}
+	if _, err := renderer.Render(); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "old.html")); err == nil {
+		t.Error("Expected stale output to be removed")
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		output string
+	}{
+		{"output inside source", map[string]string{"index.md": "hi"}, "public/dist"},
+		{"output is source", map[string]string{"index.md": "hi"}, "public"},
+		{"conflicting outputs", map[string]string{"about.md": "a", "about/index.html": "b"}, "dist"},
+		{"invalid frontmatter", map[string]string{"index.md": "---\ntitle: [\n---\n"}, "dist"},
+		{"unterminated frontmatter", map[string]string{"index.md": "---\ntitle: x\n"}, "dist"},
+		{"template error", map[string]string{"_template.html": "[[.Meta.title", "index.md": "hi"}, "dist"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := filepath.Join(dir, "public")
+			writeSite(t, source, test.files)
+
+			renderer := &Renderer{SourceDir: source, OutputDir: filepath.Join(dir, test.output)}
+			if _, err := renderer.Render(); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestRenderUnpublishedPages(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "public")
+	writeSite(t, source, map[string]string{
+		"index.md":  "---\ntitle: Home\nlastmod: 2024-01-02\n---\n",
+		"draft.md":  "---\ntitle: Draft\ndraft: true\n---\n",
+		"later.md":  "---\ntitle: Later\ndate: 2999-01-01\n---\n",
+		"hidden.md": "---\ntitle: Hidden\nsitemap: false\n---\n",
+	})
+
+	renderer := &Renderer{SourceDir: source, OutputDir: filepath.Join(dir, "dist"), BaseURL: "https://example.com/"}
+	result, err := renderer.Render()
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if result.Unpublished != 2 {
+		t.Errorf("Expected 2 unpublished pages, got %d", result.Unpublished)
+	}
+	for _, name := range []string{"draft/index.html", "later/index.html"} {
+		if _, err := os.Stat(filepath.Join(renderer.OutputDir, filepath.FromSlash(name))); err == nil {
+			t.Errorf("Expected %s to be skipped", name)
+		}
+	}
+
+	if !result.Sitemap {
+		t.Fatal("Expected sitemap to be generated")
+	}
+	sitemap := readOutput(t, renderer.OutputDir, SitemapFile)
+	if !strings.Contains(sitemap, "<loc>https://example.com/</loc>") || !strings.Contains(sitemap, "<lastmod>2024-01-02</lastmod>") {
+		t.Errorf("Unexpected sitemap:\n%s", sitemap)
+	}
+	for _, loc := range []string{"/draft/", "/later/", "/hidden/"} {
+		if strings.Contains(sitemap, "https://example.com"+loc+"<") {
+			t.Errorf("Expected %s to be left out of the sitemap", loc)
+		}
+	}
+
+	renderer.Publish = content.PublishOptions{Drafts: true, Future: true}
+	result, err = renderer.Render()
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if result.Unpublished != 0 {
+		t.Errorf("Expected drafts and future pages to be included, %d skipped", result.Unpublished)
+	}
+	readOutput(t, renderer.OutputDir, "draft/index.html")
+	readOutput(t, renderer.OutputDir, "later/index.html")
+}
+
+func TestRenderSitemapRequiresBaseURL(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "public")
+	writeSite(t, source, map[string]string{"index.md": "hi"})
+
+	renderer := &Renderer{SourceDir: source, OutputDir: filepath.Join(dir, "dist")}
+	result, err := renderer.Render()
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if result.Sitemap {
+		t.Error("Expected no sitemap without a base URL")
+	}
+}
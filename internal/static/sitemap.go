@@ -0,0 +1,60 @@
+package static
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SitemapFile is written to the root of the output directory
+const SitemapFile = "sitemap.xml"
+
+// sitemapURLSet is the root element of a sitemap
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is a single sitemap entry
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// writeSitemap lists every rendered page in sitemap.xml. A sitemap.xml
+// provided in the source directory takes precedence and is left alone.
+func (r *Renderer) writeSitemap() (bool, error) {
+	if _, exists := r.outputs[SitemapFile]; exists {
+		return false, nil
+	}
+
+	base := strings.TrimRight(r.BaseURL, "/")
+	urlSet := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, page := range r.rendered {
+		// Pages opt out with "sitemap: false" in their front matter
+		if include, ok := page.Meta["sitemap"].(bool); ok && !include {
+			continue
+		}
+		entry := sitemapURL{Loc: base + page.URL}
+		if !page.LastMod.IsZero() {
+			entry.LastMod = page.LastMod.UTC().Format("2006-01-02")
+		}
+		urlSet.URLs = append(urlSet.URLs, entry)
+	}
+	sort.Slice(urlSet.URLs, func(i, j int) bool {
+		return urlSet.URLs[i].Loc < urlSet.URLs[j].Loc
+	})
+
+	data, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	r.outputs[SitemapFile] = "generated"
+	return true, os.WriteFile(filepath.Join(r.OutputDir, SitemapFile), data, 0644)
+}
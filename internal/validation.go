@@ -275,7 +275,8 @@ func ValidateCommandPrerequisites(command string) error {
 		// Build command will validate dependencies internally via existing functions
 		return nil
 	case "form-server":
-		return ValidateExecutable("ruby")
+		// The form server is built in; ruby is only needed with --ruby
+		return nil
 	default:
 		return nil
 	}
@@ -377,12 +378,12 @@ func ValidateTailwindConfig() error {
 
 // ValidateTailwindConfigV4 checks if Tailwind v4 CSS-based configuration is valid
 func ValidateTailwindConfigV4() error {
-	inputPath := "public/css/input.css"
+	inputPath := GetProjectLayout().CSSInput
 
 	// Read input.css to check for v4 configuration
 	content, err := os.ReadFile(inputPath)
 	if err != nil {
-		return NewFileSystemError("cannot read public/css/input.css", err)
+		return NewFileSystemError(fmt.Sprintf("cannot read %s", inputPath), err)
 	}
 
 	contentStr := string(content)
@@ -392,7 +393,7 @@ func ValidateTailwindConfigV4() error {
 		return NewConfigurationErrorWithSuggestions(
 			"input.css missing Tailwind CSS v4 import",
 			[]string{
-				`Add '@import "tailwindcss";' to the top of public/css/input.css`,
+				fmt.Sprintf(`Add '@import "tailwindcss";' to the top of %s`, inputPath),
 				"Refer to Tailwind CSS v4 documentation",
 			},
 		)
@@ -403,7 +404,7 @@ func ValidateTailwindConfigV4() error {
 
 // ValidateInputCSS checks if the input CSS file is valid
 func ValidateInputCSS() error {
-	inputPath := "public/css/input.css"
+	inputPath := GetProjectLayout().CSSInput
 
 	// Check if file exists
 	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
@@ -411,7 +412,7 @@ func ValidateInputCSS() error {
 			"input.css not found",
 			[]string{
 				"Run 'garp init' to create a new project with input.css",
-				"Create an input.css file in public/css/",
+				fmt.Sprintf("Create the Tailwind input file at %s", inputPath),
 			},
 		)
 	}
@@ -419,7 +420,7 @@ func ValidateInputCSS() error {
 	// Basic content validation
 	content, err := os.ReadFile(inputPath)
 	if err != nil {
-		return NewFileSystemError("cannot read public/css/input.css", err)
+		return NewFileSystemError(fmt.Sprintf("cannot read %s", inputPath), err)
 	}
 
 	contentStr := string(content)
@@ -447,7 +448,7 @@ func ValidateInputCSS() error {
 			return NewConfigurationErrorWithSuggestions(
 				fmt.Sprintf("input.css missing Tailwind setup - neither @import nor individual directives found"),
 				[]string{
-					"Add '@import \"tailwindcss\";' to the top of public/css/input.css",
+					fmt.Sprintf("Add '@import \"tailwindcss\";' to the top of %s", inputPath),
 					"Or add individual directives: @tailwind base; @tailwind components; @tailwind utilities;",
 					"Run 'garp init' to regenerate a proper input.css",
 					"Refer to Tailwind CSS documentation for setup",